@@ -1,16 +1,28 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sword-demon/go-react-admin/internal/admin/biz"
+	v1 "github.com/sword-demon/go-react-admin/internal/admin/controller/v1"
+	"github.com/sword-demon/go-react-admin/internal/admin/middleware"
+	"github.com/sword-demon/go-react-admin/internal/admin/scanner"
 	"github.com/sword-demon/go-react-admin/internal/admin/store"
+	"github.com/sword-demon/go-react-admin/internal/pkg/auth"
 	"github.com/sword-demon/go-react-admin/internal/pkg/cache"
 	"github.com/sword-demon/go-react-admin/internal/pkg/config"
+	"github.com/sword-demon/go-react-admin/internal/pkg/datascope"
 	"github.com/sword-demon/go-react-admin/internal/pkg/db"
+	"github.com/sword-demon/go-react-admin/internal/pkg/metrics"
 )
 
 func main() {
@@ -44,6 +56,12 @@ func main() {
 			log.Printf("⚠️  Failed to close database: %v", err)
 		}
 	}()
+	if err := metrics.RegisterDB(database, cfg.Database.Database); err != nil {
+		log.Printf("⚠️  Failed to register database metrics: %v", err)
+	}
+	if err := db.RegisterAudit(database, auth.UserIDFromContext); err != nil {
+		log.Printf("⚠️  Failed to register audit callbacks: %v", err)
+	}
 
 	// 3. Initialize Redis (optional, warn if fails)
 	redisClient, err := cache.InitRedis(cfg.Redis.ToRedisConfig())
@@ -67,20 +85,82 @@ func main() {
 	dataStore := store.NewStore(database)
 	log.Println("✅ Store layer initialized")
 
-	// 6. Initialize biz layer
-	bizLayer := biz.NewBiz(dataStore, redisClient)
+	// 6. Initialize local cache (L1, shared by all biz instances)
+	localCache := cache.NewLocalCacheWithConfig(cfg.Cache.ToLocalCacheConfig())
+	stopCleanup := localCache.StartCleanupWorker(time.Minute)
+	defer close(stopCleanup)
+
+	// Cross-instance L1 invalidation (Redis Pub/Sub, disabled without Redis)
+	invalidator := cache.NewInvalidator(localCache, redisClient)
+	invalidator.Start(context.Background())
+	defer invalidator.Close()
+
+	// Cache load coalescing (stampede protection) and early refresh of hot keys
+	loadGroup := cache.NewLoadGroup(cfg.Cache.EarlyRefreshWindowDuration())
+
+	// Namespace generations (versioned cache keys, bumped for bulk invalidation)
+	generations := cache.NewGenerations(localCache, redisClient, invalidator)
+
+	// Restore L1 snapshot of previous run (before warmup and serving traffic)
+	// Process-local generations are restored with it, so bumped keys stay unreachable
+	snapshotConfig := cfg.Cache.Snapshot.ToSnapshotConfig()
+	snapshotConfig.Generations = generations
+	if cfg.Cache.Snapshot.Enabled {
+		if stats, err := localCache.LoadSnapshot(snapshotConfig); err != nil {
+			log.Printf("⚠️  Failed to restore cache snapshot: %v", err)
+		} else if stats.Entries > 0 {
+			log.Printf("✅ Cache snapshot restored: %d entries (%d skipped), took %v", stats.Entries, stats.Skipped, stats.Duration)
+		}
+	}
+
+	// Cache statistics for /metrics (read on each scrape)
+	if err := metrics.RegisterCache(localCache, redisClient, loadGroup); err != nil {
+		log.Printf("⚠️  Failed to register cache metrics: %v", err)
+	}
+
+	// 7. Initialize biz layer
+	bizLayer := biz.NewBiz(dataStore, localCache, redisClient, invalidator, loadGroup, generations)
 	log.Println("✅ Biz layer initialized")
 
-	// Prevent unused variable error (will be used in controllers later)
-	_ = bizLayer
+	// 8. Initialize authentication (JWT + token denylist)
+	authenticator := auth.NewAuthenticator(
+		dataStore.Users(),
+		auth.NewTokenManager(cfg.JWT.ToAuthConfig()),
+		auth.NewDenylist(redisClient),
+	)
+	authController := v1.NewAuthController(authenticator)
+	log.Println("✅ Authentication initialized")
+
+	// 9. Initialize permission middleware (routes declare permission at registration)
+	routeRegistry := middleware.NewRouteRegistry()
+	permissionMiddleware := middleware.Permission(bizLayer.Permissions(), routeRegistry)
+
+	// Data scope (row-level filtering by Role.DataScope, applied in store list queries)
+	dataScopeResolver := datascope.NewResolver(dataStore.Users(), dataStore.Roles(), dataStore.Depts(), localCache, redisClient, invalidator, generations)
+
+	// 10. Initialize cache management (stats, clearing, warmup jobs)
+	threeTierCache := cache.NewThreeTierCache(localCache, redisClient).WithInvalidator(invalidator)
+	warmer := cache.NewPermissionWarmer(cfg.Cache.Warmup.ToWarmupConfig(), bizLayer.Permissions()).
+		WithTargetSource(bizLayer.Permissions())
+	warmups := cache.NewWarmupJobs(warmer)
+	cacheController := v1.NewCacheController(bizLayer.Permissions(), threeTierCache, warmups)
+	if cfg.Cache.Warmup.OnStartup {
+		// Background job, rate limited so startup does not hammer MySQL
+		if job, _, err := warmups.Start(context.Background()); err != nil {
+			log.Printf("⚠️  Failed to start cache warmup: %v", err)
+		} else {
+			log.Printf("🔥 Cache warmup started (job: %s)", job.ID)
+		}
+	}
 
 	// TODO: Initialize controllers (pass bizLayer)
-	// TODO: Setup middleware (JWT, Permission, CORS)
+	// TODO: Setup middleware (CORS)
 	// TODO: Register routes (see internal/admin/router.go)
 
 	// Set Gin mode based on config
 	gin.SetMode(cfg.Server.Mode)
 	r := gin.Default()
+	r.Use(metrics.Middleware())
 
 	// Health check endpoint
 	r.GET("/ping", func(c *gin.Context) {
@@ -91,24 +171,45 @@ func main() {
 		})
 	})
 
+	// Prometheus metrics (HTTP, GORM, cache tiers, Redis pool)
+	r.GET("/metrics", metrics.Handler())
+
 	// API v1 routes (will be moved to internal/admin/router.go)
-	v1 := r.Group("/api/v1")
+	apiV1 := r.Group("/api/v1")
 	{
-		// Auth routes
-		v1.POST("/auth/login", func(c *gin.Context) {
-			c.JSON(200, gin.H{"message": "login endpoint"})
-		})
+		// Auth routes (public)
+		apiV1.POST("/auth/login", authController.Login)
+		apiV1.POST("/auth/refresh", authController.Refresh)
+
+		// Protected routes (JWT required)
+		protected := apiV1.Group("", auth.Middleware(authenticator), datascope.Middleware(dataScopeResolver))
+		protected.POST("/auth/logout", authController.Logout)
 
 		// User routes (protected by JWT + Permission middleware)
-		users := v1.Group("/users")
+		users := routeRegistry.Group(protected.Group("/users", permissionMiddleware), "user")
 		{
-			users.GET("", func(c *gin.Context) {
+			users.GET("", "list", "List users", func(c *gin.Context) {
 				c.JSON(200, gin.H{"message": "list users"})
 			})
-			users.POST("", func(c *gin.Context) {
+			users.POST("", "create", "Create user", func(c *gin.Context) {
 				c.JSON(200, gin.H{"message": "create user"})
 			})
 		}
+
+		// Cache management routes (cache:* grants all)
+		cacheRoutes := routeRegistry.Group(protected.Group("/cache", permissionMiddleware), "cache")
+		{
+			cacheRoutes.GET("/stats", "stats", "Get cache statistics", cacheController.GetStats)
+			cacheRoutes.DELETE("", "clear", "Clear cache by user, role or key prefix", cacheController.ClearCache)
+			cacheRoutes.POST("/warmup", "warmup", "Start cache warmup job", cacheController.WarmupCache)
+			cacheRoutes.GET("/warmup/:id", "warmup", "Get cache warmup job", cacheController.GetWarmup)
+		}
+	}
+
+	// Sync route table into sys_api_doc (after all routes are registered)
+	apiScanner := scanner.NewAPIScanner(r, routeRegistry, dataStore)
+	if _, err := apiScanner.ScanAndSyncToDB(context.Background()); err != nil {
+		log.Printf("⚠️  Failed to sync API docs: %v", err)
 	}
 
 	port := fmt.Sprintf(":%d", cfg.Server.Port)
@@ -116,8 +217,33 @@ func main() {
 	fmt.Printf("📊 Mode: %s\n", cfg.Server.Mode)
 	fmt.Println("📚 API Documentation: http://localhost:8080/swagger/index.html (coming soon)")
 	fmt.Printf("💚 Health Check: http://localhost:%d/ping\n", cfg.Server.Port)
+	fmt.Printf("📈 Metrics: http://localhost:%d/metrics\n", cfg.Server.Port)
+
+	// Serve until SIGINT/SIGTERM, then shut down gracefully
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	if err := r.Run(port); err != nil {
-		log.Fatal("❌ Server startup failed:", err)
+	srv := &http.Server{Addr: port, Handler: r}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("❌ Server startup failed:", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("🛑 Shutting down server...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("⚠️  Server shutdown error: %v", err)
+	}
+
+	// Persist L1 for the next start (after in-flight requests finished)
+	if cfg.Cache.Snapshot.Enabled {
+		if stats, err := localCache.SaveSnapshot(snapshotConfig); err != nil {
+			log.Printf("⚠️  Failed to save cache snapshot: %v", err)
+		} else {
+			log.Printf("✅ Cache snapshot saved: %d entries, %d bytes, took %v", stats.Entries, stats.Bytes, stats.Duration)
+		}
 	}
 }
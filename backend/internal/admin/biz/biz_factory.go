@@ -12,31 +12,43 @@ import (
 
 // bizFactory implements IBiz interface
 type bizFactory struct {
-	store store.IStore
-	cache *cache.RedisClient
+	store       store.IStore
+	localCache  *cache.LocalCache
+	cache       *cache.RedisClient
+	invalidator *cache.Invalidator
+	loads       *cache.LoadGroup
+	generations *cache.Generations
 }
 
 // NewBiz creates a new biz instance
-func NewBiz(store store.IStore, cache *cache.RedisClient) IBiz {
+// localCache is shared by all biz instances (L1 cache)
+// invalidator keeps localCache consistent across instances (nil without Redis)
+// loads coalesces cache loads of all biz instances
+// generations versions cache keys for O(1) namespace invalidation
+func NewBiz(store store.IStore, localCache *cache.LocalCache, cache *cache.RedisClient, invalidator *cache.Invalidator, loads *cache.LoadGroup, generations *cache.Generations) IBiz {
 	return &bizFactory{
-		store: store,
-		cache: cache,
+		store:       store,
+		localCache:  localCache,
+		cache:       cache,
+		invalidator: invalidator,
+		loads:       loads,
+		generations: generations,
 	}
 }
 
 // Users returns user biz
 func (b *bizFactory) Users() user.IUserBiz {
-	return user.NewUserBiz(b.store, b.cache)
+	return user.NewUserBiz(b.store, b.cache, b.generations)
 }
 
 // Roles returns role biz
 func (b *bizFactory) Roles() role.IRoleBiz {
-	return role.NewRoleBiz(b.store, b.cache)
+	return role.NewRoleBiz(b.store, b.cache, b.Permissions())
 }
 
 // Depts returns dept biz
 func (b *bizFactory) Depts() dept.IDeptBiz {
-	return dept.NewDeptBiz(b.store)
+	return dept.NewDeptBiz(b.store, b.generations)
 }
 
 // Menus returns menu biz
@@ -46,5 +58,5 @@ func (b *bizFactory) Menus() menu.IMenuBiz {
 
 // Permissions returns permission biz
 func (b *bizFactory) Permissions() permission.IPermissionBiz {
-	return permission.NewPermissionBiz(b.store, b.cache)
-}
\ No newline at end of file
+	return permission.NewPermissionBiz(b.store, b.localCache, b.cache, b.invalidator, b.loads, b.generations)
+}
@@ -2,19 +2,26 @@ package dept
 
 import (
 	"context"
+	stderrors "errors"
+	"log"
 
 	"github.com/sword-demon/go-react-admin/internal/admin/store"
+	"github.com/sword-demon/go-react-admin/internal/pkg/cache"
+	"github.com/sword-demon/go-react-admin/internal/pkg/errors"
 	"github.com/sword-demon/go-react-admin/internal/pkg/model"
+	"gorm.io/gorm"
 )
 
 type deptBiz struct {
-	store store.IStore
+	store       store.IStore
+	generations *cache.Generations // Versions cached dept ID sets (data scope)
 }
 
 // Keep interface here to avoid import cycle
 type IDeptBiz interface {
 	Create(ctx context.Context, req *CreateDeptRequest) (*DeptResponse, error)
 	Update(ctx context.Context, id uint64, req *UpdateDeptRequest) error
+	Move(ctx context.Context, id uint64, req *MoveDeptRequest) error
 	Delete(ctx context.Context, id uint64) error
 	Get(ctx context.Context, id uint64) (*DeptResponse, error)
 	GetTree(ctx context.Context) ([]*DeptTreeNode, error)
@@ -38,6 +45,11 @@ type UpdateDeptRequest struct {
 	Status   int8   `json:"status"`
 }
 
+// MoveDeptRequest re-parents a department with its subtree
+type MoveDeptRequest struct {
+	ParentID uint64 `json:"parent_id"` // 0 = root
+}
+
 type DeptResponse struct {
 	ID       uint64 `json:"id"`
 	ParentID uint64 `json:"parent_id"`
@@ -54,8 +66,10 @@ type DeptTreeNode struct {
 	Children []*DeptTreeNode `json:"children,omitempty"`
 }
 
-func NewDeptBiz(store store.IStore) IDeptBiz {
-	return &deptBiz{store: store}
+// NewDeptBiz creates a new dept biz
+// generations invalidates cached department subtrees when the tree changes
+func NewDeptBiz(store store.IStore, generations *cache.Generations) IDeptBiz {
+	return &deptBiz{store: store, generations: generations}
 }
 
 func (b *deptBiz) Create(ctx context.Context, req *CreateDeptRequest) (*DeptResponse, error) {
@@ -71,6 +85,7 @@ func (b *deptBiz) Create(ctx context.Context, req *CreateDeptRequest) (*DeptResp
 	if err := b.store.Depts().Create(ctx, dept); err != nil {
 		return nil, err
 	}
+	b.invalidateDeptIDs(ctx)
 	return b.toDeptResponse(dept), nil
 }
 
@@ -91,8 +106,27 @@ func (b *deptBiz) Update(ctx context.Context, id uint64, req *UpdateDeptRequest)
 	return b.store.Depts().Update(ctx, dept)
 }
 
+// Move moves a department (and its descendants) under a new parent
+func (b *deptBiz) Move(ctx context.Context, id uint64, req *MoveDeptRequest) error {
+	if err := b.store.Depts().Move(ctx, id, req.ParentID); err != nil {
+		if stderrors.Is(err, store.ErrDeptMoveCycle) {
+			return errors.ErrDeptInvalidParentError
+		}
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.ErrDeptNotFoundError
+		}
+		return err
+	}
+	b.invalidateDeptIDs(ctx)
+	return nil
+}
+
 func (b *deptBiz) Delete(ctx context.Context, id uint64) error {
-	return b.store.Depts().Delete(ctx, id)
+	if err := b.store.Depts().Delete(ctx, id); err != nil {
+		return err
+	}
+	b.invalidateDeptIDs(ctx)
+	return nil
 }
 
 func (b *deptBiz) Get(ctx context.Context, id uint64) (*DeptResponse, error) {
@@ -112,6 +146,14 @@ func (b *deptBiz) GetTree(ctx context.Context) ([]*DeptTreeNode, error) {
 	return b.toDeptTree(tree), nil
 }
 
+// invalidateDeptIDs drops cached department subtrees of data scope (O(1) generation bump)
+// Failures are logged, cached subtrees expire by TTL
+func (b *deptBiz) invalidateDeptIDs(ctx context.Context) {
+	if _, err := b.generations.Bump(ctx, cache.DeptNamespace); err != nil {
+		log.Printf("⚠️  Failed to invalidate dept ID cache: %v", err)
+	}
+}
+
 func (b *deptBiz) toDeptResponse(dept *model.Dept) *DeptResponse {
 	return &DeptResponse{
 		ID:       dept.ID,
@@ -3,7 +3,6 @@ package menu
 import (
 	"context"
 
-	"github.com/sword-demon/go-react-admin/internal/admin/biz"
 	"github.com/sword-demon/go-react-admin/internal/admin/store"
 	"github.com/sword-demon/go-react-admin/internal/pkg/model"
 )
@@ -12,11 +11,57 @@ type menuBiz struct {
 	store store.IStore
 }
 
-func NewMenuBiz(store store.IStore) biz.IMenuBiz {
+// Keep interface here to avoid import cycle
+type IMenuBiz interface {
+	Create(ctx context.Context, req *CreateMenuRequest) (*MenuResponse, error)
+	Update(ctx context.Context, id uint64, req *UpdateMenuRequest) error
+	Delete(ctx context.Context, id uint64) error
+	Get(ctx context.Context, id uint64) (*MenuResponse, error)
+	GetTree(ctx context.Context) ([]*MenuTreeNode, error)
+	GetUserMenus(ctx context.Context, userID uint64) ([]*MenuTreeNode, error)
+}
+
+type CreateMenuRequest struct {
+	ParentID  uint64 `json:"parent_id"`
+	MenuName  string `json:"menu_name" binding:"required"`
+	MenuType  string `json:"menu_type"` // D=Directory, M=Menu, B=Button
+	Path      string `json:"path"`
+	Component string `json:"component"`
+	Perms     string `json:"perms"`
+	Icon      string `json:"icon"`
+	Sort      int    `json:"sort"`
+}
+
+type UpdateMenuRequest struct {
+	MenuName string `json:"menu_name"`
+	Path     string `json:"path"`
+	Status   int8   `json:"status"`
+}
+
+type MenuResponse struct {
+	ID        uint64 `json:"id"`
+	MenuName  string `json:"menu_name"`
+	ParentID  uint64 `json:"parent_id"`
+	MenuType  string `json:"menu_type"`
+	Path      string `json:"path"`
+	Component string `json:"component"`
+	Perms     string `json:"perms"`
+	Icon      string `json:"icon"`
+	Sort      int    `json:"sort"`
+	Visible   int8   `json:"visible"`
+	Status    int8   `json:"status"`
+}
+
+type MenuTreeNode struct {
+	*MenuResponse
+	Children []*MenuTreeNode `json:"children,omitempty"`
+}
+
+func NewMenuBiz(store store.IStore) IMenuBiz {
 	return &menuBiz{store: store}
 }
 
-func (b *menuBiz) Create(ctx context.Context, req *biz.CreateMenuRequest) (*biz.MenuResponse, error) {
+func (b *menuBiz) Create(ctx context.Context, req *CreateMenuRequest) (*MenuResponse, error) {
 	menu := &model.Menu{
 		ParentID:  req.ParentID,
 		MenuName:  req.MenuName,
@@ -35,7 +80,7 @@ func (b *menuBiz) Create(ctx context.Context, req *biz.CreateMenuRequest) (*biz.
 	return b.toMenuResponse(menu), nil
 }
 
-func (b *menuBiz) Update(ctx context.Context, id uint64, req *biz.UpdateMenuRequest) error {
+func (b *menuBiz) Update(ctx context.Context, id uint64, req *UpdateMenuRequest) error {
 	menu, err := b.store.Menus().Get(ctx, id)
 	if err != nil {
 		return err
@@ -56,7 +101,7 @@ func (b *menuBiz) Delete(ctx context.Context, id uint64) error {
 	return b.store.Menus().Delete(ctx, id)
 }
 
-func (b *menuBiz) Get(ctx context.Context, id uint64) (*biz.MenuResponse, error) {
+func (b *menuBiz) Get(ctx context.Context, id uint64) (*MenuResponse, error) {
 	menu, err := b.store.Menus().Get(ctx, id)
 	if err != nil {
 		return nil, err
@@ -64,7 +109,7 @@ func (b *menuBiz) Get(ctx context.Context, id uint64) (*biz.MenuResponse, error)
 	return b.toMenuResponse(menu), nil
 }
 
-func (b *menuBiz) GetTree(ctx context.Context) ([]*biz.MenuTreeNode, error) {
+func (b *menuBiz) GetTree(ctx context.Context) ([]*MenuTreeNode, error) {
 	menus, err := b.store.Menus().List(ctx)
 	if err != nil {
 		return nil, err
@@ -73,7 +118,7 @@ func (b *menuBiz) GetTree(ctx context.Context) ([]*biz.MenuTreeNode, error) {
 	return b.toMenuTree(tree), nil
 }
 
-func (b *menuBiz) GetUserMenus(ctx context.Context, userID uint64) ([]*biz.MenuTreeNode, error) {
+func (b *menuBiz) GetUserMenus(ctx context.Context, userID uint64) ([]*MenuTreeNode, error) {
 	menus, err := b.store.Menus().GetByUserID(ctx, userID)
 	if err != nil {
 		return nil, err
@@ -82,12 +127,12 @@ func (b *menuBiz) GetUserMenus(ctx context.Context, userID uint64) ([]*biz.MenuT
 	return b.toMenuTree(tree), nil
 }
 
-func (b *menuBiz) toMenuResponse(menu *model.Menu) *biz.MenuResponse {
-	return &biz.MenuResponse{
+func (b *menuBiz) toMenuResponse(menu *model.Menu) *MenuResponse {
+	return &MenuResponse{
 		ID:        menu.ID,
 		MenuName:  menu.MenuName,
 		ParentID:  menu.ParentID,
-		MenuType:  string(menu.MenuType),
+		MenuType:  getMenuTypeCode(menu.MenuType),
 		Path:      menu.Path,
 		Component: menu.Component,
 		Perms:     menu.PermKey,
@@ -98,10 +143,10 @@ func (b *menuBiz) toMenuResponse(menu *model.Menu) *biz.MenuResponse {
 	}
 }
 
-func (b *menuBiz) toMenuTree(menus []*model.Menu) []*biz.MenuTreeNode {
-	nodes := make([]*biz.MenuTreeNode, 0, len(menus))
+func (b *menuBiz) toMenuTree(menus []*model.Menu) []*MenuTreeNode {
+	nodes := make([]*MenuTreeNode, 0, len(menus))
 	for _, menu := range menus {
-		node := &biz.MenuTreeNode{
+		node := &MenuTreeNode{
 			MenuResponse: b.toMenuResponse(menu),
 		}
 		if len(menu.Children) > 0 {
@@ -124,3 +169,14 @@ func getMenuType(menuType string) uint8 {
 		return model.MenuTypeMenu
 	}
 }
+
+func getMenuTypeCode(menuType uint8) string {
+	switch menuType {
+	case model.MenuTypeDirectory:
+		return "D"
+	case model.MenuTypeButton:
+		return "B"
+	default:
+		return "M"
+	}
+}
@@ -0,0 +1,190 @@
+package permission
+
+// Matcher is a compiled set of permission rules
+// Rules are indexed by module name and path segments (trie), so a check costs
+// O(path depth) instead of scanning every rule
+// Matcher is immutable after NewMatcher and safe for concurrent use
+type Matcher struct {
+	rules   []string
+	global  []parsedPattern        // *:*, !*:*
+	modules map[string]*moduleNode // Module patterns by module name ("*" for any module)
+	paths   *pathNode              // Path patterns by segment
+}
+
+// moduleNode indexes module patterns by action ("*", read, write, or concrete action)
+type moduleNode struct {
+	actions map[string][]parsedPattern
+}
+
+// pathNode is a trie node of path patterns
+type pathNode struct {
+	children map[string]*pathNode // Literal segments
+	param    *pathNode            // ":param" and mid-path "*" (exactly one segment)
+	rules    []parsedPattern      // Patterns ending at this node
+	tail     []parsedPattern      // Trailing "*" patterns (zero or more remaining segments)
+}
+
+// NewMatcher compiles permission rules (deny rules prefixed with "!")
+// Invalid rules are ignored, same as uncompiled matching
+func NewMatcher(rules []string) *Matcher {
+	m := &Matcher{
+		rules:   append([]string(nil), rules...),
+		modules: make(map[string]*moduleNode),
+		paths:   &pathNode{},
+	}
+
+	for _, rule := range rules {
+		p := parseRule(rule)
+		switch p.kind {
+		case kindGlobal:
+			m.global = append(m.global, p)
+		case kindModule:
+			node, ok := m.modules[p.module]
+			if !ok {
+				node = &moduleNode{actions: make(map[string][]parsedPattern)}
+				m.modules[p.module] = node
+			}
+			node.actions[p.action] = append(node.actions[p.action], p)
+		case kindPath:
+			m.paths.insert(p)
+		}
+	}
+
+	return m
+}
+
+// Rules returns a copy of the source rules (Matcher is immutable and shared via cache)
+func (m *Matcher) Rules() []string {
+	return append(make([]string, 0, len(m.rules)), m.rules...)
+}
+
+// Empty reports whether matcher has no rules (denies everything)
+func (m *Matcher) Empty() bool {
+	return len(m.rules) == 0
+}
+
+// CacheSize estimates matcher memory for the L1 memory bound (cache.Sized)
+// Each rule is kept as source string plus a parsed pattern in one index
+func (m *Matcher) CacheSize() int64 {
+	const ruleOverhead = 160 // parsedPattern, index slots and segment headers
+	size := int64(128)       // Matcher, maps and trie root
+	for _, rule := range m.rules {
+		size += int64(2*len(rule) + ruleOverhead)
+	}
+	return size
+}
+
+// Allowed checks if rules grant the request
+// requestPatterns are alternative forms of one request, evaluated together
+// Same semantics as MatchRules: any matching deny rule wins, otherwise any matching grant allows
+func (m *Matcher) Allowed(requestPatterns ...string) bool {
+	var r resolution
+	for _, requestPattern := range requestPatterns {
+		req := parseRequest(requestPattern)
+		switch req.kind {
+		case kindModule:
+			m.resolveModule(&r, req)
+		case kindPath:
+			m.paths.resolve(&r, req, req.segments)
+		default:
+			continue
+		}
+		for _, p := range m.global {
+			r.add(p)
+		}
+	}
+	return r.allowed()
+}
+
+// resolveModule applies module patterns of req.module and "*" module
+func (m *Matcher) resolveModule(r *resolution, req permissionRequest) {
+	for _, module := range [...]string{req.module, "*"} {
+		node, ok := m.modules[module]
+		if !ok {
+			continue
+		}
+		for _, action := range [...]string{req.action, "*", "read", "write"} {
+			for _, p := range node.actions[action] {
+				if p.matchModule(req) {
+					r.add(p)
+				}
+			}
+		}
+		if req.module == "*" {
+			break
+		}
+	}
+}
+
+// insert adds a path pattern to the trie
+func (n *pathNode) insert(p parsedPattern) {
+	node := n
+	for i, segment := range p.segments {
+		switch {
+		case segment == "*" && i == len(p.segments)-1:
+			node.tail = append(node.tail, p)
+			return
+		case segment == "*", len(segment) > 0 && segment[0] == ':':
+			if node.param == nil {
+				node.param = &pathNode{}
+			}
+			node = node.param
+		default:
+			if node.children == nil {
+				node.children = make(map[string]*pathNode)
+			}
+			child, ok := node.children[segment]
+			if !ok {
+				child = &pathNode{}
+				node.children[segment] = child
+			}
+			node = child
+		}
+	}
+	node.rules = append(node.rules, p)
+}
+
+// resolve applies path patterns matching the remaining segments
+func (n *pathNode) resolve(r *resolution, req permissionRequest, segments []string) {
+	n.apply(r, req, n.tail)
+	if len(segments) == 0 {
+		n.apply(r, req, n.rules)
+		return
+	}
+
+	if child, ok := n.children[segments[0]]; ok {
+		child.resolve(r, req, segments[1:])
+	}
+	if n.param != nil && segments[0] != "" {
+		n.param.resolve(r, req, segments[1:])
+	}
+}
+
+// apply adds patterns whose method restriction allows the request
+func (n *pathNode) apply(r *resolution, req permissionRequest, patterns []parsedPattern) {
+	for _, p := range patterns {
+		if p.matchMethod(req.method) {
+			r.add(p)
+		}
+	}
+}
+
+// resolution tracks matching rules (deny overrides)
+type resolution struct {
+	granted bool
+	denied  bool
+}
+
+// add records a matching rule (p must come from parseRule)
+func (r *resolution) add(p parsedPattern) {
+	if p.deny {
+		r.denied = true
+	} else {
+		r.granted = true
+	}
+}
+
+// allowed reports the final decision (no matching grant or any matching deny means deny)
+func (r *resolution) allowed() bool {
+	return r.granted && !r.denied
+}
@@ -0,0 +1,364 @@
+package permission
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sword-demon/go-react-admin/internal/pkg/errors"
+	"github.com/sword-demon/go-react-admin/internal/pkg/model"
+)
+
+// patternKind represents the form of a permission pattern
+type patternKind int
+
+const (
+	kindInvalid patternKind = iota
+	kindGlobal              // *:*
+	kindModule              // user:*, user:read, user:create, user:read:GET
+	kindPath                // /api/users/*, /api/users/:id:GET, /api/users/*/roles:GET|POST
+)
+
+// Read/write action aliases: user:read, user:write
+var (
+	// readMethods are HTTP methods matched by "read" action
+	readMethods = map[string]bool{"GET": true, "HEAD": true, "OPTIONS": true}
+
+	// writeMethods are HTTP methods matched by "write" action
+	writeMethods = map[string]bool{"POST": true, "PUT": true, "PATCH": true, "DELETE": true}
+
+	// readActions are actions matched by "read" when request has no method
+	readActions = map[string]bool{
+		"read": true, "list": true, "get": true, "detail": true, "query": true, "view": true, "export": true,
+	}
+
+	// writeActions are actions matched by "write" when request has no method
+	writeActions = map[string]bool{
+		"write": true, "create": true, "add": true, "update": true, "edit": true,
+		"delete": true, "remove": true, "import": true, "assign": true,
+	}
+)
+
+// httpMethods are valid method tokens in method suffix (e.g., /api/users:GET|POST)
+var httpMethods = map[string]bool{
+	"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "HEAD": true, "OPTIONS": true,
+}
+
+// parsedPattern represents a parsed user permission pattern
+type parsedPattern struct {
+	kind     patternKind
+	deny     bool // Deny rule (!user:delete)
+	module   string
+	action   string
+	segments []string        // Path segments (path patterns only)
+	methods  map[string]bool // Allowed methods, nil means any method
+}
+
+// permissionRequest represents a parsed request pattern
+// Request examples: user:create, user:create:POST, /api/v1/users/123:GET
+type permissionRequest struct {
+	kind     patternKind
+	module   string
+	action   string
+	segments []string
+	method   string // Empty if request has no method
+}
+
+// parseRule parses a user permission rule, "!" prefix marks a deny rule
+func parseRule(rule string) parsedPattern {
+	rule = strings.TrimSpace(rule)
+	pattern, deny := strings.CutPrefix(rule, model.DenyRulePrefix)
+
+	p := parsePattern(pattern)
+	p.deny = deny
+	return p
+}
+
+// parsePattern parses a user permission pattern
+// Specificity does not resolve conflicts: any matching deny rule wins (see MatchRules)
+//
+// User pattern examples:
+//   - *:*                      global
+//   - user:*, user:read        module wildcard / module action (read=GET, write=POST/PUT/PATCH/DELETE)
+//   - /api/users/*             path prefix (any method)
+//   - /api/users:GET|POST      path + method set
+//   - /api/users/*/roles:GET   mid-path wildcard (one segment)
+//   - /api/users/:id:DELETE    path parameter (one segment)
+//
+// Request pattern examples: user:create, user:create:POST, /api/users/123:GET
+// Module patterns only match module requests and path patterns only match path requests
+func parsePattern(pattern string) parsedPattern {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return parsedPattern{kind: kindInvalid}
+	}
+	if pattern == "*:*" || pattern == "*" {
+		return parsedPattern{kind: kindGlobal}
+	}
+
+	// Path pattern: /api/users/:id:GET|POST
+	if strings.HasPrefix(pattern, "/") {
+		path, methodSuffix, hasMethod := splitMethodSuffix(pattern)
+		p := parsedPattern{kind: kindPath, segments: splitPath(path)}
+		if hasMethod {
+			methods, ok := parseMethods(methodSuffix)
+			if !ok {
+				return parsedPattern{kind: kindInvalid}
+			}
+			p.methods = methods
+		}
+		return p
+	}
+
+	// Module pattern: module:action[:METHODS]
+	parts := strings.Split(pattern, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return parsedPattern{kind: kindInvalid}
+	}
+	p := parsedPattern{kind: kindModule, module: parts[0], action: parts[1]}
+	if len(parts) == 3 {
+		methods, ok := parseMethods(parts[2])
+		if !ok {
+			return parsedPattern{kind: kindInvalid}
+		}
+		p.methods = methods
+	}
+	if p.module == "*" && p.action == "*" && p.methods == nil {
+		return parsedPattern{kind: kindGlobal}
+	}
+	return p
+}
+
+// parseRequest parses a request pattern (concrete path/action, at most one method)
+func parseRequest(request string) permissionRequest {
+	request = strings.TrimSpace(request)
+	if request == "" {
+		return permissionRequest{kind: kindInvalid}
+	}
+
+	if strings.HasPrefix(request, "/") {
+		path, method, hasMethod := splitMethodSuffix(request)
+		if hasMethod && !httpMethods[method] {
+			return permissionRequest{kind: kindInvalid}
+		}
+		return permissionRequest{kind: kindPath, segments: splitPath(path), method: method}
+	}
+
+	parts := strings.Split(request, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return permissionRequest{kind: kindInvalid}
+	}
+	req := permissionRequest{kind: kindModule, module: parts[0], action: parts[1]}
+	if len(parts) == 3 {
+		if !httpMethods[parts[2]] {
+			return permissionRequest{kind: kindInvalid}
+		}
+		req.method = parts[2]
+	}
+	return req
+}
+
+// match checks if parsed user pattern matches the request
+func (p parsedPattern) match(req permissionRequest) bool {
+	switch p.kind {
+	case kindGlobal:
+		return req.kind != kindInvalid
+	case kindModule:
+		return req.kind == kindModule && p.matchModule(req)
+	case kindPath:
+		return req.kind == kindPath && p.matchMethod(req.method) && matchSegments(p.segments, req.segments)
+	default:
+		return false
+	}
+}
+
+// matchModule matches module:action patterns (including read/write aliases)
+func (p parsedPattern) matchModule(req permissionRequest) bool {
+	if p.module != "*" && p.module != req.module {
+		return false
+	}
+	if !p.matchMethod(req.method) {
+		return false
+	}
+
+	switch {
+	case p.action == "*", p.action == req.action:
+		return true
+	case p.action == "read":
+		if req.method != "" {
+			return readMethods[req.method]
+		}
+		return readActions[req.action]
+	case p.action == "write":
+		if req.method != "" {
+			return writeMethods[req.method]
+		}
+		return writeActions[req.action]
+	default:
+		return false
+	}
+}
+
+// matchMethod checks method restriction (nil = any method)
+// A method-restricted pattern never matches a request without method
+func (p parsedPattern) matchMethod(method string) bool {
+	if p.methods == nil {
+		return true
+	}
+	return method != "" && p.methods[method]
+}
+
+// matchSegments matches path segments
+// - literal segment must be equal
+// - ":param" matches exactly one segment
+// - "*" in the middle matches exactly one segment
+// - trailing "*" matches zero or more remaining segments
+func matchSegments(pattern, path []string) bool {
+	for i, segment := range pattern {
+		if segment == "*" && i == len(pattern)-1 {
+			return true
+		}
+		if i >= len(path) {
+			return false
+		}
+		switch {
+		case segment == "*", strings.HasPrefix(segment, ":"):
+			if path[i] == "" {
+				return false
+			}
+		case segment != path[i]:
+			return false
+		}
+	}
+	return len(pattern) == len(path)
+}
+
+// splitMethodSuffix splits "/api/users:GET|POST" into ("/api/users", "GET|POST", true)
+// A colon that starts a path segment is a parameter (/api/users/:id), not a method suffix
+func splitMethodSuffix(pattern string) (string, string, bool) {
+	idx := strings.LastIndex(pattern, ":")
+	if idx <= 0 || pattern[idx-1] == '/' {
+		return pattern, "", false
+	}
+	suffix := pattern[idx+1:]
+	if strings.Contains(suffix, "/") {
+		return pattern, "", false
+	}
+	return pattern[:idx], suffix, true
+}
+
+// parseMethods parses "GET|POST" into a method set, "*" means any method (nil)
+func parseMethods(s string) (map[string]bool, bool) {
+	if s == "*" {
+		return nil, true
+	}
+	methods := make(map[string]bool)
+	for _, method := range strings.Split(s, "|") {
+		if !httpMethods[method] {
+			return nil, false
+		}
+		methods[method] = true
+	}
+	return methods, true
+}
+
+// splitPath splits path into segments ("/" → no segments)
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return []string{}
+	}
+	return strings.Split(path, "/")
+}
+
+// maxPatternLength is the size of sys_role_permission.permission_pattern
+const maxPatternLength = 100
+
+// ClassifyPattern validates a permission rule and returns its type and effect
+// A "!" prefix marks a deny rule (e.g., !user:delete)
+//
+// Types:
+//   - *:*                              → global
+//   - user:*, user:*:GET               → module
+//   - user:read, user:create, *:export → action
+//   - /api/users/*, /api/users/:id:GET → path
+func ClassifyPattern(rule string) (permissionType string, effect string, err error) {
+	pattern, deny := strings.CutPrefix(rule, model.DenyRulePrefix)
+	effect = model.PermissionEffectAllow
+	if deny {
+		effect = model.PermissionEffectDeny
+	}
+
+	invalid := errors.New(errors.ErrPermissionInvalidPattern, fmt.Sprintf("invalid permission pattern: %q", rule))
+	if pattern == "" || len(rule) > maxPatternLength || !validPatternChars(pattern) {
+		return "", "", invalid
+	}
+
+	p := parsePattern(pattern)
+	switch p.kind {
+	case kindGlobal:
+		return model.PermissionTypeGlobal, effect, nil
+	case kindModule:
+		if !validName(p.module) || !validName(p.action) {
+			return "", "", invalid
+		}
+		if p.action == "*" {
+			return model.PermissionTypeModule, effect, nil
+		}
+		return model.PermissionTypeAction, effect, nil
+	case kindPath:
+		if len(p.segments) == 0 {
+			return "", "", invalid
+		}
+		for _, segment := range p.segments {
+			if !validSegment(segment) {
+				return "", "", invalid
+			}
+		}
+		return model.PermissionTypePath, effect, nil
+	default:
+		return "", "", invalid
+	}
+}
+
+// validPatternChars rejects whitespace and control characters
+func validPatternChars(pattern string) bool {
+	for _, r := range pattern {
+		if r <= ' ' || r == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// validName checks module/action name: "*" or [A-Za-z0-9_-]+
+func validName(name string) bool {
+	if name == "*" {
+		return true
+	}
+	for _, r := range name {
+		if !isNameChar(r) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// validSegment checks path segment: literal, ":param", or "*"
+func validSegment(segment string) bool {
+	switch {
+	case segment == "":
+		return false
+	case segment == "*":
+		return true
+	case strings.HasPrefix(segment, ":"):
+		return validName(segment[1:]) && segment != ":*"
+	default:
+		// "*" must be a whole segment (no /api/user*)
+		return !strings.ContainsAny(segment, "*:")
+	}
+}
+
+// isNameChar reports whether r is allowed in module/action/param names
+func isNameChar(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-'
+}
@@ -0,0 +1,321 @@
+package permission
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sword-demon/go-react-admin/internal/pkg/errors"
+	"github.com/sword-demon/go-react-admin/internal/pkg/model"
+)
+
+// matchPattern checks a single pattern against a request, ignoring rule effect
+// Production code evaluates rules with MatchRules or Matcher
+func matchPattern(userPattern, requestPattern string) bool {
+	// Fast path: global permission and exact match
+	if userPattern == "*:*" || userPattern == requestPattern {
+		return parseRequest(requestPattern).kind != kindInvalid
+	}
+
+	return parsePattern(userPattern).match(parseRequest(requestPattern))
+}
+
+// TestMatchPattern tests permission pattern matching
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		request string
+		want    bool
+	}{
+		// Global wildcard
+		{"global matches module request", "*:*", "user:create", true},
+		{"global matches path request", "*:*", "/api/v1/users/1:DELETE", true},
+		{"global rejects invalid request", "*:*", "", false},
+
+		// Exact match
+		{"exact module action", "user:create", "user:create", true},
+		{"exact module action with request method", "user:create", "user:create:POST", true},
+		{"different action", "user:create", "user:delete", false},
+		{"exact path and method", "/api/users:GET", "/api/users:GET", true},
+		{"exact path other method", "/api/users:GET", "/api/users:POST", false},
+		{"exact path without method suffix", "/api/users", "/api/users:DELETE", true},
+
+		// Module wildcard
+		{"module wildcard", "user:*", "user:delete", true},
+		{"module wildcard with method", "user:*", "user:delete:DELETE", true},
+		{"module wildcard other module", "user:*", "role:delete", false},
+		{"module wildcard module prefix", "user:*", "users:delete", false},
+		{"module wildcard does not match path", "user:*", "/api/users:GET", false},
+
+		// read/write aliases by method
+		{"read matches GET", "user:read", "user:list:GET", true},
+		{"read rejects POST", "user:read", "user:create:POST", false},
+		{"write matches POST", "user:write", "user:create:POST", true},
+		{"write matches PUT", "user:write", "user:update:PUT", true},
+		{"write matches DELETE", "user:write", "user:delete:DELETE", true},
+		{"write rejects GET", "user:write", "user:list:GET", false},
+
+		// read/write aliases by action verb (no method)
+		{"read matches list", "user:read", "user:list", true},
+		{"read matches detail", "user:read", "user:detail", true},
+		{"read rejects delete", "user:read", "user:delete", false},
+		{"write matches create", "user:write", "user:create", true},
+		{"write rejects list", "user:write", "user:list", false},
+
+		// Module pattern with method set
+		{"module method set allows", "user:*:GET|HEAD", "user:list:GET", true},
+		{"module method set rejects", "user:*:GET|HEAD", "user:create:POST", false},
+		{"module method set needs method", "user:*:GET", "user:list", false},
+
+		// Path wildcard (trailing)
+		{"path wildcard child", "/api/users/*", "/api/users/123:GET", true},
+		{"path wildcard deep child", "/api/users/*", "/api/users/123/roles:PUT", true},
+		{"path wildcard base path", "/api/users/*", "/api/users:GET", true},
+		{"path wildcard without request method", "/api/users/*", "/api/users/123", true},
+		{"path wildcard segment prefix", "/api/users/*", "/api/usersettings:GET", false},
+		{"path wildcard other path", "/api/users/*", "/api/roles/1:GET", false},
+		{"path wildcard with method", "/api/users/*:GET", "/api/users/1:GET", true},
+		{"path wildcard with other method", "/api/users/*:GET", "/api/users/1:DELETE", false},
+
+		// Method sets
+		{"method set first", "/api/users:GET|POST", "/api/users:GET", true},
+		{"method set second", "/api/users:GET|POST", "/api/users:POST", true},
+		{"method set excluded", "/api/users:GET|POST", "/api/users:DELETE", false},
+		{"method set needs request method", "/api/users:GET|POST", "/api/users", false},
+		{"method wildcard", "/api/users:*", "/api/users:PATCH", true},
+
+		// Mid-path wildcard
+		{"mid wildcard", "/api/users/*/roles", "/api/users/5/roles:GET", true},
+		{"mid wildcard with method", "/api/users/*/roles:PUT", "/api/users/5/roles:PUT", true},
+		{"mid wildcard wrong method", "/api/users/*/roles:PUT", "/api/users/5/roles:GET", false},
+		{"mid wildcard one segment only", "/api/users/*/roles", "/api/users/5/6/roles:GET", false},
+		{"mid wildcard wrong tail", "/api/users/*/roles", "/api/users/5/menus:GET", false},
+		{"mid wildcard missing segment", "/api/users/*/roles", "/api/users/roles:GET", false},
+
+		// :id style parameters
+		{"param matches", "/api/users/:id", "/api/users/42:GET", true},
+		{"param with method", "/api/users/:id:DELETE", "/api/users/42:DELETE", true},
+		{"param with other method", "/api/users/:id:DELETE", "/api/users/42:GET", false},
+		{"param one segment only", "/api/users/:id", "/api/users/42/roles:GET", false},
+		{"param requires segment", "/api/users/:id", "/api/users:GET", false},
+		{"param in middle", "/api/users/:id/roles:GET", "/api/users/42/roles:GET", true},
+
+		// Kind mismatch and invalid patterns
+		{"path pattern does not match module request", "/api/users/*", "user:list", false},
+		{"empty pattern", "", "user:list", false},
+		{"invalid module pattern", "user", "user:list", false},
+		{"invalid method suffix", "/api/users:FETCH", "/api/users:FETCH", false},
+		{"lowercase method suffix", "/api/users:get", "/api/users:GET", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchPattern(tt.pattern, tt.request); got != tt.want {
+				t.Errorf("matchPattern(%q, %q) = %v, want %v", tt.pattern, tt.request, got, tt.want)
+			}
+			if got := NewMatcher([]string{tt.pattern}).Allowed(tt.request); got != tt.want {
+				t.Errorf("Matcher(%q).Allowed(%q) = %v, want %v", tt.pattern, tt.request, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMatchPermission tests matching against a set of user patterns
+func TestMatchPermission(t *testing.T) {
+	permissions := []string{"user:read", "dept:*", "/api/v1/roles/*:GET"}
+
+	tests := []struct {
+		request string
+		want    bool
+	}{
+		{"user:list:GET", true},
+		{"user:create:POST", false},
+		{"dept:delete:DELETE", true},
+		{"/api/v1/roles/1:GET", true},
+		{"/api/v1/roles/1:PUT", false},
+		{"menu:list:GET", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchRules(permissions, tt.request); got != tt.want {
+			t.Errorf("MatchRules(%v, %q) = %v, want %v", permissions, tt.request, got, tt.want)
+		}
+		if got := NewMatcher(permissions).Allowed(tt.request); got != tt.want {
+			t.Errorf("Matcher(%v).Allowed(%q) = %v, want %v", permissions, tt.request, got, tt.want)
+		}
+	}
+}
+
+// TestMatchPermissionDeny tests deny rules override grants
+func TestMatchPermissionDeny(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []string
+		request     string
+		want        bool
+	}{
+		{"deny exact overrides module wildcard", []string{"user:*", "!user:delete"}, "user:delete:DELETE", false},
+		{"module wildcard still grants others", []string{"user:*", "!user:delete"}, "user:create:POST", true},
+		{"deny module wildcard overrides global", []string{"*:*", "!user:*"}, "user:list:GET", false},
+		{"global still grants other modules", []string{"*:*", "!user:*"}, "dept:list:GET", true},
+		{"module deny overrides exact grant", []string{"!user:*", "user:list"}, "user:list:GET", false},
+		{"module deny blocks other actions", []string{"!user:*", "user:list"}, "user:create:POST", false},
+		{"deny wins over same pattern", []string{"user:*", "!user:*"}, "user:list:GET", false},
+		{"deny wins regardless of order", []string{"!user:delete", "user:delete"}, "user:delete", false},
+		{"module wildcard deny overrides module action", []string{"!user:*", "user:read"}, "user:list:GET", false},
+		{"write deny overrides module wildcard", []string{"user:*", "!user:write"}, "user:update:PUT", false},
+		{"path wildcard deny overrides module action", []string{"user:read", "!/api/users/*"}, "/api/users/1:GET", false},
+		{"path wildcard deny overrides exact path grant", []string{"!/api/users/*", "/api/users:GET"}, "/api/users:GET", false},
+		{"path deny with method", []string{"/api/users/*", "!/api/users/:id:DELETE"}, "/api/users/1:DELETE", false},
+		{"path deny other method", []string{"/api/users/*", "!/api/users/:id:DELETE"}, "/api/users/1:GET", true},
+		{"deny only", []string{"!user:delete"}, "user:list", false},
+		{"no rules", nil, "user:list", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchRules(tt.permissions, tt.request); got != tt.want {
+				t.Errorf("MatchRules(%v, %q) = %v, want %v", tt.permissions, tt.request, got, tt.want)
+			}
+			if got := NewMatcher(tt.permissions).Allowed(tt.request); got != tt.want {
+				t.Errorf("Matcher(%v).Allowed(%q) = %v, want %v", tt.permissions, tt.request, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMatchPermissionRequestForms tests deny rules across module and path request forms
+func TestMatchPermissionRequestForms(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []string
+		requests    []string
+		want        bool
+	}{
+		{"module deny not bypassed by global", []string{"*:*", "!user:delete"}, []string{"user:delete:DELETE", "/api/v1/users/1:DELETE"}, false},
+		{"path deny not bypassed by module grant", []string{"user:*", "!/api/v1/users/:id:DELETE"}, []string{"user:delete:DELETE", "/api/v1/users/1:DELETE"}, false},
+		{"module wildcard deny overrides path grant", []string{"!user:*", "/api/v1/users/*"}, []string{"user:list:GET", "/api/v1/users:GET"}, false},
+		{"either form grants", []string{"user:list"}, []string{"user:list:GET", "/api/v1/users:GET"}, true},
+		{"no form matches", []string{"dept:*"}, []string{"user:list:GET", "/api/v1/users:GET"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchRules(tt.permissions, tt.requests...); got != tt.want {
+				t.Errorf("MatchRules(%v, %v) = %v, want %v", tt.permissions, tt.requests, got, tt.want)
+			}
+			if got := NewMatcher(tt.permissions).Allowed(tt.requests...); got != tt.want {
+				t.Errorf("Matcher(%v).Allowed(%v) = %v, want %v", tt.permissions, tt.requests, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMatchPermissionAcrossRoles tests a deny of any role overrides grants of other roles
+// (user rules are the union of rules of all enabled roles)
+func TestMatchPermissionAcrossRoles(t *testing.T) {
+	tests := []struct {
+		name    string
+		roleA   []string
+		roleB   []string
+		request string
+		want    bool
+	}{
+		{"exact grant of A, module deny of B", []string{"user:list"}, []string{"!user:*"}, "user:list:GET", false},
+		{"global grant of A, action deny of B", []string{"*:*"}, []string{"!user:delete", "dept:*"}, "user:delete:DELETE", false},
+		{"deny of B only covers its patterns", []string{"*:*"}, []string{"!user:delete", "dept:*"}, "user:create:POST", true},
+		{"grants of both roles", []string{"user:read"}, []string{"user:create"}, "user:create:POST", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, rules := range [][]string{append(append([]string{}, tt.roleA...), tt.roleB...), append(append([]string{}, tt.roleB...), tt.roleA...)} {
+				if got := MatchRules(rules, tt.request); got != tt.want {
+					t.Errorf("MatchRules(%v, %q) = %v, want %v", rules, tt.request, got, tt.want)
+				}
+				if got := NewMatcher(rules).Allowed(tt.request); got != tt.want {
+					t.Errorf("Matcher(%v).Allowed(%q) = %v, want %v", rules, tt.request, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// TestMatcherEquivalence tests compiled matcher against uncompiled matching on a mixed rule set
+func TestMatcherEquivalence(t *testing.T) {
+	rules := []string{
+		"user:*", "!user:delete", "role:read", "!role:*:DELETE", "dept:write", "*:export",
+		"/api/v1/menus/*", "!/api/v1/menus/:id:DELETE", "/api/v1/roles/*/permissions:GET|PUT",
+		"/api/v1/logs:GET", "!/api/v1/logs/*", "invalid", "/api/v1/bad:FETCH",
+	}
+	requests := []string{
+		"user:list:GET", "user:delete:DELETE", "user:delete", "role:list:GET", "role:update:PUT",
+		"role:delete:DELETE", "dept:create:POST", "dept:list:GET", "menu:export", "menu:list",
+		"/api/v1/menus:GET", "/api/v1/menus/1:DELETE", "/api/v1/menus/1/items:DELETE",
+		"/api/v1/roles/1/permissions:PUT", "/api/v1/roles/1/permissions:POST", "/api/v1/logs:GET",
+		"/api/v1/logs/1:GET", "/api/v1//permissions:GET", "/", "",
+	}
+
+	matcher := NewMatcher(rules)
+	for _, req := range requests {
+		if got, want := matcher.Allowed(req), MatchRules(rules, req); got != want {
+			t.Errorf("Matcher.Allowed(%q) = %v, MatchRules = %v", req, got, want)
+		}
+	}
+}
+
+// TestClassifyPattern tests permission pattern validation and classification
+func TestClassifyPattern(t *testing.T) {
+	tests := []struct {
+		rule       string
+		wantType   string
+		wantEffect string
+		wantErr    bool
+	}{
+		{"*:*", model.PermissionTypeGlobal, model.PermissionEffectAllow, false},
+		{"user:*", model.PermissionTypeModule, model.PermissionEffectAllow, false},
+		{"user:*:GET|HEAD", model.PermissionTypeModule, model.PermissionEffectAllow, false},
+		{"user:read", model.PermissionTypeAction, model.PermissionEffectAllow, false},
+		{"user:create", model.PermissionTypeAction, model.PermissionEffectAllow, false},
+		{"*:export", model.PermissionTypeAction, model.PermissionEffectAllow, false},
+		{"!user:delete", model.PermissionTypeAction, model.PermissionEffectDeny, false},
+		{"/api/users/*", model.PermissionTypePath, model.PermissionEffectAllow, false},
+		{"/api/users/:id:DELETE", model.PermissionTypePath, model.PermissionEffectAllow, false},
+		{"/api/users/*/roles:GET|POST", model.PermissionTypePath, model.PermissionEffectAllow, false},
+		{"!/api/users/*", model.PermissionTypePath, model.PermissionEffectDeny, false},
+
+		{"", "", "", true},
+		{"!", "", "", true},
+		{"user", "", "", true},
+		{"user:", "", "", true},
+		{":read", "", "", true},
+		{"user:read:FETCH", "", "", true},
+		{"user:re ad", "", "", true},
+		{"user.name:read", "", "", true},
+		{"user:read:GET:POST", "", "", true},
+		{"/", "", "", true},
+		{"/api//users", "", "", true},
+		{"/api/user*", "", "", true},
+		{"/api/users/:", "", "", true},
+		{"/api/users:get", "", "", true},
+		{"/api/users/" + strings.Repeat("a", 100), "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			gotType, gotEffect, err := ClassifyPattern(tt.rule)
+			if tt.wantErr {
+				if !errors.Is(err, errors.ErrPermissionInvalidPattern) {
+					t.Errorf("ClassifyPattern(%q) error = %v, want ErrPermissionInvalidPattern", tt.rule, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ClassifyPattern(%q) unexpected error: %v", tt.rule, err)
+			}
+			if gotType != tt.wantType || gotEffect != tt.wantEffect {
+				t.Errorf("ClassifyPattern(%q) = (%q, %q), want (%q, %q)", tt.rule, gotType, gotEffect, tt.wantType, tt.wantEffect)
+			}
+		})
+	}
+}
@@ -3,91 +3,174 @@ package permission
 import (
 	"context"
 	"encoding/json"
-	"fmt"
-	"strings"
+	stderrors "errors"
+	"log"
 	"time"
 
-	"github.com/redis/go-redis/v9"
-	"github.com/sword-demon/go-react-admin/internal/admin/biz"
 	"github.com/sword-demon/go-react-admin/internal/admin/store"
 	"github.com/sword-demon/go-react-admin/internal/pkg/cache"
 	"github.com/sword-demon/go-react-admin/internal/pkg/errors"
+	"gorm.io/gorm"
 )
 
+// negativeCacheTTL is TTL of cached missing users/roles and empty permission sets
+// Short so new role assignments to previously empty users show up quickly
+const negativeCacheTTL = time.Minute
+
+// roleUserBatchSize is the number of role users invalidated per batch
+const roleUserBatchSize = 500
+
 type permissionBiz struct {
-	store      store.IStore
-	localCache *cache.LocalCache
-	redis      *cache.RedisClient
+	store       store.IStore
+	localCache  *cache.LocalCache
+	loads       *cache.LoadGroup
+	generations *cache.Generations      // Versions permission/role keys for O(1) bulk invalidation
+	users       *cache.Tiered[*Matcher] // User permissions, compiled in L1
+	roles       *cache.Tiered[[]string] // Role permissions
+}
+
+// IPermissionBiz defines permission business logic operations
+// Keep interface here to avoid import cycle
+type IPermissionBiz interface {
+	GetUserPermissions(ctx context.Context, userID uint64) ([]string, error)
+	CheckPermission(ctx context.Context, userID uint64, patterns ...string) (bool, error)
+	ClearCache(ctx context.Context, userID uint64) error
+	ClearUserCache(ctx context.Context, userID uint64) error
+	ClearRoleCache(ctx context.Context, roleID uint64) error
+	GetCacheStats() cache.CacheStats
+	GetLoadStats() cache.LoadStats
+
+	// PermissionLoader and WarmupTargetSource are used by cache warmup
+	cache.PermissionLoader
+	cache.WarmupTargetSource
+}
+
+// matcherCodec stores compiled matchers as JSON rules in Redis
+var matcherCodec = cache.FuncCodec[*Matcher]{
+	EncodeFunc: func(matcher *Matcher) ([]byte, error) {
+		return json.Marshal(matcher.Rules())
+	},
+	DecodeFunc: func(data []byte) (*Matcher, error) {
+		var rules []string
+		if err := json.Unmarshal(data, &rules); err != nil {
+			return nil, err
+		}
+		return NewMatcher(rules), nil
+	},
+}
+
+// Compiled matchers are persisted in L1 snapshots as rules (matcherCodec)
+func init() {
+	cache.RegisterSnapshotType("permission.matcher", matcherCodec)
 }
 
 // NewPermissionBiz creates a new permission biz with three-tier cache
-func NewPermissionBiz(store store.IStore, localCache *cache.LocalCache, redis *cache.RedisClient) biz.IPermissionBiz {
+// invalidator broadcasts cache clears to other instances (nil = single instance)
+// loads coalesces concurrent cache misses, generations versions cache keys,
+// share both between biz instances
+func NewPermissionBiz(store store.IStore, localCache *cache.LocalCache, redis *cache.RedisClient, invalidator *cache.Invalidator, loads *cache.LoadGroup, generations *cache.Generations) IPermissionBiz {
 	return &permissionBiz{
-		store:      store,
-		localCache: localCache,
-		redis:      redis,
+		store:       store,
+		localCache:  localCache,
+		loads:       loads,
+		generations: generations,
+		users: cache.NewTiered(localCache, redis, cache.TieredConfig[*Matcher]{
+			Name:        "user_permissions",
+			Codec:       matcherCodec,
+			LocalTTL:    5 * time.Minute,
+			RedisTTL:    30 * time.Minute,
+			Invalidator: invalidator,
+			Loads:       loads,
+			NegativeTTL: negativeCacheTTL,
+			IsEmpty:     (*Matcher).Empty,
+		}),
+		roles: cache.NewTiered(localCache, redis, cache.TieredConfig[[]string]{
+			Name:        "role_permissions",
+			Clone:       cache.CloneSlice[string],
+			LocalTTL:    5 * time.Minute,
+			RedisTTL:    30 * time.Minute,
+			Invalidator: invalidator,
+			Loads:       loads,
+			NegativeTTL: negativeCacheTTL,
+			IsEmpty:     func(permissions []string) bool { return len(permissions) == 0 },
+		}),
 	}
 }
 
 // GetUserPermissions retrieves all permission patterns for a user (with three-tier cache)
-// Cache strategy:
-// - L1 (Local): 5min TTL, <1ms latency
-// - L2 (Redis): 30min TTL, <10ms latency
-// - L3 (MySQL): persistent, 10-50ms latency
 func (b *permissionBiz) GetUserPermissions(ctx context.Context, userID uint64) ([]string, error) {
-	cacheKey := cache.PermissionCacheKey(userID)
-
-	// Layer 1: Try local cache
-	if val, ok := b.localCache.Get(cacheKey); ok {
-		if permissions, ok := val.([]string); ok {
-			return permissions, nil
-		}
+	matcher, err := b.getUserMatcher(ctx, userID)
+	if stderrors.Is(err, cache.ErrNotFound) {
+		return nil, errors.ErrUserNotFoundError
+	}
+	if err != nil {
+		return nil, err
 	}
+	return matcher.Rules(), nil
+}
 
-	// Layer 2: Try Redis
-	if b.redis != nil {
-		data, err := b.redis.Get(ctx, cacheKey)
-		if err == nil {
-			var permissions []string
-			if err := json.Unmarshal([]byte(data), &permissions); err == nil {
-				// Backfill to local cache
-				b.localCache.SetWithTTL(cacheKey, permissions, 5*time.Minute)
-				return permissions, nil
-			}
-		}
-		// Ignore redis.Nil (cache miss), propagate other errors
-		if err != nil && err != redis.Nil {
-			// Log error but continue to database
-			// log.Printf("Redis error: %v", err)
-		}
+// getUserMatcher retrieves compiled permission matcher for a user (with three-tier cache)
+// Cache strategy:
+// - L1 (Local): 5min TTL, <1ms latency, stores compiled *Matcher
+// - L2 (Redis): 30min TTL, <10ms latency, stores JSON rules
+// - L3 (MySQL): persistent, 10-50ms latency
+//
+// Concurrent misses of the same user share one L2/L3 load,
+// hot users are reloaded in background before L1 expiration
+//
+// Keys are versioned by PermissionNamespace generation, if it is unavailable
+// (Redis down) permissions are loaded from MySQL without cache
+//
+// Missing users (cache.ErrNotFound) and users without permissions are
+// cached for negativeCacheTTL, so replayed tokens of deleted users do not reach MySQL
+func (b *permissionBiz) getUserMatcher(ctx context.Context, userID uint64) (*Matcher, error) {
+	cacheKey, err := b.generations.Key(ctx, cache.PermissionNamespace, cache.PermissionCacheKey(userID))
+	if err != nil {
+		log.Printf("⚠️  Permission cache bypassed (user: %d): %v", userID, err)
+		return b.loadUserMatcher(ctx, userID)
 	}
+	return b.users.Get(ctx, cacheKey, func(ctx context.Context) (*Matcher, error) {
+		return b.loadUserMatcher(ctx, userID)
+	})
+}
 
-	// Layer 3: Load from database
+// loadUserMatcher loads and compiles user permissions from MySQL
+// Returns cache.ErrNotFound if user does not exist
+func (b *permissionBiz) loadUserMatcher(ctx context.Context, userID uint64) (*Matcher, error) {
 	permissions, err := b.store.Permissions().GetUserPermissions(ctx, userID)
 	if err != nil {
 		return nil, errors.Wrap(errors.ErrInternalServer, "failed to get user permissions", err)
 	}
 
-	// Backfill to L2 (Redis) and L1 (Local)
-	if b.redis != nil {
-		data, _ := json.Marshal(permissions)
-		_ = b.redis.Set(ctx, cacheKey, data, 30*time.Minute) // Ignore error
+	// No permissions: tell deleted users apart from users without roles
+	if len(permissions) == 0 {
+		if _, err := b.store.Users().Get(ctx, userID); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, cache.ErrNotFound
+			}
+			return nil, errors.Wrap(errors.ErrInternalServer, "failed to get user", err)
+		}
 	}
-	b.localCache.SetWithTTL(cacheKey, permissions, 5*time.Minute)
 
-	return permissions, nil
+	return NewMatcher(permissions), nil
 }
 
-// CheckPermission checks if user has permission using pattern matching (with cache)
-func (b *permissionBiz) CheckPermission(ctx context.Context, userID uint64, pattern string) (bool, error) {
-	// Get user's permission patterns (cached)
-	permissions, err := b.GetUserPermissions(ctx, userID)
+// CheckPermission checks if user has permission using compiled matcher (with cache)
+// Multiple patterns describe the same request (e.g., user:delete:DELETE and /api/v1/users/1:DELETE)
+// and are evaluated together, so a deny rule on any form cannot be bypassed by another form
+func (b *permissionBiz) CheckPermission(ctx context.Context, userID uint64, patterns ...string) (bool, error) {
+	// Get user's compiled permission matcher (cached)
+	matcher, err := b.getUserMatcher(ctx, userID)
+	if stderrors.Is(err, cache.ErrNotFound) {
+		// Deleted user has no permissions
+		return false, nil
+	}
 	if err != nil {
 		return false, err
 	}
 
 	// Check if user has matching permission
-	return matchPermission(permissions, pattern), nil
+	return matcher.Allowed(patterns...), nil
 }
 
 // ClearCache clears user permission cache (all three tiers)
@@ -96,36 +179,22 @@ func (b *permissionBiz) CheckPermission(ctx context.Context, userID uint64, patt
 // - Role permissions changed
 // - User deleted
 func (b *permissionBiz) ClearCache(ctx context.Context, userID uint64) error {
-	cacheKey := cache.PermissionCacheKey(userID)
-
-	// Clear local cache
-	b.localCache.Delete(cacheKey)
-
-	// Clear Redis cache
-	if b.redis != nil {
-		if err := b.redis.Del(ctx, cacheKey); err != nil {
-			return errors.Wrap(errors.ErrInternalServer, "failed to clear Redis cache", err)
-		}
+	cacheKey, err := b.generations.Key(ctx, cache.PermissionNamespace, cache.PermissionCacheKey(userID))
+	if err != nil {
+		return errors.Wrap(errors.ErrInternalServer, "failed to clear permission cache", err)
+	}
+	if err := b.users.Delete(ctx, cacheKey); err != nil {
+		return errors.Wrap(errors.ErrInternalServer, "failed to clear permission cache", err)
 	}
-
 	return nil
 }
 
 // ClearUserCache clears all cache related to a user
 // This includes permissions, roles, profile, etc.
 func (b *permissionBiz) ClearUserCache(ctx context.Context, userID uint64) error {
-	prefix := cache.UserCacheKeyPrefix(userID)
-
-	// Clear local cache
-	b.localCache.DeletePrefix(prefix)
-
-	// Clear Redis cache
-	if b.redis != nil {
-		if _, err := b.redis.DeleteByPrefix(ctx, prefix); err != nil {
-			return errors.Wrap(errors.ErrInternalServer, "failed to clear user cache", err)
-		}
+	if _, _, err := b.users.DeletePrefix(ctx, cache.UserCacheKeyPrefix(userID)); err != nil {
+		return errors.Wrap(errors.ErrInternalServer, "failed to clear user cache", err)
 	}
-
 	return nil
 }
 
@@ -133,23 +202,52 @@ func (b *permissionBiz) ClearUserCache(ctx context.Context, userID uint64) error
 // Called when:
 // - Role permissions changed
 // - Role deleted
+//
+// 1. Bumps the role namespace generation: role permission cache and other role:{id} keys, O(1)
+// 2. Evicts permission cache of the role's users in batches (role → user lookup),
+// other users keep their cached permissions
 func (b *permissionBiz) ClearRoleCache(ctx context.Context, roleID uint64) error {
-	// 1. Clear role permission cache
-	roleCacheKey := cache.RolePermissionCacheKey(roleID)
-	b.localCache.Delete(roleCacheKey)
-	if b.redis != nil {
-		_ = b.redis.Del(ctx, roleCacheKey)
+	if _, err := b.generations.Bump(ctx, cache.RoleNamespace(roleID)); err != nil {
+		return errors.Wrap(errors.ErrInternalServer, "failed to clear role cache", err)
+	}
+
+	var afterUserID uint64
+	for {
+		userIDs, err := b.store.Roles().GetUserIDs(ctx, roleID, afterUserID, roleUserBatchSize)
+		if err != nil {
+			return errors.Wrap(errors.ErrInternalServer, "failed to get role users", err)
+		}
+		if len(userIDs) == 0 {
+			break
+		}
+
+		if err := b.clearUsersPermissionCache(ctx, userIDs); err != nil {
+			return err
+		}
+
+		if len(userIDs) < roleUserBatchSize {
+			break
+		}
+		afterUserID = userIDs[len(userIDs)-1]
 	}
+	return nil
+}
 
-	// 2. Clear all users with this role
-	// TODO: This requires getting all users with this role from database
-	// For now, we can use a wildcard delete for role-related cache
-	prefix := cache.RoleCacheKeyPrefix(roleID)
-	b.localCache.DeletePrefix(prefix)
-	if b.redis != nil {
-		_, _ = b.redis.DeleteByPrefix(ctx, prefix)
+// clearUsersPermissionCache evicts permission cache of multiple users
+// One L1 lock, one Redis DEL and one invalidation event per batch
+func (b *permissionBiz) clearUsersPermissionCache(ctx context.Context, userIDs []uint64) error {
+	keys := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		cacheKey, err := b.generations.Key(ctx, cache.PermissionNamespace, cache.PermissionCacheKey(userID))
+		if err != nil {
+			return errors.Wrap(errors.ErrInternalServer, "failed to clear permission cache", err)
+		}
+		keys = append(keys, cacheKey)
 	}
 
+	if err := b.users.Delete(ctx, keys...); err != nil {
+		return errors.Wrap(errors.ErrInternalServer, "failed to clear permission cache", err)
+	}
 	return nil
 }
 
@@ -158,6 +256,11 @@ func (b *permissionBiz) GetCacheStats() cache.CacheStats {
 	return b.localCache.Stats()
 }
 
+// GetLoadStats returns load coalescing and early refresh statistics
+func (b *permissionBiz) GetLoadStats() cache.LoadStats {
+	return b.loads.Stats()
+}
+
 // LoadUserPermissions implements cache.PermissionLoader interface
 // Used by cache warmup to preload user permissions
 func (b *permissionBiz) LoadUserPermissions(ctx context.Context, userID uint64) ([]string, error) {
@@ -167,95 +270,77 @@ func (b *permissionBiz) LoadUserPermissions(ctx context.Context, userID uint64)
 // LoadRolePermissions implements cache.PermissionLoader interface
 // Used by cache warmup to preload role permissions
 func (b *permissionBiz) LoadRolePermissions(ctx context.Context, roleID uint64) ([]string, error) {
-	cacheKey := cache.RolePermissionCacheKey(roleID)
-
-	// Try local cache first
-	if val, ok := b.localCache.Get(cacheKey); ok {
-		if permissions, ok := val.([]string); ok {
-			return permissions, nil
-		}
+	cacheKey, err := b.generations.Key(ctx, cache.RoleNamespace(roleID), cache.RolePermissionCacheKey(roleID))
+	var permissions []string
+	if err != nil {
+		log.Printf("⚠️  Role permission cache bypassed (role: %d): %v", roleID, err)
+		permissions, err = b.loadRolePermissions(ctx, roleID)
+	} else {
+		permissions, err = b.roles.Get(ctx, cacheKey, func(ctx context.Context) ([]string, error) {
+			return b.loadRolePermissions(ctx, roleID)
+		})
 	}
-
-	// Try Redis
-	if b.redis != nil {
-		data, err := b.redis.Get(ctx, cacheKey)
-		if err == nil {
-			var permissions []string
-			if err := json.Unmarshal([]byte(data), &permissions); err == nil {
-				b.localCache.SetWithTTL(cacheKey, permissions, 5*time.Minute)
-				return permissions, nil
-			}
-		}
+	if stderrors.Is(err, cache.ErrNotFound) {
+		return nil, errors.ErrRoleNotFoundError
 	}
+	return permissions, err
+}
 
-	// Load from database
+// loadRolePermissions loads role permission rules from MySQL
+// Returns cache.ErrNotFound if role does not exist
+func (b *permissionBiz) loadRolePermissions(ctx context.Context, roleID uint64) ([]string, error) {
 	rolePerms, err := b.store.Permissions().GetRolePermissions(ctx, roleID)
 	if err != nil {
 		return nil, errors.Wrap(errors.ErrInternalServer, "failed to get role permissions", err)
 	}
 
-	// Extract permission patterns
-	permissions := make([]string, 0, len(rolePerms))
-	for _, rp := range rolePerms {
-		permissions = append(permissions, rp.Permission)
+	// No permissions: tell missing roles apart from empty roles
+	if len(rolePerms) == 0 {
+		if _, err := b.store.Roles().Get(ctx, roleID); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, cache.ErrNotFound
+			}
+			return nil, errors.Wrap(errors.ErrInternalServer, "failed to get role", err)
+		}
 	}
 
-	// Backfill cache
-	if b.redis != nil {
-		data, _ := json.Marshal(permissions)
-		_ = b.redis.Set(ctx, cacheKey, data, 30*time.Minute)
+	// Extract permission rules (deny rules prefixed with "!")
+	permissions := make([]string, 0, len(rolePerms))
+	for _, rp := range rolePerms {
+		permissions = append(permissions, rp.Rule())
 	}
-	b.localCache.SetWithTTL(cacheKey, permissions, 5*time.Minute)
-
 	return permissions, nil
 }
 
-// matchPermission checks if pattern matches any user permission
-func matchPermission(userPermissions []string, requestPattern string) bool {
-	for _, userPerm := range userPermissions {
-		if matchPattern(userPerm, requestPattern) {
-			return true
-		}
-	}
-	return false
-}
-
-// matchPattern checks if a permission pattern matches the request pattern
-// Priority (high → low):
-// 1. Exact match: /api/users:GET
-// 2. Path wildcard: /api/users/*
-// 3. Module permission: user:read, user:write
-// 4. Module wildcard: user:*
-// 5. Global wildcard: *:*
+// MatchRules checks if permission rules grant the request without compiling them
+// requestPatterns are alternative forms of one request, evaluated together
+// Rules prefixed with "!" are deny rules (e.g., !user:delete)
+// Prefer Matcher for repeated checks against the same rules
 //
-// User pattern examples: *:*, user:*, user:read, /api/users/*
-// Request pattern examples: user:create, /api/users/123
-func matchPattern(userPattern, requestPattern string) bool {
-	// Global permission: *:* (priority 5)
-	if userPattern == "*:*" {
-		return true
-	}
-
-	// Exact match (priority 1)
-	if userPattern == requestPattern {
-		return true
-	}
-
-	// Module wildcard: user:* matches user:create, user:read, etc. (priority 4)
-	if strings.HasSuffix(userPattern, ":*") {
-		module := strings.TrimSuffix(userPattern, ":*")
-		if strings.HasPrefix(requestPattern, module+":") {
-			return true
+// Conflict resolution (deny overrides):
+// 1. Any matching deny rule denies, whatever the role and specificity of matching grants
+// 2. Otherwise any matching grant allows
+// 3. No matching rule means deny
+//
+// e.g., [user:*, !user:delete] denies user:delete but allows user:create,
+// [!user:*, user:list] denies user:list (carve exceptions out of grants, not out of denies)
+func MatchRules(rules []string, requestPatterns ...string) bool {
+	requests := make([]permissionRequest, 0, len(requestPatterns))
+	for _, requestPattern := range requestPatterns {
+		if req := parseRequest(requestPattern); req.kind != kindInvalid {
+			requests = append(requests, req)
 		}
 	}
 
-	// Path wildcard: /api/users/* matches /api/users/123, /api/users/create, etc. (priority 2)
-	if strings.HasSuffix(userPattern, "/*") {
-		prefix := strings.TrimSuffix(userPattern, "/*")
-		if strings.HasPrefix(requestPattern, prefix+"/") || requestPattern == prefix {
-			return true
+	var r resolution
+	for _, rule := range rules {
+		p := parseRule(rule)
+		for _, req := range requests {
+			if p.match(req) {
+				r.add(p)
+			}
 		}
 	}
 
-	return false
+	return r.allowed()
 }
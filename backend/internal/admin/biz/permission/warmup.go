@@ -0,0 +1,84 @@
+package permission
+
+import (
+	"context"
+	"slices"
+
+	"github.com/sword-demon/go-react-admin/internal/pkg/cache"
+	"github.com/sword-demon/go-react-admin/internal/pkg/errors"
+)
+
+// superAdminRule grants every permission, its holders are warmed first
+const superAdminRule = "*:*"
+
+// warmupUserBatch is the batch size of role user lookups
+const warmupUserBatch = 500
+
+// WarmupTargets implements cache.WarmupTargetSource interface
+// Roles: all enabled roles with permissions (by ID)
+// Users: holders of *:* roles first, then users by most recent login since query.RecentLoginSince
+func (b *permissionBiz) WarmupTargets(ctx context.Context, query cache.WarmupTargetQuery) (*cache.WarmupTargets, error) {
+	rolePermissions, err := b.store.Permissions().GetAllPermissions(ctx)
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrInternalServer, "failed to get role permissions", err)
+	}
+
+	targets := &cache.WarmupTargets{}
+	var superAdminRoleIDs []uint64
+	for roleID, rules := range rolePermissions {
+		targets.RoleIDs = append(targets.RoleIDs, roleID)
+		if slices.Contains(rules, superAdminRule) {
+			superAdminRoleIDs = append(superAdminRoleIDs, roleID)
+		}
+	}
+	slices.Sort(targets.RoleIDs)
+	slices.Sort(superAdminRoleIDs)
+	if query.MaxRoles > 0 && len(targets.RoleIDs) > query.MaxRoles {
+		targets.RoleIDs = targets.RoleIDs[:query.MaxRoles]
+	}
+
+	seen := make(map[uint64]struct{})
+	full := func() bool {
+		return query.MaxUsers > 0 && len(targets.UserIDs) >= query.MaxUsers
+	}
+	addUsers := func(userIDs []uint64) {
+		for _, userID := range userIDs {
+			if full() {
+				return
+			}
+			if _, ok := seen[userID]; ok {
+				continue
+			}
+			seen[userID] = struct{}{}
+			targets.UserIDs = append(targets.UserIDs, userID)
+		}
+	}
+
+	// Super admins: every permission check of theirs is a hit afterwards
+	for _, roleID := range superAdminRoleIDs {
+		var afterUserID uint64
+		for !full() {
+			userIDs, err := b.store.Roles().GetUserIDs(ctx, roleID, afterUserID, warmupUserBatch)
+			if err != nil {
+				return nil, errors.Wrap(errors.ErrInternalServer, "failed to get role users", err)
+			}
+			addUsers(userIDs)
+			if len(userIDs) < warmupUserBatch {
+				break
+			}
+			afterUserID = userIDs[len(userIDs)-1]
+		}
+	}
+
+	// Recently active users
+	if !query.RecentLoginSince.IsZero() && !full() {
+		// MaxUsers rows fill the remaining slots even if every super admin logged in too
+		userIDs, err := b.store.Users().GetRecentLoginUserIDs(ctx, query.RecentLoginSince, query.MaxUsers)
+		if err != nil {
+			return nil, errors.Wrap(errors.ErrInternalServer, "failed to get recent login users", err)
+		}
+		addUsers(userIDs)
+	}
+
+	return targets, nil
+}
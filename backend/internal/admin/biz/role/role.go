@@ -3,30 +3,100 @@ package role
 import (
 	"context"
 	"fmt"
+	"log"
+	"strings"
 
-	"github.com/sword-demon/go-react-admin/internal/admin/biz"
+	"github.com/sword-demon/go-react-admin/internal/admin/biz/permission"
 	"github.com/sword-demon/go-react-admin/internal/admin/store"
 	"github.com/sword-demon/go-react-admin/internal/pkg/cache"
+	"github.com/sword-demon/go-react-admin/internal/pkg/errors"
 	"github.com/sword-demon/go-react-admin/internal/pkg/model"
 	"gorm.io/gorm"
 )
 
 // roleBiz implements IRoleBiz interface
 type roleBiz struct {
-	store store.IStore
-	cache *cache.RedisClient
+	store       store.IStore
+	cache       *cache.RedisClient
+	permissions permission.IPermissionBiz
+}
+
+// IRoleBiz defines role business logic operations
+// Keep interface here to avoid import cycle
+type IRoleBiz interface {
+	Create(ctx context.Context, req *CreateRoleRequest) (*RoleResponse, error)
+	Update(ctx context.Context, id uint64, req *UpdateRoleRequest) error
+	Delete(ctx context.Context, id uint64) error
+	Get(ctx context.Context, id uint64) (*RoleResponse, error)
+	List(ctx context.Context, req *ListRoleRequest) (*ListRoleResponse, error)
+	AssignPermissions(ctx context.Context, roleID uint64, req *AssignPermissionsRequest) error
+	AssignDataScope(ctx context.Context, roleID uint64, req *AssignDataScopeRequest) error
+}
+
+// Request/Response structs
+type CreateRoleRequest struct {
+	RoleName string `json:"role_name" binding:"required"`
+	RoleKey  string `json:"role_key" binding:"required"`
+	Sort     int    `json:"sort"`
+}
+
+type UpdateRoleRequest struct {
+	RoleName string `json:"role_name"`
+	Sort     int    `json:"sort"`
+	Status   int8   `json:"status"`
+}
+
+type RoleResponse struct {
+	ID       uint64 `json:"id"`
+	RoleName string `json:"role_name"`
+	RoleKey  string `json:"role_key"`
+	Sort     int    `json:"sort"`
+	Status   int8   `json:"status"`
+}
+
+type ListRoleRequest struct {
+	Page     int    `form:"page"`
+	PageSize int    `form:"page_size"`
+	RoleName string `form:"role_name"`
+}
+
+type ListRoleResponse struct {
+	Total int64           `json:"total"`
+	Items []*RoleResponse `json:"items"`
+}
+
+// AssignPermissionsRequest replaces all permission patterns of a role
+type AssignPermissionsRequest struct {
+	Permissions []PermissionItem `json:"permissions"`
+}
+
+// PermissionItem represents a permission pattern to assign
+// Prefix pattern with "!" to deny (e.g., !user:delete)
+type PermissionItem struct {
+	Pattern     string `json:"pattern" binding:"required"`
+	Description string `json:"description"`
+}
+
+// AssignDataScopeRequest sets data scope of a role
+// DeptIDs and IncludeChildren apply to DataScopeCustom only
+type AssignDataScopeRequest struct {
+	DataScope       uint8    `json:"data_scope" binding:"required,min=1,max=5"`
+	DeptIDs         []uint64 `json:"dept_ids"`
+	IncludeChildren bool     `json:"include_children"` // Also grant descendants of each dept
 }
 
 // NewRoleBiz creates a new role biz
-func NewRoleBiz(store store.IStore, cache *cache.RedisClient) biz.IRoleBiz {
+// permissions is used to invalidate cached permissions of role users
+func NewRoleBiz(store store.IStore, cache *cache.RedisClient, permissions permission.IPermissionBiz) IRoleBiz {
 	return &roleBiz{
-		store: store,
-		cache: cache,
+		store:       store,
+		cache:       cache,
+		permissions: permissions,
 	}
 }
 
 // Create creates a new role
-func (b *roleBiz) Create(ctx context.Context, req *biz.CreateRoleRequest) (*biz.RoleResponse, error) {
+func (b *roleBiz) Create(ctx context.Context, req *CreateRoleRequest) (*RoleResponse, error) {
 	// Check if role_key exists
 	_, err := b.store.Roles().GetByKey(ctx, req.RoleKey)
 	if err == nil {
@@ -54,7 +124,7 @@ func (b *roleBiz) Create(ctx context.Context, req *biz.CreateRoleRequest) (*biz.
 }
 
 // Update updates role information
-func (b *roleBiz) Update(ctx context.Context, id uint64, req *biz.UpdateRoleRequest) error {
+func (b *roleBiz) Update(ctx context.Context, id uint64, req *UpdateRoleRequest) error {
 	role, err := b.store.Roles().Get(ctx, id)
 	if err != nil {
 		return err
@@ -79,7 +149,7 @@ func (b *roleBiz) Delete(ctx context.Context, id uint64) error {
 }
 
 // Get retrieves role by ID
-func (b *roleBiz) Get(ctx context.Context, id uint64) (*biz.RoleResponse, error) {
+func (b *roleBiz) Get(ctx context.Context, id uint64) (*RoleResponse, error) {
 	role, err := b.store.Roles().Get(ctx, id)
 	if err != nil {
 		return nil, err
@@ -88,7 +158,7 @@ func (b *roleBiz) Get(ctx context.Context, id uint64) (*biz.RoleResponse, error)
 }
 
 // List retrieves roles with pagination
-func (b *roleBiz) List(ctx context.Context, req *biz.ListRoleRequest) (*biz.ListRoleResponse, error) {
+func (b *roleBiz) List(ctx context.Context, req *ListRoleRequest) (*ListRoleResponse, error) {
 	opts := &store.ListOptions{
 		Page:     req.Page,
 		PageSize: req.PageSize,
@@ -111,26 +181,112 @@ func (b *roleBiz) List(ctx context.Context, req *biz.ListRoleRequest) (*biz.List
 		return nil, err
 	}
 
-	items := make([]*biz.RoleResponse, 0, len(roles))
+	items := make([]*RoleResponse, 0, len(roles))
 	for _, role := range roles {
 		items = append(items, b.toRoleResponse(role))
 	}
 
-	return &biz.ListRoleResponse{
+	return &ListRoleResponse{
 		Total: total,
 		Items: items,
 	}, nil
 }
 
-// AssignPermissions assigns permissions to role
-func (b *roleBiz) AssignPermissions(ctx context.Context, roleID uint64, permissionIDs []uint64) error {
-	// TODO: Implement permission assignment logic
-	return fmt.Errorf("not implemented yet")
+// AssignPermissions replaces role permission patterns
+// Each pattern is validated and classified (global/module/action/path)
+// Cached permissions of all users with this role are invalidated (L1 + L2)
+// Invalidation failures are logged, not returned: permissions are already saved
+// and cached entries expire by TTL, so a retry would only repeat the write
+func (b *roleBiz) AssignPermissions(ctx context.Context, roleID uint64, req *AssignPermissionsRequest) error {
+	// 1. Check role exists
+	if _, err := b.store.Roles().Get(ctx, roleID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrRoleNotFoundError
+		}
+		return err
+	}
+
+	// 2. Validate and classify patterns (skip duplicates)
+	perms := make([]*model.RolePermission, 0, len(req.Permissions))
+	seen := make(map[string]struct{}, len(req.Permissions))
+	for _, item := range req.Permissions {
+		rule := strings.TrimSpace(item.Pattern)
+		permissionType, effect, err := permission.ClassifyPattern(rule)
+		if err != nil {
+			return err
+		}
+		if _, ok := seen[rule]; ok {
+			continue
+		}
+		seen[rule] = struct{}{}
+
+		perms = append(perms, &model.RolePermission{
+			RoleID:            roleID,
+			PermissionPattern: strings.TrimPrefix(rule, model.DenyRulePrefix),
+			PermissionType:    permissionType,
+			Effect:            effect,
+			Description:       item.Description,
+			Status:            model.StatusEnabled,
+		})
+	}
+
+	// 3. Replace existing permissions
+	if len(perms) == 0 {
+		if err := b.store.Permissions().DeleteRolePermissions(ctx, roleID); err != nil {
+			return err
+		}
+	} else if err := b.store.Permissions().BatchCreateRolePermissions(ctx, perms); err != nil {
+		return err
+	}
+
+	// 4. Invalidate cached permissions of role users
+	if err := b.permissions.ClearRoleCache(ctx, roleID); err != nil {
+		log.Printf("⚠️  Failed to invalidate permission cache (role: %d): %v", roleID, err)
+	}
+	return nil
+}
+
+// AssignDataScope sets role data scope and its departments (DataScopeCustom) in one transaction
+// Other scopes clear assigned departments
+func (b *roleBiz) AssignDataScope(ctx context.Context, roleID uint64, req *AssignDataScopeRequest) error {
+	if req.DataScope < model.DataScopeAll || req.DataScope > model.DataScopeCustom {
+		return errors.New(errors.ErrInvalidParams, "invalid data scope")
+	}
+	deptIDs := req.DeptIDs
+	if req.DataScope != model.DataScopeCustom {
+		deptIDs = nil
+	} else if len(deptIDs) == 0 {
+		return errors.New(errors.ErrInvalidParams, "custom data scope requires dept_ids")
+	}
+
+	return b.store.Transaction(ctx, func(tx store.IStore) error {
+		role, err := tx.Roles().Get(ctx, roleID)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.ErrRoleNotFoundError
+			}
+			return err
+		}
+		for _, deptID := range deptIDs {
+			if _, err := tx.Depts().Get(ctx, deptID); err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return errors.New(errors.ErrInvalidParams, fmt.Sprintf("dept %d not found", deptID))
+				}
+				return err
+			}
+		}
+
+		role.DataScope = req.DataScope
+		if err := tx.Roles().Update(ctx, role); err != nil {
+			return err
+		}
+		return tx.Roles().AssignDepts(ctx, roleID, deptIDs, req.IncludeChildren)
+	})
 }
 
 // toRoleResponse converts model to response
-func (b *roleBiz) toRoleResponse(role *model.Role) *biz.RoleResponse {
-	return &biz.RoleResponse{
+func (b *roleBiz) toRoleResponse(role *model.Role) *RoleResponse {
+	return &RoleResponse{
 		ID:       role.ID,
 		RoleName: role.RoleName,
 		RoleKey:  role.RoleKey,
@@ -2,6 +2,8 @@ package user
 
 import (
 	"context"
+	"fmt"
+	"log"
 
 	"github.com/sword-demon/go-react-admin/internal/admin/store"
 	"github.com/sword-demon/go-react-admin/internal/pkg/cache"
@@ -13,8 +15,9 @@ import (
 
 // userBiz implements IUserBiz interface
 type userBiz struct {
-	store store.IStore
-	cache *cache.RedisClient
+	store       store.IStore
+	cache       *cache.RedisClient
+	generations *cache.Generations
 }
 
 // IUserBiz defines user business logic operations
@@ -74,10 +77,12 @@ type ListUserResponse struct {
 }
 
 // NewUserBiz creates a new user biz
-func NewUserBiz(store store.IStore, cache *cache.RedisClient) IUserBiz {
+// generations versions data scope cache (departments of users)
+func NewUserBiz(store store.IStore, cache *cache.RedisClient, generations *cache.Generations) IUserBiz {
 	return &userBiz{
-		store: store,
-		cache: cache,
+		store:       store,
+		cache:       cache,
+		generations: generations,
 	}
 }
 
@@ -134,6 +139,7 @@ func (b *userBiz) Update(ctx context.Context, id uint64, req *UpdateUserRequest)
 	}
 
 	// 3. Update fields
+	oldDeptID := user.DeptID
 	if req.NickName != "" {
 		user.NickName = req.NickName
 	}
@@ -161,6 +167,9 @@ func (b *userBiz) Update(ctx context.Context, id uint64, req *UpdateUserRequest)
 		cacheKey := fmt.Sprintf("user:permissions:%d", id)
 		_ = b.cache.Del(ctx, cacheKey)
 	}
+	if user.DeptID != oldDeptID {
+		b.invalidateUserDept(ctx, id)
+	}
 
 	return nil
 }
@@ -191,6 +200,7 @@ func (b *userBiz) Delete(ctx context.Context, id uint64) error {
 		cacheKey := fmt.Sprintf("user:permissions:%d", id)
 		_ = b.cache.Del(ctx, cacheKey)
 	}
+	b.invalidateUserDept(ctx, id)
 
 	return nil
 }
@@ -324,6 +334,14 @@ func (b *userBiz) AssignRoles(ctx context.Context, userID uint64, roleIDs []uint
 	// NOTE: If any step fails, the transaction will be rolled back automatically
 }
 
+// invalidateUserDept drops cached department of a user (data scope), bumping cache.DeptNamespace
+// Failures are logged, the cached department expires by TTL
+func (b *userBiz) invalidateUserDept(ctx context.Context, userID uint64) {
+	if _, err := b.generations.Bump(ctx, cache.DeptNamespace); err != nil {
+		log.Printf("⚠️  Failed to invalidate dept cache (user: %d): %v", userID, err)
+	}
+}
+
 // toUserResponse converts model to response
 func (b *userBiz) toUserResponse(user *model.User) *UserResponse {
 	return &UserResponse{
@@ -0,0 +1,115 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sword-demon/go-react-admin/internal/pkg/auth"
+	"github.com/sword-demon/go-react-admin/internal/pkg/core"
+	"github.com/sword-demon/go-react-admin/internal/pkg/errors"
+)
+
+// AuthController handles login, token refresh and logout
+type AuthController struct {
+	auth *auth.Authenticator
+}
+
+// NewAuthController creates a new auth controller
+func NewAuthController(authenticator *auth.Authenticator) *AuthController {
+	return &AuthController{auth: authenticator}
+}
+
+// LoginRequest represents login request body
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// RefreshRequest represents token refresh request body
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutRequest represents logout request body
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"` // Optional: also revoke refresh token
+}
+
+// LoginResponse represents login response data
+type LoginResponse struct {
+	*auth.TokenPair
+	User *LoginUser `json:"user"`
+}
+
+// LoginUser represents basic user info returned on login
+type LoginUser struct {
+	ID       uint64 `json:"id"`
+	Username string `json:"username"`
+	NickName string `json:"nick_name"`
+	Avatar   string `json:"avatar"`
+	DeptID   uint64 `json:"dept_id"`
+}
+
+// Login authenticates user and returns token pair
+// POST /api/v1/auth/login
+func (c *AuthController) Login(ctx *gin.Context) {
+	var req LoginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		core.WriteResponse(ctx, errors.Wrap(errors.ErrInvalidParams, "invalid login request", err), nil)
+		return
+	}
+
+	pair, user, err := c.auth.Login(ctx.Request.Context(), req.Username, req.Password)
+	if err != nil {
+		core.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	core.WriteResponse(ctx, nil, &LoginResponse{
+		TokenPair: pair,
+		User: &LoginUser{
+			ID:       user.ID,
+			Username: user.Username,
+			NickName: user.NickName,
+			Avatar:   user.Avatar,
+			DeptID:   user.DeptID,
+		},
+	})
+}
+
+// Refresh exchanges refresh token for a new token pair
+// POST /api/v1/auth/refresh
+func (c *AuthController) Refresh(ctx *gin.Context) {
+	var req RefreshRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		core.WriteResponse(ctx, errors.Wrap(errors.ErrInvalidParams, "invalid refresh request", err), nil)
+		return
+	}
+
+	pair, err := c.auth.Refresh(ctx.Request.Context(), req.RefreshToken)
+	if err != nil {
+		core.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	core.WriteResponse(ctx, nil, pair)
+}
+
+// Logout revokes current access token (and refresh token if provided)
+// POST /api/v1/auth/logout (requires auth.Middleware)
+func (c *AuthController) Logout(ctx *gin.Context) {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		core.WriteResponse(ctx, errors.ErrTokenMissingError, nil)
+		return
+	}
+
+	// Body is optional
+	var req LogoutRequest
+	_ = ctx.ShouldBindJSON(&req)
+
+	if err := c.auth.Logout(ctx.Request.Context(), claims, req.RefreshToken); err != nil {
+		core.WriteResponse(ctx, err, nil)
+		return
+	}
+
+	core.WriteResponse(ctx, nil, nil)
+}
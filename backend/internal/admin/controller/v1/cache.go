@@ -2,104 +2,139 @@
 package v1
 
 import (
-	"fmt"
-	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sword-demon/go-react-admin/internal/admin/biz"
+	"github.com/sword-demon/go-react-admin/internal/admin/biz/permission"
 	"github.com/sword-demon/go-react-admin/internal/pkg/cache"
+	"github.com/sword-demon/go-react-admin/internal/pkg/core"
+	"github.com/sword-demon/go-react-admin/internal/pkg/errors"
 )
 
 // CacheController handles cache monitoring operations
 type CacheController struct {
-	permissionBiz biz.IPermissionBiz
-	localCache    *cache.LocalCache
+	permissionBiz permission.IPermissionBiz
+	cache         *cache.ThreeTierCache // L1 + L2 (prefix clearing, stats)
+	warmups       *cache.WarmupJobs
 }
 
 // NewCacheController creates a new cache controller
-func NewCacheController(permissionBiz biz.IPermissionBiz, localCache *cache.LocalCache) *CacheController {
+func NewCacheController(permissionBiz permission.IPermissionBiz, threeTier *cache.ThreeTierCache, warmups *cache.WarmupJobs) *CacheController {
 	return &CacheController{
 		permissionBiz: permissionBiz,
-		localCache:    localCache,
+		cache:         threeTier,
+		warmups:       warmups,
 	}
 }
 
 // GetStats returns cache statistics
 // GET /api/v1/cache/stats
 func (c *CacheController) GetStats(ctx *gin.Context) {
-	stats := c.localCache.Stats()
-
-	ctx.JSON(http.StatusOK, gin.H{
-		"code": 0,
-		"msg":  "success",
-		"data": gin.H{
-			"hits":      stats.Hits,
-			"misses":    stats.Misses,
-			"hit_rate":  stats.HitRate,
-			"size":      stats.Size,
-			"max_size":  stats.MaxSize,
-			"health":    getHealthStatus(stats.HitRate),
-		},
+	all := c.cache.Stats()
+	stats := all.Local
+	breaker := all.Breaker
+
+	core.WriteResponse(ctx, nil, gin.H{
+		"hits":        stats.Hits,
+		"misses":      stats.Misses,
+		"hit_rate":    stats.HitRate,
+		"size":        stats.Size,
+		"max_size":    stats.MaxSize,
+		"bytes":       stats.Bytes,
+		"max_bytes":   stats.MaxBytes,
+		"evictions":   stats.Evictions,
+		"expirations": stats.Expirations,
+		"rejected":    stats.Rejected,
+		"health":      getHealthStatus(stats.HitRate),
+		"loads":       c.permissionBiz.GetLoadStats(),
+		"tiers":       cache.AllTierStats(), // Per cache name: L1/L2/L3 hits, misses, errors, load latency
+		"redis":       breaker,
+		"redis_pool":  all.Redis,
+		"degraded":    breaker.State != cache.BreakerClosed, // L2 skipped, serving L1 + MySQL
 	})
 }
 
 // ClearCache clears specific cache entries
 // DELETE /api/v1/cache
-// Query params: user_id, role_id, prefix
+// Query params: user_id, role_id, prefix (cleared in L1, Redis and other instances' L1)
 func (c *CacheController) ClearCache(ctx *gin.Context) {
 	userID := ctx.Query("user_id")
 	roleID := ctx.Query("role_id")
 	prefix := ctx.Query("prefix")
 
-	var clearedCount int
+	var clearedCount, redisCount int
 
 	if userID != "" {
-		// Clear user cache
-		var uid uint64
-		if _, err := fmt.Sscanf(userID, "%d", &uid); err == nil {
-			c.permissionBiz.ClearCache(ctx, uid)
-			clearedCount++
+		uid, err := strconv.ParseUint(userID, 10, 64)
+		if err != nil {
+			core.WriteResponse(ctx, errors.Wrap(errors.ErrInvalidParams, "invalid user_id", err), nil)
+			return
+		}
+		if err := c.permissionBiz.ClearCache(ctx.Request.Context(), uid); err != nil {
+			core.WriteResponse(ctx, err, nil)
+			return
 		}
+		clearedCount++
 	}
 
 	if roleID != "" {
-		// Clear role cache
-		var rid uint64
-		if _, err := fmt.Sscanf(roleID, "%d", &rid); err == nil {
-			c.permissionBiz.ClearRoleCache(ctx, rid)
-			clearedCount++
+		rid, err := strconv.ParseUint(roleID, 10, 64)
+		if err != nil {
+			core.WriteResponse(ctx, errors.Wrap(errors.ErrInvalidParams, "invalid role_id", err), nil)
+			return
+		}
+		if err := c.permissionBiz.ClearRoleCache(ctx.Request.Context(), rid); err != nil {
+			core.WriteResponse(ctx, err, nil)
+			return
 		}
+		clearedCount++
 	}
 
 	if prefix != "" {
-		// Clear by prefix
-		count := c.localCache.DeletePrefix(prefix)
-		clearedCount += count
+		// Clear by prefix in all tiers
+		localCount, count, err := c.cache.DeletePrefix(ctx.Request.Context(), prefix)
+		if err != nil {
+			core.WriteResponse(ctx, errors.Wrap(errors.ErrInternalServer, "failed to clear cache prefix", err), nil)
+			return
+		}
+		clearedCount += localCount
+		redisCount = count
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"code": 0,
-		"msg":  "cache cleared",
-		"data": gin.H{
-			"cleared_count": clearedCount,
-		},
+	core.WriteResponse(ctx, nil, gin.H{
+		"cleared_count": clearedCount,
+		"redis_count":   redisCount,
 	})
 }
 
-// WarmupCache triggers cache warmup
+// WarmupCache starts a cache warmup job
 // POST /api/v1/cache/warmup
+// Returns the running job if a warmup is already in progress; poll GET /api/v1/cache/warmup/:id
 func (c *CacheController) WarmupCache(ctx *gin.Context) {
-	// TODO: Implement warmup trigger
-	// For now, return not implemented
-	ctx.JSON(http.StatusOK, gin.H{
-		"code": 0,
-		"msg":  "warmup triggered",
-		"data": gin.H{
-			"status": "queued",
-		},
+	job, started, err := c.warmups.Start(ctx.Request.Context())
+	if err != nil {
+		core.WriteResponse(ctx, errors.Wrap(errors.ErrInternalServer, "failed to start cache warmup", err), nil)
+		return
+	}
+
+	core.WriteResponse(ctx, nil, gin.H{
+		"job":     job,
+		"started": started, // false: joined the running warmup
 	})
 }
 
+// GetWarmup returns a warmup job with its statistics
+// GET /api/v1/cache/warmup/:id
+func (c *CacheController) GetWarmup(ctx *gin.Context) {
+	job, ok := c.warmups.Get(ctx.Param("id"))
+	if !ok {
+		core.WriteResponse(ctx, errors.New(errors.ErrNotFound, "warmup job not found"), nil)
+		return
+	}
+
+	core.WriteResponse(ctx, nil, job)
+}
+
 // getHealthStatus determines cache health based on hit rate
 func getHealthStatus(hitRate float64) string {
 	switch {
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sword-demon/go-react-admin/internal/admin/biz/permission"
+	"github.com/sword-demon/go-react-admin/internal/pkg/auth"
+	"github.com/sword-demon/go-react-admin/internal/pkg/core"
+	"github.com/sword-demon/go-react-admin/internal/pkg/errors"
+)
+
+// Permission checks API permission of the authenticated user
+// Must be used after auth.Middleware
+//
+// Request patterns checked together (deny rules on either form apply):
+// 1. module:action:METHOD declared at registration (e.g., user:create:POST)
+// 2. /api/path:METHOD of the actual request (e.g., /api/v1/users/123:DELETE)
+//
+// Routes not declared in registry are only checked by path pattern
+func Permission(permissionBiz permission.IPermissionBiz, registry *RouteRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := auth.FromContext(c)
+		if !ok {
+			core.AbortWithError(c, errors.ErrTokenMissingError)
+			return
+		}
+
+		allowed, err := permissionBiz.CheckPermission(c.Request.Context(), claims.UserID, requestPatterns(c, registry)...)
+		if err != nil {
+			core.AbortWithError(c, err)
+			return
+		}
+		if !allowed {
+			core.AbortWithError(c, errors.ErrPermissionDeniedError)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// requestPatterns derives request patterns from route metadata
+func requestPatterns(c *gin.Context, registry *RouteRegistry) []string {
+	patterns := make([]string, 0, 2)
+
+	if meta, ok := registry.Lookup(c.Request.Method, c.FullPath()); ok && meta.Permission != "" {
+		// Method lets user:read / user:write aliases match by HTTP method
+		patterns = append(patterns, meta.Permission+":"+c.Request.Method)
+	}
+	patterns = append(patterns, c.Request.URL.Path+":"+c.Request.Method)
+
+	return patterns
+}
@@ -0,0 +1,143 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sword-demon/go-react-admin/internal/admin/biz/permission"
+	"github.com/sword-demon/go-react-admin/internal/admin/middleware"
+	"github.com/sword-demon/go-react-admin/internal/pkg/auth"
+)
+
+// mockPermissionBiz checks patterns against fixed rules with the real matcher
+// Only CheckPermission is implemented, other methods panic via the nil interface
+type mockPermissionBiz struct {
+	permission.IPermissionBiz
+	matcher  *permission.Matcher
+	patterns []string // Patterns of the last check
+}
+
+func (m *mockPermissionBiz) CheckPermission(ctx context.Context, userID uint64, patterns ...string) (bool, error) {
+	m.patterns = patterns
+	return m.matcher.Allowed(patterns...), nil
+}
+
+// newTestRouter registers declared user routes and an undeclared route behind Permission
+// withClaims simulates auth.Middleware setting the authenticated user
+func newTestRouter(biz permission.IPermissionBiz, withClaims bool) (*gin.Engine, *middleware.RouteRegistry) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	registry := middleware.NewRouteRegistry()
+
+	protected := engine.Group("/api/v1")
+	if withClaims {
+		protected.Use(func(c *gin.Context) {
+			c.Set(auth.ContextKeyClaims, &auth.Claims{UserID: 7, Username: "alice"})
+		})
+	}
+	protected.Use(middleware.Permission(biz, registry))
+
+	handler := func(c *gin.Context) { c.Status(http.StatusOK) }
+	users := registry.Group(protected.Group("/users"), "user")
+	users.GET("", "list", "List users", handler)
+	users.DELETE("/:id", "delete", "Delete user", handler)
+	protected.GET("/stats", handler) // undeclared
+
+	return engine, registry
+}
+
+// TestPermission tests declared permission and path patterns of a request
+func TestPermission(t *testing.T) {
+	tests := []struct {
+		name         string
+		rules        []string
+		method       string
+		path         string
+		wantStatus   int
+		wantPatterns []string
+	}{
+		{"declared permission allowed", []string{"user:list"}, http.MethodGet, "/api/v1/users", http.StatusOK,
+			[]string{"user:list:GET", "/api/v1/users:GET"}},
+		{"declared permission by alias", []string{"user:read"}, http.MethodGet, "/api/v1/users", http.StatusOK,
+			[]string{"user:list:GET", "/api/v1/users:GET"}},
+		{"declared permission denied", []string{"user:list"}, http.MethodDelete, "/api/v1/users/3", http.StatusForbidden,
+			[]string{"user:delete:DELETE", "/api/v1/users/3:DELETE"}},
+		{"declared permission granted by path", []string{"/api/v1/users/:id:DELETE"}, http.MethodDelete, "/api/v1/users/3", http.StatusOK,
+			[]string{"user:delete:DELETE", "/api/v1/users/3:DELETE"}},
+		{"undeclared route allowed by path", []string{"/api/v1/stats:GET"}, http.MethodGet, "/api/v1/stats", http.StatusOK,
+			[]string{"/api/v1/stats:GET"}},
+		{"undeclared route ignores module rules", []string{"stats:*"}, http.MethodGet, "/api/v1/stats", http.StatusForbidden,
+			[]string{"/api/v1/stats:GET"}},
+		{"path deny overrides declared grant", []string{"user:*", "!/api/v1/users/1:DELETE"}, http.MethodDelete, "/api/v1/users/1", http.StatusForbidden,
+			[]string{"user:delete:DELETE", "/api/v1/users/1:DELETE"}},
+		{"path deny covers only its path", []string{"user:*", "!/api/v1/users/1:DELETE"}, http.MethodDelete, "/api/v1/users/2", http.StatusOK,
+			[]string{"user:delete:DELETE", "/api/v1/users/2:DELETE"}},
+		{"declared deny overrides path grant", []string{"/api/v1/*", "!user:delete"}, http.MethodDelete, "/api/v1/users/2", http.StatusForbidden,
+			[]string{"user:delete:DELETE", "/api/v1/users/2:DELETE"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			biz := &mockPermissionBiz{matcher: permission.NewMatcher(tt.rules)}
+			engine, _ := newTestRouter(biz, true)
+
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("%s %s status = %d, want %d", tt.method, tt.path, w.Code, tt.wantStatus)
+			}
+			if !slices.Equal(biz.patterns, tt.wantPatterns) {
+				t.Errorf("%s %s patterns = %v, want %v", tt.method, tt.path, biz.patterns, tt.wantPatterns)
+			}
+		})
+	}
+}
+
+// TestPermissionWithoutClaims tests requests without authenticated user are rejected
+func TestPermissionWithoutClaims(t *testing.T) {
+	biz := &mockPermissionBiz{matcher: permission.NewMatcher([]string{"*:*"})}
+	engine, _ := newTestRouter(biz, false)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users", nil))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	if biz.patterns != nil {
+		t.Errorf("permission checked with %v, want no check", biz.patterns)
+	}
+}
+
+// TestRouteRegistryGroup tests routes declare metadata with full path at registration
+func TestRouteRegistryGroup(t *testing.T) {
+	biz := &mockPermissionBiz{matcher: permission.NewMatcher(nil)}
+	_, registry := newTestRouter(biz, true)
+
+	want := []middleware.RouteMeta{
+		{Method: http.MethodGet, Path: "/api/v1/users", Module: "user", Permission: "user:list", Description: "List users"},
+		{Method: http.MethodDelete, Path: "/api/v1/users/:id", Module: "user", Permission: "user:delete", Description: "Delete user"},
+	}
+	for _, meta := range want {
+		got, ok := registry.Lookup(meta.Method, meta.Path)
+		if !ok {
+			t.Errorf("Lookup(%s, %s) not found", meta.Method, meta.Path)
+			continue
+		}
+		if *got != meta {
+			t.Errorf("Lookup(%s, %s) = %+v, want %+v", meta.Method, meta.Path, *got, meta)
+		}
+	}
+
+	if _, ok := registry.Lookup(http.MethodGet, "/api/v1/stats"); ok {
+		t.Error("Lookup(GET, /api/v1/stats) found undeclared route")
+	}
+	if got := len(registry.All()); got != len(want) {
+		t.Errorf("All() returned %d routes, want %d", got, len(want))
+	}
+}
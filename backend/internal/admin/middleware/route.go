@@ -0,0 +1,143 @@
+// Package middleware provides application-specific Gin middleware
+package middleware
+
+import (
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RouteMeta describes permission metadata declared at route registration
+type RouteMeta struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`        // Full route path (e.g., /api/v1/users/:id)
+	Module      string `json:"module"`      // Module name (e.g., user)
+	Permission  string `json:"permission"`  // module:action form (e.g., user:create)
+	Description string `json:"description"` // API description
+}
+
+// RouteRegistry records route metadata keyed by method + full path
+// Used by permission middleware and API scanner
+type RouteRegistry struct {
+	mu     sync.RWMutex
+	routes map[string]*RouteMeta
+}
+
+// NewRouteRegistry creates a new route registry
+func NewRouteRegistry() *RouteRegistry {
+	return &RouteRegistry{
+		routes: make(map[string]*RouteMeta),
+	}
+}
+
+// Register records metadata for a route
+func (r *RouteRegistry) Register(meta *RouteMeta) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes[routeKey(meta.Method, meta.Path)] = meta
+}
+
+// Lookup returns metadata for a route (fullPath is gin.Context.FullPath())
+func (r *RouteRegistry) Lookup(method, fullPath string) (*RouteMeta, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	meta, ok := r.routes[routeKey(method, fullPath)]
+	return meta, ok
+}
+
+// All returns all registered route metadata sorted by path and method
+func (r *RouteRegistry) All() []*RouteMeta {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	metas := make([]*RouteMeta, 0, len(r.routes))
+	for _, meta := range r.routes {
+		metas = append(metas, meta)
+	}
+	sort.Slice(metas, func(i, j int) bool {
+		if metas[i].Path != metas[j].Path {
+			return metas[i].Path < metas[j].Path
+		}
+		return metas[i].Method < metas[j].Method
+	})
+	return metas
+}
+
+// Group wraps a gin router group so routes declare their permission at registration
+// Usage:
+//
+//	users := registry.Group(protected.Group("/users"), "user")
+//	users.GET("", "list", "List users", userController.List)       // user:list
+//	users.POST("", "create", "Create user", userController.Create) // user:create
+func (r *RouteRegistry) Group(group *gin.RouterGroup, module string) *Routes {
+	return &Routes{
+		group:    group,
+		registry: r,
+		module:   module,
+	}
+}
+
+// Routes registers routes of one module with permission metadata
+type Routes struct {
+	group    *gin.RouterGroup
+	registry *RouteRegistry
+	module   string
+}
+
+// Handle registers a route requiring permission module:action
+func (r *Routes) Handle(method, relativePath, action, description string, handlers ...gin.HandlerFunc) {
+	r.registry.Register(&RouteMeta{
+		Method:      method,
+		Path:        joinPaths(r.group.BasePath(), relativePath),
+		Module:      r.module,
+		Permission:  r.module + ":" + action,
+		Description: description,
+	})
+	r.group.Handle(method, relativePath, handlers...)
+}
+
+// GET registers a GET route requiring permission module:action
+func (r *Routes) GET(relativePath, action, description string, handlers ...gin.HandlerFunc) {
+	r.Handle(http.MethodGet, relativePath, action, description, handlers...)
+}
+
+// POST registers a POST route requiring permission module:action
+func (r *Routes) POST(relativePath, action, description string, handlers ...gin.HandlerFunc) {
+	r.Handle(http.MethodPost, relativePath, action, description, handlers...)
+}
+
+// PUT registers a PUT route requiring permission module:action
+func (r *Routes) PUT(relativePath, action, description string, handlers ...gin.HandlerFunc) {
+	r.Handle(http.MethodPut, relativePath, action, description, handlers...)
+}
+
+// PATCH registers a PATCH route requiring permission module:action
+func (r *Routes) PATCH(relativePath, action, description string, handlers ...gin.HandlerFunc) {
+	r.Handle(http.MethodPatch, relativePath, action, description, handlers...)
+}
+
+// DELETE registers a DELETE route requiring permission module:action
+func (r *Routes) DELETE(relativePath, action, description string, handlers ...gin.HandlerFunc) {
+	r.Handle(http.MethodDelete, relativePath, action, description, handlers...)
+}
+
+// routeKey builds registry key
+func routeKey(method, fullPath string) string {
+	return method + " " + fullPath
+}
+
+// joinPaths joins group base path and relative path the same way gin does
+func joinPaths(basePath, relativePath string) string {
+	if relativePath == "" {
+		return basePath
+	}
+	finalPath := path.Join(basePath, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(finalPath, "/") {
+		return finalPath + "/"
+	}
+	return finalPath
+}
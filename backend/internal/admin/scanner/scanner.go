@@ -0,0 +1,153 @@
+// Package scanner syncs the Gin route table into sys_api_doc
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sword-demon/go-react-admin/internal/admin/middleware"
+	"github.com/sword-demon/go-react-admin/internal/admin/store"
+	"github.com/sword-demon/go-react-admin/internal/pkg/model"
+)
+
+// defaultPathPrefix limits scanning to API routes (skip /ping, static files, etc.)
+const defaultPathPrefix = "/api/"
+
+// APIScanner walks gin.Engine.Routes() and syncs them into sys_api_doc
+// Module and permission come from route metadata declared at registration
+type APIScanner struct {
+	engine     *gin.Engine
+	registry   *middleware.RouteRegistry
+	store      store.IStore
+	pathPrefix string
+}
+
+// SyncResult represents API doc sync statistics
+type SyncResult struct {
+	Total int `json:"total"` // Routes found in route table
+	Stale int `json:"stale"` // Routes marked as stale (disappeared)
+}
+
+// NewAPIScanner creates a new API scanner
+// Call after all routes are registered
+func NewAPIScanner(engine *gin.Engine, registry *middleware.RouteRegistry, store store.IStore) *APIScanner {
+	return &APIScanner{
+		engine:     engine,
+		registry:   registry,
+		store:      store,
+		pathPrefix: defaultPathPrefix,
+	}
+}
+
+// Scan builds API docs from current route table
+func (s *APIScanner) Scan() []*model.APIDoc {
+	routes := s.engine.Routes()
+	docs := make([]*model.APIDoc, 0, len(routes))
+
+	for _, route := range routes {
+		if !strings.HasPrefix(route.Path, s.pathPrefix) {
+			continue
+		}
+
+		group := routeGroup(route.Path)
+		doc := &model.APIDoc{
+			APIPath:   route.Path,
+			APIMethod: route.Method,
+			APIModule: group,
+			APIGroup:  group,
+			Status:    model.APIDocStatusActive,
+		}
+
+		// Declared metadata overrides path-derived defaults
+		if meta, ok := s.registry.Lookup(route.Method, route.Path); ok {
+			if meta.Module != "" {
+				doc.APIModule = meta.Module
+			}
+			doc.Permission = meta.Permission
+			doc.Description = meta.Description
+		}
+
+		docs = append(docs, doc)
+	}
+
+	return docs
+}
+
+// ScanAndSyncToDB upserts all routes and marks disappeared routes as stale
+func (s *APIScanner) ScanAndSyncToDB(ctx context.Context) (*SyncResult, error) {
+	docs := s.Scan()
+	result := &SyncResult{Total: len(docs)}
+
+	err := s.store.Transaction(ctx, func(txStore store.IStore) error {
+		// 1. Load existing docs (before upsert) to find disappeared routes
+		existing, err := txStore.APIDocs().ListAll(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list api docs: %w", err)
+		}
+
+		// 2. Upsert current routes (also revives previously stale routes)
+		if err := txStore.APIDocs().Upsert(ctx, docs); err != nil {
+			return fmt.Errorf("failed to upsert api docs: %w", err)
+		}
+
+		// 3. Mark routes that are no longer registered as stale
+		current := make(map[string]struct{}, len(docs))
+		for _, doc := range docs {
+			current[doc.RouteKey()] = struct{}{}
+		}
+
+		staleIDs := make([]uint64, 0)
+		for _, doc := range existing {
+			if _, ok := current[doc.RouteKey()]; !ok && !doc.IsStale() {
+				staleIDs = append(staleIDs, doc.ID)
+			}
+		}
+		if err := txStore.APIDocs().MarkStale(ctx, staleIDs); err != nil {
+			return fmt.Errorf("failed to mark stale api docs: %w", err)
+		}
+		result.Stale = len(staleIDs)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ API docs synced: %d routes, %d stale", result.Total, result.Stale)
+	return result, nil
+}
+
+// routeGroup derives group from path: first segment after /api and version
+// e.g., /api/v1/users/:id → users, /api/auth/login → auth
+func routeGroup(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if i == 0 && segment == "api" {
+			continue
+		}
+		if isVersionSegment(segment) {
+			continue
+		}
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			break
+		}
+		return segment
+	}
+	return "common"
+}
+
+// isVersionSegment checks if segment is an API version (v1, v2, ...)
+func isVersionSegment(segment string) bool {
+	if len(segment) < 2 || segment[0] != 'v' {
+		return false
+	}
+	for _, r := range segment[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,177 @@
+package scanner_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	gormsqlite "github.com/glebarez/sqlite"
+	"github.com/sword-demon/go-react-admin/internal/admin/middleware"
+	"github.com/sword-demon/go-react-admin/internal/admin/scanner"
+	"github.com/sword-demon/go-react-admin/internal/admin/store"
+	"github.com/sword-demon/go-react-admin/internal/pkg/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB opens an in-memory SQLite database with sys_api_doc
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	database, err := gorm.Open(gormsqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	sqlDB, err := database.DB()
+	if err != nil {
+		t.Fatalf("failed to get sql.DB: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1) // every connection has its own in-memory database
+	if err := database.AutoMigrate(&model.APIDoc{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return database
+}
+
+// newTestEngine registers a small route table; withDelete adds DELETE /api/v1/users/:id
+func newTestEngine(withDelete bool) (*gin.Engine, *middleware.RouteRegistry) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	registry := middleware.NewRouteRegistry()
+	handler := func(c *gin.Context) {}
+
+	engine.GET("/ping", handler)            // outside /api/, not scanned
+	engine.POST("/api/auth/login", handler) // undeclared
+
+	users := registry.Group(engine.Group("/api/v1/users"), "user")
+	users.GET("", "list", "List users", handler)
+	users.POST("", "create", "Create user", handler)
+	if withDelete {
+		users.DELETE("/:id", "delete", "Delete user", handler)
+	}
+
+	return engine, registry
+}
+
+// listDocs returns all API docs keyed by route
+func listDocs(t *testing.T, s store.IStore) map[string]*model.APIDoc {
+	t.Helper()
+	docs, err := s.APIDocs().ListAll(context.Background())
+	if err != nil {
+		t.Fatalf("failed to list api docs: %v", err)
+	}
+	byRoute := make(map[string]*model.APIDoc, len(docs))
+	for _, doc := range docs {
+		byRoute[doc.RouteKey()] = doc
+	}
+	return byRoute
+}
+
+func TestScanAndSyncToDB(t *testing.T) {
+	s := store.NewStore(newTestDB(t))
+
+	engine, registry := newTestEngine(true)
+	result, err := scanner.NewAPIScanner(engine, registry, s).ScanAndSyncToDB(context.Background())
+	if err != nil {
+		t.Fatalf("ScanAndSyncToDB() error = %v", err)
+	}
+	if result.Total != 4 || result.Stale != 0 {
+		t.Errorf("ScanAndSyncToDB() = %+v, want Total=4 Stale=0", result)
+	}
+
+	docs := listDocs(t, s)
+	if len(docs) != 4 {
+		t.Fatalf("got %d api docs, want 4", len(docs))
+	}
+	if _, ok := docs["GET /ping"]; ok {
+		t.Error("GET /ping synced, want only /api/ routes")
+	}
+
+	tests := []struct {
+		route       string
+		module      string
+		group       string
+		permission  string
+		description string
+	}{
+		{"GET /api/v1/users", "user", "users", "user:list", "List users"},
+		{"POST /api/v1/users", "user", "users", "user:create", "Create user"},
+		{"DELETE /api/v1/users/:id", "user", "users", "user:delete", "Delete user"},
+		// Undeclared routes fall back to path-derived module
+		{"POST /api/auth/login", "auth", "auth", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.route, func(t *testing.T) {
+			doc, ok := docs[tt.route]
+			if !ok {
+				t.Fatalf("%s not synced", tt.route)
+			}
+			if doc.APIModule != tt.module || doc.APIGroup != tt.group {
+				t.Errorf("module/group = %s/%s, want %s/%s", doc.APIModule, doc.APIGroup, tt.module, tt.group)
+			}
+			if doc.Permission != tt.permission || doc.Description != tt.description {
+				t.Errorf("permission/description = %q/%q, want %q/%q", doc.Permission, doc.Description, tt.permission, tt.description)
+			}
+			if doc.IsStale() {
+				t.Error("doc is stale, want active")
+			}
+		})
+	}
+}
+
+func TestScanAndSyncToDBStale(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewStore(newTestDB(t))
+
+	sync := func(withDelete bool) *scanner.SyncResult {
+		t.Helper()
+		engine, registry := newTestEngine(withDelete)
+		result, err := scanner.NewAPIScanner(engine, registry, s).ScanAndSyncToDB(ctx)
+		if err != nil {
+			t.Fatalf("ScanAndSyncToDB() error = %v", err)
+		}
+		return result
+	}
+
+	sync(true)
+
+	// DELETE route removed: marked stale, not deleted
+	if result := sync(false); result.Total != 3 || result.Stale != 1 {
+		t.Errorf("after removal = %+v, want Total=3 Stale=1", result)
+	}
+	docs := listDocs(t, s)
+	if len(docs) != 4 {
+		t.Fatalf("got %d api docs, want 4", len(docs))
+	}
+	deleted, ok := docs["DELETE /api/v1/users/:id"]
+	if !ok {
+		t.Fatal("DELETE /api/v1/users/:id removed, want kept as stale")
+	}
+	if !deleted.IsStale() {
+		t.Error("DELETE /api/v1/users/:id active, want stale")
+	}
+	for route, doc := range docs {
+		if doc != deleted && doc.IsStale() {
+			t.Errorf("%s stale, want active", route)
+		}
+	}
+
+	// Already stale routes are not counted again
+	if result := sync(false); result.Stale != 0 {
+		t.Errorf("repeated sync Stale = %d, want 0", result.Stale)
+	}
+
+	// Route registered again: revived in place
+	if result := sync(true); result.Total != 4 || result.Stale != 0 {
+		t.Errorf("after re-adding = %+v, want Total=4 Stale=0", result)
+	}
+	revived, ok := listDocs(t, s)["DELETE /api/v1/users/:id"]
+	if !ok {
+		t.Fatal("DELETE /api/v1/users/:id not synced")
+	}
+	if revived.IsStale() {
+		t.Error("DELETE /api/v1/users/:id stale, want revived")
+	}
+	if revived.ID != deleted.ID {
+		t.Errorf("revived ID = %d, want %d", revived.ID, deleted.ID)
+	}
+}
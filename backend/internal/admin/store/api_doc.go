@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+
+	"github.com/sword-demon/go-react-admin/internal/pkg/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// apiDocStore implements IAPIDocStore interface
+type apiDocStore struct {
+	db *gorm.DB
+}
+
+// newAPIDocStore creates a new API doc store
+func newAPIDocStore(db *gorm.DB) IAPIDocStore {
+	return &apiDocStore{db: db}
+}
+
+// Upsert creates or updates API docs by (api_path, api_method)
+func (s *apiDocStore) Upsert(ctx context.Context, docs []*model.APIDoc) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	return s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "api_path"}, {Name: "api_method"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"api_module", "api_group", "api_permission", "description", "status", "update_time",
+			}),
+		}).
+		CreateInBatches(docs, 100).Error
+}
+
+// MarkStale marks API docs as stale (route no longer registered)
+func (s *apiDocStore) MarkStale(ctx context.Context, ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return s.db.WithContext(ctx).
+		Model(&model.APIDoc{}).
+		Where("id IN ?", ids).
+		Update("status", model.APIDocStatusStale).Error
+}
+
+// ListAll retrieves all API docs including stale ones (for scanner diff)
+func (s *apiDocStore) ListAll(ctx context.Context) ([]*model.APIDoc, error) {
+	var docs []*model.APIDoc
+	err := s.db.WithContext(ctx).
+		Order("api_path ASC, api_method ASC").
+		Find(&docs).Error
+	if err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// List retrieves API docs with pagination and filters
+func (s *apiDocStore) List(ctx context.Context, opts *ListOptions) ([]*model.APIDoc, int64, error) {
+	var docs []*model.APIDoc
+	var total int64
+
+	// Build base query
+	query := s.db.WithContext(ctx).Model(&model.APIDoc{})
+
+	// Apply filters
+	if module, ok := opts.Filters["api_module"].(string); ok && module != "" {
+		query = query.Where("api_module = ?", module)
+	}
+	if path, ok := opts.Filters["api_path"].(string); ok && path != "" {
+		query = query.Where("api_path LIKE ?", "%"+path+"%")
+	}
+	if status, ok := opts.Filters["status"].(int); ok {
+		query = query.Where("status = ?", status)
+	}
+
+	// Count total
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Apply pagination
+	offset := (opts.Page - 1) * opts.PageSize
+	query = query.Offset(offset).Limit(opts.PageSize)
+
+	// Apply ordering
+	if opts.OrderBy != "" {
+		query = query.Order(opts.OrderBy)
+	} else {
+		query = query.Order("api_module ASC, api_path ASC, api_method ASC")
+	}
+
+	// Execute query
+	if err := query.Find(&docs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return docs, total, nil
+}
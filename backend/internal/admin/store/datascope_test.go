@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sword-demon/go-react-admin/internal/pkg/datascope"
+	"github.com/sword-demon/go-react-admin/internal/pkg/model"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// TestListDataScope tests dept lists are restricted to data scope,
+// role and menu lists (system configuration) are not
+func TestListDataScope(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "user:pass@tcp(localhost:3306)/test", SkipInitializeWithVersion: true}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("failed to open dry run db: %v", err)
+	}
+	var sqls []string
+	if err := db.Callback().Query().After("gorm:query").Register("test:record_sql", func(tx *gorm.DB) {
+		sqls = append(sqls, tx.Statement.SQL.String())
+	}); err != nil {
+		t.Fatalf("failed to register callback: %v", err)
+	}
+	roles, depts, menus := newRoleStore(db), newDeptStore(db), newMenuStore(db)
+	list := map[string]func(ctx context.Context) error{
+		"roles": func(ctx context.Context) error {
+			_, _, err := roles.List(ctx, &ListOptions{Page: 1, PageSize: 10})
+			return err
+		},
+		"depts": func(ctx context.Context) error { _, err := depts.List(ctx); return err },
+		"menus": func(ctx context.Context) error { _, err := menus.List(ctx); return err },
+	}
+
+	tests := []struct {
+		name  string
+		list  string
+		scope *datascope.Scope // nil = no scope in context
+		want  string           // in every query, empty = no data scope condition
+	}{
+		{"roles without scope", "roles", nil, ""},
+		{"roles all", "roles", &datascope.Scope{All: true}, ""},
+		{"roles self", "roles", &datascope.Scope{UserID: 7}, ""},
+		{"roles dept only", "roles", &datascope.Scope{DeptIDs: []uint64{5}}, ""},
+		{"depts without scope", "depts", nil, ""},
+		{"depts", "depts", &datascope.Scope{DeptIDs: []uint64{5, 6}}, "WHERE status = ? AND id IN (?,?) AND"},
+		{"depts or self", "depts", &datascope.Scope{DeptIDs: []uint64{5}, UserID: 7}, "WHERE status = ? AND (id IN (?) OR created_by = ?) AND"},
+		{"menus without scope", "menus", nil, ""},
+		{"menus self", "menus", &datascope.Scope{UserID: 7}, ""},
+		{"menus dept only", "menus", &datascope.Scope{DeptIDs: []uint64{5}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.scope != nil {
+				ctx = datascope.WithScope(ctx, tt.scope)
+			}
+			sqls = nil
+			if err := list[tt.list](ctx); err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+			if len(sqls) == 0 {
+				t.Fatal("no query recorded")
+			}
+			for _, sql := range sqls {
+				if tt.want == "" {
+					if strings.Contains(sql, "created_by") || strings.Contains(sql, "1 = 0") {
+						t.Errorf("SQL = %s, want no data scope condition", sql)
+					}
+				} else if !strings.Contains(sql, tt.want) {
+					t.Errorf("SQL = %s, want to contain %s", sql, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// TestDeptBuildTreeScoped tests departments whose parent is out of data scope become roots
+func TestDeptBuildTreeScoped(t *testing.T) {
+	depts := []*model.Dept{
+		{BaseModel: model.BaseModel{ID: 5}, ParentID: 1},
+		{BaseModel: model.BaseModel{ID: 6}, ParentID: 5},
+		{BaseModel: model.BaseModel{ID: 9}, ParentID: 2},
+	}
+	roots := (&deptStore{}).BuildTree(depts)
+	if len(roots) != 2 || roots[0].ID != 5 || roots[1].ID != 9 {
+		t.Fatalf("roots = %v, want depts 5 and 9", roots)
+	}
+	if len(roots[0].Children) != 1 || roots[0].Children[0].ID != 6 {
+		t.Errorf("children of 5 = %v, want dept 6", roots[0].Children)
+	}
+}
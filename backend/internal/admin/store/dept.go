@@ -2,13 +2,19 @@ package store
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
+	"slices"
 
+	"github.com/sword-demon/go-react-admin/internal/pkg/datascope"
 	"github.com/sword-demon/go-react-admin/internal/pkg/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrDeptMoveCycle is returned by Move when the new parent is the department itself or one of its descendants
+var ErrDeptMoveCycle = errors.New("cannot move department under itself or its descendants")
+
 // deptStore implements IDeptStore interface
 type deptStore struct {
 	db *gorm.DB
@@ -22,22 +28,30 @@ func newDeptStore(db *gorm.DB) IDeptStore {
 // Create creates a new department
 func (s *deptStore) Create(ctx context.Context, dept *model.Dept) error {
 	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// Build ancestors chain
+		// Build ancestors chain from the share-locked parent
+		// Move locks every department of the moved subtree and its closure rows, so a concurrent move
+		// of the parent or any of its ancestors either finishes before this read or waits for the insert
 		if dept.ParentID > 0 {
 			var parent model.Dept
-			if err := tx.First(&parent, dept.ParentID).Error; err != nil {
+			if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).First(&parent, dept.ParentID).Error; err != nil {
 				return fmt.Errorf("parent dept not found: %w", err)
 			}
-			if parent.Ancestors == "" {
-				dept.Ancestors = fmt.Sprintf("0,%d", parent.ID)
-			} else {
-				dept.Ancestors = fmt.Sprintf("%s,%d", parent.Ancestors, parent.ID)
-			}
+			dept.Ancestors = childAncestors(&parent)
 		} else {
 			dept.Ancestors = "0"
 		}
 
-		return tx.Create(dept).Error
+		if err := tx.Create(dept).Error; err != nil {
+			return err
+		}
+
+		// Closure rows: self, plus every ancestor of parent one level deeper
+		return tx.Exec(
+			"INSERT INTO sys_dept_closure (ancestor, descendant, depth) "+
+				"SELECT ?, ?, 0 UNION ALL "+
+				"SELECT ancestor, ?, depth + 1 FROM sys_dept_closure WHERE descendant = ?",
+			dept.ID, dept.ID, dept.ID, dept.ParentID,
+		).Error
 	})
 }
 
@@ -46,6 +60,105 @@ func (s *deptStore) Update(ctx context.Context, dept *model.Dept) error {
 	return s.db.WithContext(ctx).Model(dept).Updates(dept).Error
 }
 
+// Move re-parents a department, rewriting closure rows and ancestors of the whole subtree
+// Department and new parent rows are locked, so concurrent moves cannot create a cycle;
+// subtree closure rows and departments are locked, so Create cannot copy a stale ancestor chain
+func (s *deptStore) Move(ctx context.Context, id, parentID uint64) error {
+	if id == parentID {
+		return ErrDeptMoveCycle
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock both rows in ID order (avoids deadlocks between opposite moves)
+		var rows []*model.Dept
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", []uint64{id, parentID}).
+			Order("id ASC").
+			Find(&rows).Error; err != nil {
+			return err
+		}
+		var dept, parent *model.Dept
+		for _, row := range rows {
+			if row.ID == id {
+				dept = row
+			} else {
+				parent = row
+			}
+		}
+		if dept == nil {
+			return gorm.ErrRecordNotFound
+		}
+		if dept.ParentID == parentID {
+			return nil
+		}
+
+		// Subtree of the department (itself included), rejecting moves under it
+		// Locking the closure rows blocks new descendants until commit
+		var subtree []uint64
+		if err := tx.Model(&model.DeptClosure{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("ancestor = ?", id).
+			Order("descendant ASC").
+			Pluck("descendant", &subtree).Error; err != nil {
+			return err
+		}
+		if slices.Contains(subtree, parentID) {
+			return ErrDeptMoveCycle
+		}
+
+		// Lock subtree departments: Create share-locks the parent, so no child is created
+		// under a moved department from its old ancestors
+		var locked []uint64
+		if err := tx.Model(&model.Dept{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", subtree).
+			Order("id ASC").
+			Pluck("id", &locked).Error; err != nil {
+			return err
+		}
+
+		ancestors := "0"
+		if parentID > 0 {
+			if parent == nil {
+				return fmt.Errorf("parent dept not found: %w", gorm.ErrRecordNotFound)
+			}
+			ancestors = childAncestors(parent)
+		}
+
+		// Detach subtree from old ancestors, attach to new parent's ancestors
+		if err := tx.Where("descendant IN ? AND ancestor NOT IN ?", subtree, subtree).
+			Delete(&model.DeptClosure{}).Error; err != nil {
+			return err
+		}
+		if parentID > 0 {
+			if err := tx.Exec(
+				"INSERT INTO sys_dept_closure (ancestor, descendant, depth) "+
+					"SELECT p.ancestor, c.descendant, p.depth + c.depth + 1 "+
+					"FROM sys_dept_closure p JOIN sys_dept_closure c ON c.ancestor = ? "+
+					"WHERE p.descendant = ?",
+				id, parentID,
+			).Error; err != nil {
+				return err
+			}
+		}
+
+		// Path prefix of descendants, before Updates writes the new ancestors back to dept
+		oldPrefix := childAncestors(dept)
+		if err := tx.Model(dept).Updates(map[string]interface{}{
+			"parent_id": parentID,
+			"ancestors": ancestors,
+		}).Error; err != nil {
+			return err
+		}
+
+		// Rewrite ancestors of descendants: replace old path prefix with the new one
+		newPrefix := fmt.Sprintf("%s,%d", ancestors, id)
+		return tx.Model(&model.Dept{}).
+			Where("id IN ? AND id <> ?", subtree, id).
+			Update("ancestors", gorm.Expr("CONCAT(?, SUBSTRING(ancestors, ?))", newPrefix, len(oldPrefix)+1)).Error
+	})
+}
+
 // Delete soft deletes a department
 func (s *deptStore) Delete(ctx context.Context, id uint64) error {
 	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
@@ -66,7 +179,11 @@ func (s *deptStore) Delete(ctx context.Context, id uint64) error {
 			return fmt.Errorf("cannot delete department with users")
 		}
 
-		return tx.Delete(&model.Dept{}, id).Error
+		if err := tx.Delete(&model.Dept{}, id).Error; err != nil {
+			return err
+		}
+		// Leaf department: only its own ancestor rows remain
+		return tx.Where("descendant = ?", id).Delete(&model.DeptClosure{}).Error
 	})
 }
 
@@ -82,10 +199,11 @@ func (s *deptStore) Get(ctx context.Context, id uint64) (*model.Dept, error) {
 	return &dept, nil
 }
 
-// List retrieves all departments (for building tree)
+// List retrieves all departments in data scope of current user (for building tree)
 func (s *deptStore) List(ctx context.Context) ([]*model.Dept, error) {
 	var depts []*model.Dept
 	err := s.db.WithContext(ctx).
+		Scopes(datascope.Filter(ctx, datascope.Columns{Dept: "id", Creator: "created_by"})).
 		Where("status = ?", model.StatusEnabled).
 		Order("order_num ASC").
 		Find(&depts).Error
@@ -106,21 +224,13 @@ func (s *deptStore) GetChildren(ctx context.Context, parentID uint64) ([]*model.
 		// Get all root departments
 		query = query.Where("parent_id = ?", 0)
 	} else {
-		// Get all descendants using ancestors field
+		// Get all descendants using closure table
 		var parent model.Dept
 		if err := s.db.WithContext(ctx).First(&parent, parentID).Error; err != nil {
 			return nil, err
 		}
 
-		// Find all children where ancestors contains parent ID
-		ancestorsPattern := fmt.Sprintf("%%,%d,%%", parentID)
-		query = query.Where(
-			"parent_id = ? OR ancestors LIKE ? OR ancestors LIKE ? OR ancestors LIKE ?",
-			parentID,
-			ancestorsPattern,
-			fmt.Sprintf("%d,%%", parentID),
-			fmt.Sprintf("%%,%d", parentID),
-		)
+		query = query.Where("id IN (?)", s.descendants(ctx, parentID).Where("depth > 0"))
 	}
 
 	err := query.
@@ -146,28 +256,26 @@ func (s *deptStore) GetDeptIDs(ctx context.Context, deptID uint64, includeChildr
 		return nil, err
 	}
 
-	// Find all children departments
-	var children []*model.Dept
-	ancestorsPattern := fmt.Sprintf("%%,%d%%", deptID)
+	// Department itself and all descendants (closure table, soft deleted excluded)
+	var ids []uint64
 	err := s.db.WithContext(ctx).
 		Model(&model.Dept{}).
-		Where("id = ? OR ancestors LIKE ?", deptID, ancestorsPattern).
-		Select("id").
-		Find(&children).Error
-
+		Where("id IN (?)", s.descendants(ctx, deptID)).
+		Pluck("id", &ids).Error
 	if err != nil {
 		return nil, err
 	}
-
-	// Extract IDs
-	ids := make([]uint64, 0, len(children))
-	for _, child := range children {
-		ids = append(ids, child.ID)
-	}
-
 	return ids, nil
 }
 
+// descendants returns a subquery of descendant IDs of a department (itself included, depth 0)
+func (s *deptStore) descendants(ctx context.Context, deptID uint64) *gorm.DB {
+	return s.db.WithContext(ctx).
+		Model(&model.DeptClosure{}).
+		Select("descendant").
+		Where("ancestor = ?", deptID)
+}
+
 // BuildTree builds department tree structure
 func (s *deptStore) BuildTree(depts []*model.Dept) []*model.Dept {
 	// Create map for quick lookup
@@ -179,34 +287,29 @@ func (s *deptStore) BuildTree(depts []*model.Dept) []*model.Dept {
 	// Build tree
 	var roots []*model.Dept
 	for _, dept := range depts {
-		if dept.ParentID == 0 {
-			roots = append(roots, dept)
-		} else if parent, exists := deptMap[dept.ParentID]; exists {
+		// Departments whose parent is not listed (out of data scope) are roots
+		if parent, exists := deptMap[dept.ParentID]; dept.ParentID != 0 && exists {
 			if parent.Children == nil {
 				parent.Children = []*model.Dept{}
 			}
 			parent.Children = append(parent.Children, dept)
+		} else {
+			roots = append(roots, dept)
 		}
 	}
 
 	return roots
 }
 
-// GetAncestorIDs parses ancestors string to ID slice
-func GetAncestorIDs(ancestors string) []uint64 {
-	if ancestors == "" || ancestors == "0" {
-		return []uint64{}
-	}
-
-	parts := strings.Split(ancestors, ",")
-	ids := make([]uint64, 0, len(parts))
-
-	for _, part := range parts {
-		var id uint64
-		if _, err := fmt.Sscanf(part, "%d", &id); err == nil && id > 0 {
-			ids = append(ids, id)
-		}
+// childAncestors returns ancestors chain of a child of parent
+func childAncestors(parent *model.Dept) string {
+	if parent.Ancestors == "" {
+		return fmt.Sprintf("0,%d", parent.ID)
 	}
+	return fmt.Sprintf("%s,%d", parent.Ancestors, parent.ID)
+}
 
-	return ids
+// GetAncestorIDs parses ancestors string to ID slice
+func GetAncestorIDs(ancestors string) []uint64 {
+	return model.ParseAncestors(ancestors)
 }
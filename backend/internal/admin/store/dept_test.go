@@ -0,0 +1,307 @@
+package store
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/go-sqlite"
+	gormsqlite "github.com/glebarez/sqlite"
+	"github.com/sword-demon/go-react-admin/internal/pkg/db"
+	"github.com/sword-demon/go-react-admin/internal/pkg/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func init() {
+	// MySQL CONCAT used by Move (built into SQLite since 3.44)
+	sqlite.MustRegisterDeterministicScalarFunction("concat", -1, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		var b strings.Builder
+		for _, arg := range args {
+			if arg == nil {
+				return nil, nil
+			}
+			fmt.Fprint(&b, arg)
+		}
+		return b.String(), nil
+	})
+}
+
+// newTestDB opens an in-memory SQLite database with department tables
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	database, err := gorm.Open(gormsqlite.Open(":memory:"), &gorm.Config{
+		Logger:                                   logger.Discard,
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	sqlDB, err := database.DB()
+	if err != nil {
+		t.Fatalf("failed to get sql.DB: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1) // every connection has its own in-memory database
+	if err := database.AutoMigrate(&model.Dept{}, &model.DeptClosure{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return database
+}
+
+// seedDepts creates the test department tree through Create (closure rows included)
+//
+//	1
+//	├── 2
+//	│   └── 5
+//	│       └── 6
+//	│           └── 7
+//	└── 3
+//	    └── 51
+//	        └── 500
+func seedDepts(t *testing.T, depts IDeptStore) {
+	t.Helper()
+	for _, dept := range [][2]uint64{{1, 0}, {2, 1}, {3, 1}, {5, 2}, {6, 5}, {7, 6}, {51, 3}, {500, 51}} {
+		if err := depts.Create(context.Background(), &model.Dept{
+			BaseModel: model.BaseModel{ID: dept[0]},
+			ParentID:  dept[1],
+			DeptName:  fmt.Sprintf("dept %d", dept[0]),
+			Status:    model.StatusEnabled,
+		}); err != nil {
+			t.Fatalf("failed to create dept %d: %v", dept[0], err)
+		}
+	}
+}
+
+// deptAncestors returns ancestors of all departments by ID
+func deptAncestors(t *testing.T, database *gorm.DB) map[uint64]string {
+	t.Helper()
+	var depts []*model.Dept
+	if err := database.Find(&depts).Error; err != nil {
+		t.Fatalf("failed to list depts: %v", err)
+	}
+	ancestors := make(map[uint64]string, len(depts))
+	for _, dept := range depts {
+		ancestors[dept.ID] = dept.Ancestors
+	}
+	return ancestors
+}
+
+// closureRows returns closure rows as sorted "ancestor>descendant:depth" strings
+func closureRows(t *testing.T, database *gorm.DB, descendants ...uint64) []string {
+	t.Helper()
+	var rows []*model.DeptClosure
+	query := database.Model(&model.DeptClosure{})
+	if len(descendants) > 0 {
+		query = query.Where("descendant IN ?", descendants)
+	}
+	if err := query.Find(&rows).Error; err != nil {
+		t.Fatalf("failed to list closure rows: %v", err)
+	}
+	return formatClosure(rows)
+}
+
+// formatClosure formats closure rows as sorted "ancestor>descendant:depth" strings
+func formatClosure(rows []*model.DeptClosure) []string {
+	formatted := make([]string, 0, len(rows))
+	for _, row := range rows {
+		formatted = append(formatted, fmt.Sprintf("%d>%d:%d", row.Ancestor, row.Descendant, row.Depth))
+	}
+	slices.Sort(formatted)
+	return formatted
+}
+
+// assertClosureConsistent checks the closure table matches ancestors of all departments
+func assertClosureConsistent(t *testing.T, database *gorm.DB) {
+	t.Helper()
+	var depts []*model.Dept
+	if err := database.Find(&depts).Error; err != nil {
+		t.Fatalf("failed to list depts: %v", err)
+	}
+	if got, want := closureRows(t, database), formatClosure(db.DeptClosureRows(depts)); !slices.Equal(got, want) {
+		t.Errorf("closure = %v, want (from ancestors) %v", got, want)
+	}
+}
+
+// TestDeptSubtreeQueries tests subtree queries use the closure table, not LIKE on ancestors
+// (ancestors LIKE '%,5%' also matched departments 51 and 500)
+func TestDeptSubtreeQueries(t *testing.T) {
+	database := newTestDB(t)
+	depts := newDeptStore(database)
+	seedDepts(t, depts)
+	var sqls []string
+	if err := database.Callback().Query().After("gorm:query").Register("test:record_sql", func(tx *gorm.DB) {
+		sqls = append(sqls, tx.Statement.SQL.String())
+	}); err != nil {
+		t.Fatalf("failed to register callback: %v", err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		run  func() ([]uint64, error)
+		want []uint64
+	}{
+		{"dept ids", func() ([]uint64, error) { return depts.GetDeptIDs(ctx, 5, true) }, []uint64{5, 6, 7}},
+		{"children", func() ([]uint64, error) {
+			children, err := depts.GetChildren(ctx, 5)
+			ids := make([]uint64, 0, len(children))
+			for _, child := range children {
+				ids = append(ids, child.ID)
+			}
+			return ids, err
+		}, []uint64{6, 7}},
+		{"dept ids of 51", func() ([]uint64, error) { return depts.GetDeptIDs(ctx, 51, true) }, []uint64{51, 500}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqls = nil
+			ids, err := tt.run()
+			if err != nil {
+				t.Fatalf("query failed: %v", err)
+			}
+			slices.Sort(ids)
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("IDs = %v, want %v", ids, tt.want)
+			}
+			for _, sql := range sqls {
+				if strings.Contains(sql, "LIKE") {
+					t.Errorf("SQL = %s, want no LIKE", sql)
+				}
+			}
+		})
+	}
+}
+
+// TestDeptCreate tests Create maintains ancestors and closure rows
+func TestDeptCreate(t *testing.T) {
+	database := newTestDB(t)
+	depts := newDeptStore(database)
+	seedDepts(t, depts)
+
+	if got := deptAncestors(t, database)[500]; got != "0,1,3,51" {
+		t.Errorf("ancestors of 500 = %q, want 0,1,3,51", got)
+	}
+	want := []string{"1>500:3", "3>500:2", "500>500:0", "51>500:1"}
+	if got := closureRows(t, database, 500); !slices.Equal(got, want) {
+		t.Errorf("closure of 500 = %v, want %v", got, want)
+	}
+	assertClosureConsistent(t, database)
+
+	// Missing parent: nothing created
+	err := depts.Create(context.Background(), &model.Dept{ParentID: 404, DeptName: "orphan"})
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Create under missing parent error = %v, want ErrRecordNotFound", err)
+	}
+	assertClosureConsistent(t, database)
+}
+
+// TestDeptMove tests Move rewrites ancestors and closure rows of the whole subtree
+func TestDeptMove(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("subtree to root", func(t *testing.T) {
+		database := newTestDB(t)
+		depts := newDeptStore(database)
+		seedDepts(t, depts)
+
+		if err := depts.Move(ctx, 5, 0); err != nil {
+			t.Fatalf("Move failed: %v", err)
+		}
+		ancestors := deptAncestors(t, database)
+		for id, want := range map[uint64]string{5: "0", 6: "0,5", 7: "0,5,6", 2: "0,1", 500: "0,1,3,51"} {
+			if ancestors[id] != want {
+				t.Errorf("ancestors of %d = %q, want %q", id, ancestors[id], want)
+			}
+		}
+		want := []string{"5>5:0", "5>6:1", "5>7:2", "6>6:0", "6>7:1", "7>7:0"}
+		if got := closureRows(t, database, 5, 6, 7); !slices.Equal(got, want) {
+			t.Errorf("closure of subtree = %v, want %v", got, want)
+		}
+		if ids, _ := depts.GetDeptIDs(ctx, 1, true); slices.Contains(ids, 5) || slices.Contains(ids, 7) {
+			t.Errorf("GetDeptIDs(1) = %v, want without moved subtree", ids)
+		}
+		assertClosureConsistent(t, database)
+	})
+
+	t.Run("subtree to sibling branch", func(t *testing.T) {
+		database := newTestDB(t)
+		depts := newDeptStore(database)
+		seedDepts(t, depts)
+
+		if err := depts.Move(ctx, 5, 51); err != nil {
+			t.Fatalf("Move failed: %v", err)
+		}
+		ancestors := deptAncestors(t, database)
+		for id, want := range map[uint64]string{5: "0,1,3,51", 6: "0,1,3,51,5", 7: "0,1,3,51,5,6"} {
+			if ancestors[id] != want {
+				t.Errorf("ancestors of %d = %q, want %q", id, ancestors[id], want)
+			}
+		}
+		want := []string{"1>7:5", "3>7:4", "51>7:3", "5>7:2", "6>7:1", "7>7:0"}
+		if got := closureRows(t, database, 7); !slices.Equal(got, want) {
+			t.Errorf("closure of 7 = %v, want %v", got, want)
+		}
+		if ids, _ := depts.GetDeptIDs(ctx, 2, true); !slices.Equal(ids, []uint64{2}) {
+			t.Errorf("GetDeptIDs(2) = %v, want [2]", ids)
+		}
+		ids, _ := depts.GetDeptIDs(ctx, 3, true)
+		slices.Sort(ids)
+		if !slices.Equal(ids, []uint64{3, 5, 6, 7, 51, 500}) {
+			t.Errorf("GetDeptIDs(3) = %v, want [3 5 6 7 51 500]", ids)
+		}
+		assertClosureConsistent(t, database)
+	})
+
+	t.Run("cycle rejected", func(t *testing.T) {
+		database := newTestDB(t)
+		depts := newDeptStore(database)
+		seedDepts(t, depts)
+		ancestors, closure := deptAncestors(t, database), closureRows(t, database)
+
+		for _, move := range [][2]uint64{{2, 7}, {5, 6}, {5, 5}} {
+			if err := depts.Move(ctx, move[0], move[1]); !errors.Is(err, ErrDeptMoveCycle) {
+				t.Errorf("Move(%d, %d) error = %v, want ErrDeptMoveCycle", move[0], move[1], err)
+			}
+		}
+		if got := deptAncestors(t, database); !mapsEqual(got, ancestors) {
+			t.Errorf("ancestors = %v, want unchanged %v", got, ancestors)
+		}
+		if got := closureRows(t, database); !slices.Equal(got, closure) {
+			t.Errorf("closure = %v, want unchanged", got)
+		}
+	})
+
+	t.Run("same parent no-op", func(t *testing.T) {
+		database := newTestDB(t)
+		depts := newDeptStore(database)
+		seedDepts(t, depts)
+		ancestors, closure := deptAncestors(t, database), closureRows(t, database)
+
+		if err := depts.Move(ctx, 6, 5); err != nil {
+			t.Fatalf("Move failed: %v", err)
+		}
+		if got := deptAncestors(t, database); !mapsEqual(got, ancestors) {
+			t.Errorf("ancestors = %v, want unchanged %v", got, ancestors)
+		}
+		if got := closureRows(t, database); !slices.Equal(got, closure) {
+			t.Errorf("closure = %v, want unchanged", got)
+		}
+	})
+}
+
+// mapsEqual compares ancestors maps
+func mapsEqual(a, b map[uint64]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -18,23 +18,32 @@ func newPermissionStore(db *gorm.DB) IPermissionStore {
 	return &permissionStore{db: db}
 }
 
-// GetUserPermissions retrieves all permission patterns for a user (via roles)
+// GetUserPermissions retrieves all permission rules for a user (via roles)
+// Deny rules are returned with "!" prefix (e.g., !user:delete)
 func (s *permissionStore) GetUserPermissions(ctx context.Context, userID uint64) ([]string, error) {
-	var permissions []string
+	var records []struct {
+		PermissionPattern string
+		Effect            string
+	}
 
 	// Get all permission patterns from user's roles
 	err := s.db.WithContext(ctx).
 		Model(&model.RolePermission{}).
-		Select("sys_role_permission.permission_pattern").
+		Select("sys_role_permission.permission_pattern, sys_role_permission.effect").
 		Joins("JOIN sys_user_role ON sys_user_role.role_id = sys_role_permission.role_id").
 		Where("sys_user_role.user_id = ?", userID).
 		Where("sys_role_permission.status = ?", model.StatusEnabled).
-		Pluck("permission_pattern", &permissions).Error
+		Find(&records).Error
 
 	if err != nil {
 		return nil, err
 	}
 
+	permissions := make([]string, 0, len(records))
+	for _, record := range records {
+		permissions = append(permissions, model.PermissionRule(record.PermissionPattern, record.Effect))
+	}
+
 	return permissions, nil
 }
 
@@ -99,17 +108,20 @@ func (s *permissionStore) GetPermissionsByPattern(ctx context.Context, pattern s
 	return permissions, nil
 }
 
-// GetAllPermissions retrieves all permission patterns (for cache warming)
+// GetAllPermissions retrieves permission rules of all enabled roles, grouped by role ID (for cache warming)
 func (s *permissionStore) GetAllPermissions(ctx context.Context) (map[uint64][]string, error) {
 	var records []struct {
 		RoleID            uint64
 		PermissionPattern string
+		Effect            string
 	}
 
 	err := s.db.WithContext(ctx).
 		Model(&model.RolePermission{}).
-		Select("role_id, permission_pattern").
-		Where("status = ?", model.StatusEnabled).
+		Select("sys_role_permission.role_id, sys_role_permission.permission_pattern, sys_role_permission.effect").
+		Joins("JOIN sys_role ON sys_role.id = sys_role_permission.role_id AND sys_role.deleted_at IS NULL").
+		Where("sys_role_permission.status = ?", model.StatusEnabled).
+		Where("sys_role.status = ?", model.StatusEnabled).
 		Find(&records).Error
 
 	if err != nil {
@@ -119,7 +131,7 @@ func (s *permissionStore) GetAllPermissions(ctx context.Context) (map[uint64][]s
 	// Group by role ID
 	result := make(map[uint64][]string)
 	for _, record := range records {
-		result[record.RoleID] = append(result[record.RoleID], record.PermissionPattern)
+		result[record.RoleID] = append(result[record.RoleID], model.PermissionRule(record.PermissionPattern, record.Effect))
 	}
 
 	return result, nil
@@ -63,7 +63,7 @@ func (s *roleStore) List(ctx context.Context, opts *ListOptions) ([]*model.Role,
 	var roles []*model.Role
 	var total int64
 
-	// Build base query
+	// Build base query (roles are system configuration, not restricted to data scope)
 	query := s.db.WithContext(ctx).Model(&model.Role{})
 
 	// Apply filters
@@ -124,6 +124,46 @@ func (s *roleStore) AssignMenus(ctx context.Context, roleID uint64, menuIDs []ui
 	})
 }
 
+// AssignDepts assigns departments to a role (replaces existing departments, DataScopeCustom)
+// includeChildren also grants descendants of each department
+func (s *roleStore) AssignDepts(ctx context.Context, roleID uint64, deptIDs []uint64, includeChildren bool) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Delete existing dept assignments
+		if err := tx.Where("role_id = ?", roleID).Delete(&model.RoleDept{}).Error; err != nil {
+			return fmt.Errorf("failed to delete existing depts: %w", err)
+		}
+
+		// Assign new depts
+		if len(deptIDs) > 0 {
+			roleDepts := make([]model.RoleDept, 0, len(deptIDs))
+			for _, deptID := range deptIDs {
+				roleDepts = append(roleDepts, model.RoleDept{
+					RoleID:          roleID,
+					DeptID:          deptID,
+					IncludeChildren: includeChildren,
+				})
+			}
+			if err := tx.Create(&roleDepts).Error; err != nil {
+				return fmt.Errorf("failed to assign depts: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetRoleDepts retrieves departments assigned to a role (DataScopeCustom)
+func (s *roleStore) GetRoleDepts(ctx context.Context, roleID uint64) ([]*model.RoleDept, error) {
+	var roleDepts []*model.RoleDept
+	err := s.db.WithContext(ctx).
+		Where("role_id = ?", roleID).
+		Find(&roleDepts).Error
+	if err != nil {
+		return nil, err
+	}
+	return roleDepts, nil
+}
+
 // GetRoleMenus retrieves menus for a role
 func (s *roleStore) GetRoleMenus(ctx context.Context, roleID uint64) ([]*model.Menu, error) {
 	var menus []*model.Menu
@@ -138,3 +178,20 @@ func (s *roleStore) GetRoleMenus(ctx context.Context, roleID uint64) ([]*model.M
 	}
 	return menus, nil
 }
+
+// GetUserIDs retrieves IDs of users holding a role (keyset pagination by user_id)
+// Pass the last user ID of previous batch as afterUserID (0 for first batch)
+// Uses idx_role_id (role_id, user_id) of sys_user_role
+func (s *roleStore) GetUserIDs(ctx context.Context, roleID uint64, afterUserID uint64, limit int) ([]uint64, error) {
+	var userIDs []uint64
+	err := s.db.WithContext(ctx).
+		Model(&model.UserRole{}).
+		Where("role_id = ? AND user_id > ?", roleID, afterUserID).
+		Order("user_id ASC").
+		Limit(limit).
+		Pluck("user_id", &userIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
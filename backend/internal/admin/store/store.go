@@ -3,6 +3,7 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"github.com/sword-demon/go-react-admin/internal/pkg/model"
 )
@@ -14,6 +15,7 @@ type IStore interface {
 	Depts() IDeptStore
 	Menus() IMenuStore
 	Permissions() IPermissionStore
+	APIDocs() IAPIDocStore
 
 	// Transaction executes a function within a database transaction
 	// If the function returns an error, the transaction is rolled back
@@ -32,7 +34,9 @@ type IUserStore interface {
 	GetByUsername(ctx context.Context, username string) (*model.User, error)
 	List(ctx context.Context, opts *ListOptions) ([]*model.User, int64, error)
 	GetUserRoles(ctx context.Context, userID uint64) ([]*model.Role, error)
+	GetDeptID(ctx context.Context, userID uint64) (uint64, error)
 	AssignRoles(ctx context.Context, userID uint64, roleIDs []uint64) error
+	GetRecentLoginUserIDs(ctx context.Context, since time.Time, limit int) ([]uint64, error)
 }
 
 // IRoleStore defines role data access operations
@@ -45,12 +49,16 @@ type IRoleStore interface {
 	List(ctx context.Context, opts *ListOptions) ([]*model.Role, int64, error)
 	AssignMenus(ctx context.Context, roleID uint64, menuIDs []uint64) error
 	GetRoleMenus(ctx context.Context, roleID uint64) ([]*model.Menu, error)
+	GetUserIDs(ctx context.Context, roleID uint64, afterUserID uint64, limit int) ([]uint64, error)
+	AssignDepts(ctx context.Context, roleID uint64, deptIDs []uint64, includeChildren bool) error
+	GetRoleDepts(ctx context.Context, roleID uint64) ([]*model.RoleDept, error)
 }
 
 // IDeptStore defines department data access operations
 type IDeptStore interface {
 	Create(ctx context.Context, dept *model.Dept) error
 	Update(ctx context.Context, dept *model.Dept) error
+	Move(ctx context.Context, id, parentID uint64) error
 	Delete(ctx context.Context, id uint64) error
 	Get(ctx context.Context, id uint64) (*model.Dept, error)
 	List(ctx context.Context) ([]*model.Dept, error)
@@ -83,6 +91,14 @@ type IPermissionStore interface {
 	GetAllPermissions(ctx context.Context) (map[uint64][]string, error)
 }
 
+// IAPIDocStore defines API documentation data access operations
+type IAPIDocStore interface {
+	Upsert(ctx context.Context, docs []*model.APIDoc) error
+	MarkStale(ctx context.Context, ids []uint64) error
+	ListAll(ctx context.Context) ([]*model.APIDoc, error)
+	List(ctx context.Context, opts *ListOptions) ([]*model.APIDoc, int64, error)
+}
+
 // ListOptions defines common list query options
 type ListOptions struct {
 	Page     int
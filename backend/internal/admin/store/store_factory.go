@@ -41,6 +41,11 @@ func (ds *datastore) Permissions() IPermissionStore {
 	return newPermissionStore(ds.db)
 }
 
+// APIDocs returns API doc store
+func (ds *datastore) APIDocs() IAPIDocStore {
+	return newAPIDocStore(ds.db)
+}
+
 // Transaction executes a function within a database transaction
 // Usage example:
 //
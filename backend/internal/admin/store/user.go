@@ -3,7 +3,9 @@ package store
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/sword-demon/go-react-admin/internal/pkg/datascope"
 	"github.com/sword-demon/go-react-admin/internal/pkg/model"
 	"gorm.io/gorm"
 )
@@ -65,8 +67,9 @@ func (s *userStore) List(ctx context.Context, opts *ListOptions) ([]*model.User,
 	var users []*model.User
 	var total int64
 
-	// Build base query
-	query := s.db.WithContext(ctx).Model(&model.User{})
+	// Build base query, restricted to data scope of current user
+	query := s.db.WithContext(ctx).Model(&model.User{}).
+		Scopes(datascope.Filter(ctx, datascope.Columns{Dept: "dept_id", User: "id", Creator: "created_by"}))
 
 	// Apply filters
 	if username, ok := opts.Filters["username"].(string); ok && username != "" {
@@ -119,6 +122,18 @@ func (s *userStore) GetUserRoles(ctx context.Context, userID uint64) ([]*model.R
 	return roles, nil
 }
 
+// GetDeptID retrieves department ID of a user (0 = none)
+func (s *userStore) GetDeptID(ctx context.Context, userID uint64) (uint64, error) {
+	var user model.User
+	err := s.db.WithContext(ctx).
+		Select("id", "dept_id").
+		First(&user, userID).Error
+	if err != nil {
+		return 0, err
+	}
+	return user.DeptID, nil
+}
+
 // AssignRoles assigns roles to a user (replaces existing roles)
 func (s *userStore) AssignRoles(ctx context.Context, userID uint64, roleIDs []uint64) error {
 	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
@@ -144,3 +159,24 @@ func (s *userStore) AssignRoles(ctx context.Context, userID uint64, roleIDs []ui
 		return nil
 	})
 }
+
+// GetRecentLoginUserIDs retrieves IDs of enabled users with successful logins since a time
+// Most recent login first, limit <= 0 means no limit (sys_login_log, used by cache warmup)
+func (s *userStore) GetRecentLoginUserIDs(ctx context.Context, since time.Time, limit int) ([]uint64, error) {
+	query := s.db.WithContext(ctx).
+		Table("sys_login_log").
+		Joins("JOIN sys_user ON sys_user.id = sys_login_log.user_id AND sys_user.deleted_at IS NULL").
+		Where("sys_login_log.status = ? AND sys_login_log.login_time >= ?", model.StatusEnabled, since).
+		Where("sys_user.status = ?", model.StatusEnabled).
+		Group("sys_login_log.user_id").
+		Order("MAX(sys_login_log.login_time) DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var userIDs []uint64
+	if err := query.Pluck("sys_login_log.user_id", &userIDs).Error; err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sword-demon/go-react-admin/internal/pkg/errors"
+	"github.com/sword-demon/go-react-admin/internal/pkg/model"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// UserStore defines user lookup needed by authentication
+// Implemented by store.IUserStore
+type UserStore interface {
+	GetByUsername(ctx context.Context, username string) (*model.User, error)
+}
+
+// dummyHash is compared on unknown usernames (timing equalization)
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte("go-react-admin-dummy-password"), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err) // Only fails for cost out of range
+	}
+	return hash
+})
+
+// Authenticator handles login, token refresh, logout and token verification
+type Authenticator struct {
+	users    UserStore
+	tokens   *TokenManager
+	denylist Denylist
+}
+
+// NewAuthenticator creates a new authenticator
+func NewAuthenticator(users UserStore, tokens *TokenManager, denylist Denylist) *Authenticator {
+	return &Authenticator{
+		users:    users,
+		tokens:   tokens,
+		denylist: denylist,
+	}
+}
+
+// Login verifies username/password and issues a token pair
+func (a *Authenticator) Login(ctx context.Context, username, password string) (*TokenPair, *model.User, error) {
+	// 1. Load user (don't reveal whether username exists)
+	user, err := a.users.GetByUsername(ctx, username)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			// Same bcrypt cost as existing users, so response time does not reveal the username
+			_ = bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+			return nil, nil, errors.ErrInvalidCredentials
+		}
+		return nil, nil, errors.Wrap(errors.ErrInternalServer, "failed to get user", err)
+	}
+
+	// 2. Verify bcrypt password
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return nil, nil, errors.ErrInvalidCredentials
+	}
+
+	// 3. Business rule: disabled users cannot login
+	if !user.IsEnabled() {
+		return nil, nil, errors.ErrUserDisabledError
+	}
+
+	// 4. Issue tokens
+	pair, err := a.tokens.GenerateTokenPair(user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pair, user, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair
+// The used refresh token is revoked (rotation), so it can only be used once
+func (a *Authenticator) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	// 1. Verify refresh token
+	claims, err := a.Verify(ctx, refreshToken, TokenTypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Claim (revoke) used refresh token atomically: of concurrent refreshes
+	// with the same token only one gets a new pair
+	claimed, err := a.claim(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, errors.ErrTokenRevokedError
+	}
+
+	// 3. Reload user (status or dept may have changed since login)
+	user, err := a.users.GetByUsername(ctx, claims.Username)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrTokenInvalidError
+		}
+		return nil, errors.Wrap(errors.ErrInternalServer, "failed to get user", err)
+	}
+	if user.ID != claims.UserID {
+		return nil, errors.ErrTokenInvalidError
+	}
+	if !user.IsEnabled() {
+		return nil, errors.ErrUserDisabledError
+	}
+
+	// 4. Issue new tokens
+	return a.tokens.GenerateTokenPair(user)
+}
+
+// Logout revokes the current access token and (optionally) its refresh token
+func (a *Authenticator) Logout(ctx context.Context, accessClaims *Claims, refreshToken string) error {
+	if err := a.revoke(ctx, accessClaims); err != nil {
+		return err
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	// Refresh token is optional: ignore if already expired/revoked
+	refreshClaims, err := a.Verify(ctx, refreshToken, TokenTypeRefresh)
+	if err != nil {
+		return nil
+	}
+	if refreshClaims.UserID != accessClaims.UserID {
+		return errors.ErrTokenInvalidError
+	}
+	return a.revoke(ctx, refreshClaims)
+}
+
+// Verify parses a token, checks its type and that it has not been revoked
+func (a *Authenticator) Verify(ctx context.Context, tokenString string, tokenType string) (*Claims, error) {
+	claims, err := a.tokens.ParseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != tokenType {
+		return nil, errors.ErrTokenInvalidError
+	}
+
+	revoked, err := a.denylist.Contains(ctx, claims.ID)
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrInternalServer, "failed to check token denylist", err)
+	}
+	if revoked {
+		return nil, errors.ErrTokenRevokedError
+	}
+
+	return claims, nil
+}
+
+// claim revokes token unless already revoked, returns false if it was
+func (a *Authenticator) claim(ctx context.Context, claims *Claims) (bool, error) {
+	if claims.ExpiresAt == nil {
+		return false, errors.ErrTokenInvalidError
+	}
+	claimed, err := a.denylist.Claim(ctx, claims.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		return false, errors.Wrap(errors.ErrInternalServer, "failed to revoke token", err)
+	}
+	return claimed, nil
+}
+
+// revoke adds token to denylist until it expires
+func (a *Authenticator) revoke(ctx context.Context, claims *Claims) error {
+	if claims == nil || claims.ExpiresAt == nil {
+		return nil
+	}
+	if err := a.denylist.Add(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return errors.Wrap(errors.ErrInternalServer, "failed to revoke token", err)
+	}
+	return nil
+}
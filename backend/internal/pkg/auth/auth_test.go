@@ -0,0 +1,306 @@
+package auth_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sword-demon/go-react-admin/internal/pkg/auth"
+	"github.com/sword-demon/go-react-admin/internal/pkg/cache"
+	"github.com/sword-demon/go-react-admin/internal/pkg/errors"
+	"github.com/sword-demon/go-react-admin/internal/pkg/model"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// mockUserStore implements auth.UserStore
+type mockUserStore struct {
+	users map[string]*model.User
+}
+
+func (m *mockUserStore) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	user, ok := m.users[username]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+// newTestUsers creates a store with enabled alice and disabled bob (password: secret)
+func newTestUsers(t *testing.T) *mockUserStore {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	alice := testUser()
+	alice.Password = string(hash)
+	bob := &model.User{BaseModel: model.BaseModel{ID: 8}, Username: "bob", Password: string(hash), Status: model.StatusDisabled}
+	return &mockUserStore{users: map[string]*model.User{"alice": alice, "bob": bob}}
+}
+
+// testDenylists returns in-process and Redis denylists
+func testDenylists(t *testing.T) map[string]auth.Denylist {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	redisClient := &cache.RedisClient{Client: redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+	t.Cleanup(func() { _ = redisClient.Client.Close() })
+	return map[string]auth.Denylist{
+		"memory": auth.NewDenylist(nil),
+		"redis":  auth.NewDenylist(redisClient),
+	}
+}
+
+// TestLogin tests credentials and user status checks
+func TestLogin(t *testing.T) {
+	a := auth.NewAuthenticator(newTestUsers(t), auth.NewTokenManager(testConfig()), auth.NewDenylist(nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		want     errors.ErrorCode // 0 = success
+	}{
+		{"ok", "alice", "secret", 0},
+		{"wrong password", "alice", "wrong", errors.ErrUserInvalidCredentials},
+		{"unknown user", "nobody", "secret", errors.ErrUserInvalidCredentials},
+		{"disabled", "bob", "secret", errors.ErrUserDisabled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pair, user, err := a.Login(ctx, tt.username, tt.password)
+			if tt.want != 0 {
+				if !errors.Is(err, tt.want) {
+					t.Errorf("Login error = %v, want code %d", err, tt.want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Login failed: %v", err)
+			}
+			if user.ID != 7 || pair.AccessToken == "" || pair.RefreshToken == "" {
+				t.Errorf("Login = %+v, %+v", pair, user)
+			}
+			if _, err := a.Verify(ctx, pair.AccessToken, auth.TokenTypeAccess); err != nil {
+				t.Errorf("Verify(access) failed: %v", err)
+			}
+		})
+	}
+}
+
+// TestVerifyTokenType tests access and refresh tokens are not interchangeable
+func TestVerifyTokenType(t *testing.T) {
+	tokens := auth.NewTokenManager(testConfig())
+	a := auth.NewAuthenticator(newTestUsers(t), tokens, auth.NewDenylist(nil))
+	pair, err := tokens.GenerateTokenPair(testUser())
+	if err != nil {
+		t.Fatalf("GenerateTokenPair failed: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := a.Verify(ctx, pair.RefreshToken, auth.TokenTypeAccess); !errors.Is(err, errors.ErrTokenInvalid) {
+		t.Errorf("Verify(refresh as access) error = %v, want invalid token", err)
+	}
+	if _, err := a.Verify(ctx, pair.AccessToken, auth.TokenTypeRefresh); !errors.Is(err, errors.ErrTokenInvalid) {
+		t.Errorf("Verify(access as refresh) error = %v, want invalid token", err)
+	}
+	if _, err := a.Refresh(ctx, pair.AccessToken); !errors.Is(err, errors.ErrTokenInvalid) {
+		t.Errorf("Refresh(access token) error = %v, want invalid token", err)
+	}
+}
+
+// TestRefreshRotation tests a refresh token works once, also under concurrent use
+func TestRefreshRotation(t *testing.T) {
+	for name, denylist := range testDenylists(t) {
+		t.Run(name, func(t *testing.T) {
+			tokens := auth.NewTokenManager(testConfig())
+			a := auth.NewAuthenticator(newTestUsers(t), tokens, denylist)
+			ctx := context.Background()
+
+			pair, _, err := a.Login(ctx, "alice", "secret")
+			if err != nil {
+				t.Fatalf("Login failed: %v", err)
+			}
+
+			// Rotation: new pair works, old refresh token is revoked
+			rotated, err := a.Refresh(ctx, pair.RefreshToken)
+			if err != nil {
+				t.Fatalf("Refresh failed: %v", err)
+			}
+			if _, err := a.Refresh(ctx, pair.RefreshToken); !errors.Is(err, errors.ErrTokenRevoked) {
+				t.Errorf("Refresh(reused token) error = %v, want revoked", err)
+			}
+			if _, err := a.Verify(ctx, rotated.AccessToken, auth.TokenTypeAccess); err != nil {
+				t.Errorf("Verify(rotated access) failed: %v", err)
+			}
+
+			// Concurrent reuse: exactly one refresh succeeds
+			var (
+				wg        sync.WaitGroup
+				succeeded atomic.Int32
+			)
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if _, err := a.Refresh(ctx, rotated.RefreshToken); err == nil {
+						succeeded.Add(1)
+					} else if !errors.Is(err, errors.ErrTokenRevoked) {
+						t.Errorf("concurrent Refresh error = %v, want revoked", err)
+					}
+				}()
+			}
+			wg.Wait()
+			if n := succeeded.Load(); n != 1 {
+				t.Errorf("concurrent refreshes succeeded %d times, want 1", n)
+			}
+		})
+	}
+}
+
+// TestLogout tests logout revokes access and refresh tokens
+func TestLogout(t *testing.T) {
+	tokens := auth.NewTokenManager(testConfig())
+	a := auth.NewAuthenticator(newTestUsers(t), tokens, auth.NewDenylist(nil))
+	ctx := context.Background()
+
+	pair, _, err := a.Login(ctx, "alice", "secret")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	claims, err := a.Verify(ctx, pair.AccessToken, auth.TokenTypeAccess)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if err := a.Logout(ctx, claims, pair.RefreshToken); err != nil {
+		t.Fatalf("Logout failed: %v", err)
+	}
+
+	if _, err := a.Verify(ctx, pair.AccessToken, auth.TokenTypeAccess); !errors.Is(err, errors.ErrTokenRevoked) {
+		t.Errorf("Verify(access) after logout error = %v, want revoked", err)
+	}
+	if _, err := a.Refresh(ctx, pair.RefreshToken); !errors.Is(err, errors.ErrTokenRevoked) {
+		t.Errorf("Refresh after logout error = %v, want revoked", err)
+	}
+}
+
+// TestDenylist tests revocations last until token expiry, and Claim succeeds once
+func TestDenylist(t *testing.T) {
+	for name, denylist := range testDenylists(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			expiresAt := time.Now().Add(time.Hour)
+
+			if revoked, err := denylist.Contains(ctx, "a"); err != nil || revoked {
+				t.Fatalf("Contains(a) = %v, %v, want false", revoked, err)
+			}
+			if err := denylist.Add(ctx, "a", expiresAt); err != nil {
+				t.Fatalf("Add failed: %v", err)
+			}
+			if revoked, err := denylist.Contains(ctx, "a"); err != nil || !revoked {
+				t.Errorf("Contains(a) = %v, %v, want true", revoked, err)
+			}
+
+			// Already expired tokens are not stored
+			if err := denylist.Add(ctx, "expired", time.Now().Add(-time.Second)); err != nil {
+				t.Fatalf("Add(expired) failed: %v", err)
+			}
+			if revoked, _ := denylist.Contains(ctx, "expired"); revoked {
+				t.Errorf("Contains(expired) = true, want false")
+			}
+
+			if claimed, err := denylist.Claim(ctx, "b", expiresAt); err != nil || !claimed {
+				t.Errorf("Claim(b) = %v, %v, want true", claimed, err)
+			}
+			if claimed, err := denylist.Claim(ctx, "b", expiresAt); err != nil || claimed {
+				t.Errorf("second Claim(b) = %v, %v, want false", claimed, err)
+			}
+			if claimed, err := denylist.Claim(ctx, "a", expiresAt); err != nil || claimed {
+				t.Errorf("Claim(revoked a) = %v, %v, want false", claimed, err)
+			}
+			if revoked, _ := denylist.Contains(ctx, "b"); !revoked {
+				t.Errorf("Contains(claimed b) = false, want true")
+			}
+		})
+	}
+}
+
+// TestVerifyBreakerOpen tests authentication keeps working while the Redis breaker is open
+// Denylist fails open to revocations of this instance (see redisDenylist)
+func TestVerifyBreakerOpen(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := (&cache.RedisClient{Client: redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})}).
+		WithBreaker(cache.NewCircuitBreaker(1, time.Hour))
+	t.Cleanup(func() { _ = redisClient.Client.Close() })
+
+	tokens := auth.NewTokenManager(testConfig())
+	a := auth.NewAuthenticator(newTestUsers(t), tokens, auth.NewDenylist(redisClient))
+	ctx := context.Background()
+
+	login := func() *auth.TokenPair {
+		pair, _, err := a.Login(ctx, "alice", "secret")
+		if err != nil {
+			t.Fatalf("Login failed: %v", err)
+		}
+		return pair
+	}
+	loggedOut, active, revokedElsewhere := login(), login(), login()
+
+	// Revoked by this instance (mirrored) and by another instance (Redis only)
+	claims, err := a.Verify(ctx, loggedOut.AccessToken, auth.TokenTypeAccess)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if err := a.Logout(ctx, claims, ""); err != nil {
+		t.Fatalf("Logout failed: %v", err)
+	}
+	elsewhere, err := tokens.ParseToken(revokedElsewhere.AccessToken)
+	if err != nil {
+		t.Fatalf("ParseToken failed: %v", err)
+	}
+	if err := mr.Set(auth.DenylistKey(elsewhere.ID), "1"); err != nil {
+		t.Fatalf("failed to set denylist key: %v", err)
+	}
+
+	// Redis goes down, first failure opens the breaker
+	mr.Close()
+	_ = redisClient.Ping(ctx).Err()
+	if state := redisClient.BreakerStats().State; state != cache.BreakerOpen {
+		t.Fatalf("breaker state = %s, want open", state)
+	}
+
+	if _, err := a.Verify(ctx, active.AccessToken, auth.TokenTypeAccess); err != nil {
+		t.Errorf("Verify(active) with breaker open failed: %v", err)
+	}
+	if _, err := a.Verify(ctx, loggedOut.AccessToken, auth.TokenTypeAccess); !errors.Is(err, errors.ErrTokenRevoked) {
+		t.Errorf("Verify(logged out) with breaker open error = %v, want revoked", err)
+	}
+	if _, err := a.Verify(ctx, revokedElsewhere.AccessToken, auth.TokenTypeAccess); err != nil {
+		t.Errorf("Verify(revoked elsewhere) with breaker open = %v, want accepted (fail open)", err)
+	}
+
+	// Refresh rotation and logout still work, recorded in this instance
+	rotated, err := a.Refresh(ctx, active.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh with breaker open failed: %v", err)
+	}
+	if _, err := a.Refresh(ctx, active.RefreshToken); !errors.Is(err, errors.ErrTokenRevoked) {
+		t.Errorf("Refresh(reused) with breaker open error = %v, want revoked", err)
+	}
+	rotatedClaims, err := a.Verify(ctx, rotated.AccessToken, auth.TokenTypeAccess)
+	if err != nil {
+		t.Fatalf("Verify(rotated) with breaker open failed: %v", err)
+	}
+	if err := a.Logout(ctx, rotatedClaims, rotated.RefreshToken); err != nil {
+		t.Fatalf("Logout with breaker open failed: %v", err)
+	}
+	if _, err := a.Verify(ctx, rotated.AccessToken, auth.TokenTypeAccess); !errors.Is(err, errors.ErrTokenRevoked) {
+		t.Errorf("Verify(rotated) after logout error = %v, want revoked", err)
+	}
+}
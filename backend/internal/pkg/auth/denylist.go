@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sword-demon/go-react-admin/internal/pkg/cache"
+)
+
+// Denylist stores revoked token IDs (jti) until the token expires
+type Denylist interface {
+	// Add revokes a token until expiresAt
+	Add(ctx context.Context, tokenID string, expiresAt time.Time) error
+
+	// Contains checks if a token has been revoked
+	Contains(ctx context.Context, tokenID string) (bool, error)
+
+	// Claim atomically revokes a token that is not revoked yet
+	// Returns false if it already was (one-time tokens used concurrently)
+	Claim(ctx context.Context, tokenID string, expiresAt time.Time) (bool, error)
+}
+
+// NewDenylist creates a denylist backed by Redis
+// Falls back to in-process denylist when redis is nil (single instance only)
+func NewDenylist(redis *cache.RedisClient) Denylist {
+	if redis == nil {
+		return newMemoryDenylist()
+	}
+	return &redisDenylist{redis: redis, local: newMemoryDenylist()}
+}
+
+// DenylistKey generates cache key for a revoked token
+func DenylistKey(tokenID string) string {
+	return fmt.Sprintf("auth:denylist:%s", tokenID)
+}
+
+// redisDenylist implements Denylist with Redis keys (TTL = remaining token lifetime)
+//
+// Revocations are mirrored in process memory. While the Redis circuit breaker is
+// open (cache.ErrCircuitOpen) the denylist fails open to the mirror, so authenticated
+// traffic keeps being served in degraded mode:
+//   - Tokens revoked by this instance stay rejected
+//   - Tokens revoked only by other instances are accepted until Redis recovers
+//   - Revocations and refresh token claims are recorded in this instance only
+//
+// Other Redis errors are returned (requests fail until the breaker opens)
+type redisDenylist struct {
+	redis *cache.RedisClient
+	local *memoryDenylist // Revocations of this instance
+}
+
+// Add revokes a token
+func (d *redisDenylist) Add(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil // Already expired, nothing to revoke
+	}
+	_ = d.local.Add(ctx, tokenID, expiresAt)
+	err := d.redis.Set(ctx, DenylistKey(tokenID), 1, ttl)
+	if errors.Is(err, cache.ErrCircuitOpen) {
+		return nil
+	}
+	return err
+}
+
+// Contains checks if a token has been revoked
+func (d *redisDenylist) Contains(ctx context.Context, tokenID string) (bool, error) {
+	// Mirror first: also covers revocations made while Redis was down
+	if revoked, _ := d.local.Contains(ctx, tokenID); revoked {
+		return true, nil
+	}
+
+	n, err := d.redis.Exists(ctx, DenylistKey(tokenID))
+	if errors.Is(err, cache.ErrCircuitOpen) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Claim revokes a token unless already revoked (SETNX)
+func (d *redisDenylist) Claim(ctx context.Context, tokenID string, expiresAt time.Time) (bool, error) {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return false, nil // Expired tokens cannot be claimed
+	}
+	claimed, err := d.redis.SetNX(ctx, DenylistKey(tokenID), 1, ttl)
+	if errors.Is(err, cache.ErrCircuitOpen) {
+		return d.local.Claim(ctx, tokenID, expiresAt)
+	}
+	if err != nil || !claimed {
+		return claimed, err
+	}
+	// Mirror, so the claim is still known if Redis goes down
+	return d.local.Claim(ctx, tokenID, expiresAt)
+}
+
+// memoryDenylist implements Denylist in process memory
+type memoryDenylist struct {
+	mu    sync.Mutex
+	items map[string]time.Time // tokenID -> expiresAt
+}
+
+// newMemoryDenylist creates an in-process denylist
+func newMemoryDenylist() *memoryDenylist {
+	return &memoryDenylist{
+		items: make(map[string]time.Time),
+	}
+}
+
+// Add revokes a token
+func (d *memoryDenylist) Add(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	if !expiresAt.After(now) {
+		return nil
+	}
+
+	// Purge expired entries on write to keep the map bounded
+	for id, exp := range d.items {
+		if !exp.After(now) {
+			delete(d.items, id)
+		}
+	}
+
+	d.items[tokenID] = expiresAt
+	return nil
+}
+
+// Contains checks if a token has been revoked
+func (d *memoryDenylist) Contains(ctx context.Context, tokenID string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	exp, exists := d.items[tokenID]
+	if !exists {
+		return false, nil
+	}
+	if !exp.After(time.Now()) {
+		delete(d.items, tokenID)
+		return false, nil
+	}
+	return true, nil
+}
+
+// Claim revokes a token unless already revoked
+func (d *memoryDenylist) Claim(ctx context.Context, tokenID string, expiresAt time.Time) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	if !expiresAt.After(now) {
+		return false, nil
+	}
+	if exp, exists := d.items[tokenID]; exists && exp.After(now) {
+		return false, nil
+	}
+	d.items[tokenID] = expiresAt
+	return true, nil
+}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sword-demon/go-react-admin/internal/pkg/core"
+	"github.com/sword-demon/go-react-admin/internal/pkg/errors"
+)
+
+// ContextKeyClaims is the gin context key of authenticated user claims
+const ContextKeyClaims = "auth.claims"
+
+// claimsContextKey is the context.Context key of authenticated user claims
+type claimsContextKey struct{}
+
+// Middleware verifies the Bearer access token and puts claims into request context
+// Usage:
+//
+//	protected := r.Group("/api/v1", auth.Middleware(authenticator))
+func Middleware(a *Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := extractToken(c)
+		if tokenString == "" {
+			core.AbortWithError(c, errors.ErrTokenMissingError)
+			return
+		}
+
+		claims, err := a.Verify(c.Request.Context(), tokenString, TokenTypeAccess)
+		if err != nil {
+			core.AbortWithError(c, err)
+			return
+		}
+
+		// Inject into both gin context and request context (for biz/store layers)
+		c.Set(ContextKeyClaims, claims)
+		c.Request = c.Request.WithContext(WithClaims(c.Request.Context(), claims))
+
+		c.Next()
+	}
+}
+
+// WithClaims returns a copy of ctx carrying the user claims
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// FromContext returns the authenticated user claims
+// Accepts both *gin.Context and request context
+func FromContext(ctx context.Context) (*Claims, bool) {
+	if c, ok := ctx.(*gin.Context); ok {
+		if val, exists := c.Get(ContextKeyClaims); exists {
+			claims, ok := val.(*Claims)
+			return claims, ok
+		}
+		return nil, false
+	}
+
+	claims, ok := ctx.Value(claimsContextKey{}).(*Claims)
+	return claims, ok
+}
+
+// UserIDFromContext returns ID of the authenticated user
+// Used by db.RegisterAudit to fill created_by/updated_by
+func UserIDFromContext(ctx context.Context) (uint64, bool) {
+	claims, ok := FromContext(ctx)
+	if !ok || claims == nil {
+		return 0, false
+	}
+	return claims.UserID, true
+}
+
+// extractToken reads token from "Authorization: Bearer <token>" header
+func extractToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if header == "" {
+		return ""
+	}
+
+	parts := strings.SplitN(header, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
@@ -0,0 +1,156 @@
+// Package auth provides JWT authentication utilities
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sword-demon/go-react-admin/internal/pkg/errors"
+	"github.com/sword-demon/go-react-admin/internal/pkg/model"
+)
+
+// Token types (stored in claims to prevent using refresh token as access token)
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// Config represents JWT configuration
+type Config struct {
+	Secret            string
+	Issuer            string
+	AccessExpiration  time.Duration
+	RefreshExpiration time.Duration
+}
+
+// DefaultConfig returns default JWT configuration
+func DefaultConfig() *Config {
+	return &Config{
+		Secret:            "go-react-admin-secret-key-change-in-production",
+		Issuer:            "go-react-admin",
+		AccessExpiration:  24 * time.Hour,
+		RefreshExpiration: 7 * 24 * time.Hour,
+	}
+}
+
+// Claims represents JWT claims of an authenticated user
+type Claims struct {
+	UserID    uint64 `json:"user_id"`
+	Username  string `json:"username"`
+	DeptID    uint64 `json:"dept_id"`
+	TokenType string `json:"token_type"`
+	jwt.RegisteredClaims
+}
+
+// TokenPair represents access + refresh tokens returned to client
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"` // Always "Bearer"
+	ExpiresIn    int64  `json:"expires_in"` // Access token lifetime in seconds
+}
+
+// TokenManager issues and verifies JWT tokens (HS256)
+type TokenManager struct {
+	config *Config
+}
+
+// NewTokenManager creates a new token manager
+func NewTokenManager(cfg *Config) *TokenManager {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	if cfg.AccessExpiration <= 0 {
+		cfg.AccessExpiration = 24 * time.Hour
+	}
+	if cfg.RefreshExpiration <= 0 {
+		cfg.RefreshExpiration = 7 * 24 * time.Hour
+	}
+	return &TokenManager{config: cfg}
+}
+
+// GenerateTokenPair issues access + refresh tokens for a user
+func (m *TokenManager) GenerateTokenPair(user *model.User) (*TokenPair, error) {
+	accessToken, err := m.generateToken(user, TokenTypeAccess, m.config.AccessExpiration)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := m.generateToken(user, TokenTypeRefresh, m.config.RefreshExpiration)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(m.config.AccessExpiration.Seconds()),
+	}, nil
+}
+
+// ParseToken verifies signature, issuer and expiration, returns claims
+// Returns ErrTokenExpiredError if token is expired, ErrTokenInvalidError otherwise
+func (m *TokenManager) ParseToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(m.config.Secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(m.config.Issuer))
+
+	if err != nil {
+		if stderrors.Is(err, jwt.ErrTokenExpired) {
+			return nil, errors.ErrTokenExpiredError
+		}
+		return nil, errors.Wrap(errors.ErrTokenInvalid, "invalid token", err)
+	}
+	if !token.Valid || claims.ID == "" {
+		return nil, errors.ErrTokenInvalidError
+	}
+
+	return claims, nil
+}
+
+// generateToken signs a token of the given type
+func (m *TokenManager) generateToken(user *model.User, tokenType string, ttl time.Duration) (string, error) {
+	tokenID, err := newTokenID()
+	if err != nil {
+		return "", errors.Wrap(errors.ErrInternalServer, "failed to generate token id", err)
+	}
+
+	now := time.Now()
+	claims := Claims{
+		UserID:    user.ID,
+		Username:  user.Username,
+		DeptID:    user.DeptID,
+		TokenType: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			Issuer:    m.config.Issuer,
+			Subject:   strconv.FormatUint(user.ID, 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString([]byte(m.config.Secret))
+	if err != nil {
+		return "", errors.Wrap(errors.ErrInternalServer, "failed to sign token", err)
+	}
+	return signed, nil
+}
+
+// newTokenID generates a random token ID (jti), used as denylist key
+func newTokenID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,96 @@
+package auth_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sword-demon/go-react-admin/internal/pkg/auth"
+	"github.com/sword-demon/go-react-admin/internal/pkg/errors"
+	"github.com/sword-demon/go-react-admin/internal/pkg/model"
+)
+
+// testConfig returns JWT configuration of tests
+func testConfig() *auth.Config {
+	return &auth.Config{
+		Secret:            "test-secret",
+		Issuer:            "go-react-admin",
+		AccessExpiration:  time.Hour,
+		RefreshExpiration: 2 * time.Hour,
+	}
+}
+
+// testUser returns an enabled user
+func testUser() *model.User {
+	return &model.User{BaseModel: model.BaseModel{ID: 7}, Username: "alice", DeptID: 3, Status: model.StatusEnabled}
+}
+
+// signClaims signs claims with secret (HS256)
+func signClaims(t *testing.T, secret string, claims *auth.Claims) string {
+	t.Helper()
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+// TestTokenIssueParse tests issued tokens parse back, and invalid tokens are rejected
+func TestTokenIssueParse(t *testing.T) {
+	tokens := auth.NewTokenManager(testConfig())
+	pair, err := tokens.GenerateTokenPair(testUser())
+	if err != nil {
+		t.Fatalf("GenerateTokenPair failed: %v", err)
+	}
+	if pair.TokenType != "Bearer" || pair.ExpiresIn != 3600 {
+		t.Errorf("pair = %+v, want Bearer, 3600s", pair)
+	}
+
+	for _, tt := range []struct {
+		token     string
+		tokenType string
+	}{
+		{pair.AccessToken, auth.TokenTypeAccess},
+		{pair.RefreshToken, auth.TokenTypeRefresh},
+	} {
+		claims, err := tokens.ParseToken(tt.token)
+		if err != nil {
+			t.Fatalf("ParseToken(%s) failed: %v", tt.tokenType, err)
+		}
+		if claims.UserID != 7 || claims.Username != "alice" || claims.DeptID != 3 || claims.TokenType != tt.tokenType || claims.ID == "" {
+			t.Errorf("claims = %+v, want user 7 alice dept 3 %s with jti", claims, tt.tokenType)
+		}
+	}
+
+	now := time.Now()
+	valid := func(issuer string, expiresAt time.Time) *auth.Claims {
+		return &auth.Claims{
+			UserID:    7,
+			TokenType: auth.TokenTypeAccess,
+			RegisteredClaims: jwt.RegisteredClaims{
+				ID:        "jti",
+				Issuer:    issuer,
+				IssuedAt:  jwt.NewNumericDate(now.Add(-2 * time.Hour)),
+				ExpiresAt: jwt.NewNumericDate(expiresAt),
+			},
+		}
+	}
+	tests := []struct {
+		name  string
+		token string
+		want  errors.ErrorCode
+	}{
+		{"expired", signClaims(t, "test-secret", valid("go-react-admin", now.Add(-time.Hour))), errors.ErrTokenExpired},
+		{"wrong issuer", signClaims(t, "test-secret", valid("someone-else", now.Add(time.Hour))), errors.ErrTokenInvalid},
+		{"no issuer", signClaims(t, "test-secret", valid("", now.Add(time.Hour))), errors.ErrTokenInvalid},
+		{"wrong secret", signClaims(t, "other-secret", valid("go-react-admin", now.Add(time.Hour))), errors.ErrTokenInvalid},
+		{"garbage", "not.a.token", errors.ErrTokenInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tokens.ParseToken(tt.token); !errors.Is(err, tt.want) {
+				t.Errorf("ParseToken error = %v, want code %d", err, tt.want)
+			}
+		})
+	}
+}
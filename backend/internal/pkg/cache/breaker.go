@@ -0,0 +1,226 @@
+// Package cache provides Redis circuit breaker
+package cache
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrCircuitOpen is returned for Redis commands while the circuit breaker is open
+// Callers treat it as L2 miss and fall back to L1 + MySQL
+var ErrCircuitOpen = errors.New("redis circuit breaker is open")
+
+// Circuit breaker states
+const (
+	BreakerClosed   = "closed"    // Redis healthy, all commands pass
+	BreakerOpen     = "open"      // Redis down, commands fail fast with ErrCircuitOpen
+	BreakerHalfOpen = "half_open" // Probing, one command passes to test Redis
+	BreakerDisabled = "disabled"  // No Redis (L1 + MySQL only)
+)
+
+// Circuit breaker defaults
+const (
+	defaultBreakerThreshold   = 5
+	defaultBreakerOpenTimeout = 5 * time.Second
+)
+
+// BreakerStats represents circuit breaker statistics
+type BreakerStats struct {
+	State               string    `json:"state"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	Opens               uint64    `json:"opens"`    // Times breaker opened
+	Rejected            uint64    `json:"rejected"` // Commands skipped while open
+	OpenedAt            time.Time `json:"opened_at,omitempty"`
+}
+
+// CircuitBreaker stops calling Redis after consecutive failures
+// closed → open: threshold consecutive failures (timeouts, connection errors)
+// open → half-open: after openTimeout, one probe command is let through
+// half-open → closed: probe succeeds; half-open → open: probe fails
+//
+// Redis replies (redis.Nil, WRONGTYPE, ...) and canceled contexts are not failures
+type CircuitBreaker struct {
+	mu          sync.Mutex
+	state       string
+	failures    int
+	threshold   int
+	openTimeout time.Duration
+	openedAt    time.Time
+	probing     bool // Half-open probe in flight
+
+	opens    uint64
+	rejected uint64
+}
+
+// NewCircuitBreaker creates a new circuit breaker
+// threshold: consecutive failures before opening (default: 5)
+// openTimeout: time before probing Redis again (default: 5s)
+func NewCircuitBreaker(threshold int, openTimeout time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		threshold = defaultBreakerThreshold
+	}
+	if openTimeout <= 0 {
+		openTimeout = defaultBreakerOpenTimeout
+	}
+	return &CircuitBreaker{
+		state:       BreakerClosed,
+		threshold:   threshold,
+		openTimeout: openTimeout,
+	}
+}
+
+// Allow checks if a command may be sent to Redis
+// Returns ErrCircuitOpen while open or while a half-open probe is in flight
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.openTimeout {
+			b.rejected++
+			return ErrCircuitOpen
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		log.Println("⚠️  Redis circuit breaker half-open, probing Redis")
+		return nil
+	case BreakerHalfOpen:
+		if b.probing {
+			b.rejected++
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Record records the result of a command let through by Allow
+func (b *CircuitBreaker) Record(err error) {
+	if errors.Is(err, context.Canceled) {
+		// Caller gave up, says nothing about Redis health
+		b.mu.Lock()
+		b.probing = false
+		b.mu.Unlock()
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !isRedisFailure(err) {
+		if b.state != BreakerClosed {
+			log.Println("✅ Redis circuit breaker closed, Redis recovered")
+		}
+		b.state = BreakerClosed
+		b.failures = 0
+		b.probing = false
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		if b.state != BreakerOpen {
+			b.opens++
+			log.Printf("⚠️  Redis circuit breaker opened after %d consecutive failures: %v", b.failures, err)
+		}
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+		b.probing = false
+	}
+}
+
+// State returns current breaker state
+func (b *CircuitBreaker) State() string {
+	if b == nil {
+		return BreakerClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Stats returns breaker statistics
+func (b *CircuitBreaker) Stats() BreakerStats {
+	if b == nil {
+		return BreakerStats{State: BreakerClosed}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := BreakerStats{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		Opens:               b.opens,
+		Rejected:            b.rejected,
+	}
+	if b.state != BreakerClosed {
+		stats.OpenedAt = b.openedAt
+	}
+	return stats
+}
+
+// isRedisFailure checks if err means Redis is unreachable or unhealthy
+func isRedisFailure(err error) bool {
+	if err == nil || err == redis.Nil {
+		return false
+	}
+	// Error replies come from a live server
+	var replyErr redis.Error
+	return !errors.As(err, &replyErr)
+}
+
+// breakerHook applies circuit breaker to all commands of a redis.Client
+type breakerHook struct {
+	breaker *CircuitBreaker
+}
+
+// guardedKey marks contexts of commands already let through by the breaker,
+// so nested commands (connection handshake) do not count as separate probes
+type guardedKey struct{}
+
+// DialHook passes dials through (commands are guarded by ProcessHook)
+func (h breakerHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook guards single commands
+func (h breakerHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if ctx.Value(guardedKey{}) != nil {
+			return next(ctx, cmd)
+		}
+		if err := h.breaker.Allow(); err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+		err := next(context.WithValue(ctx, guardedKey{}, true), cmd)
+		h.breaker.Record(err)
+		return err
+	}
+}
+
+// ProcessPipelineHook guards pipelines and transactions
+func (h breakerHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if ctx.Value(guardedKey{}) != nil {
+			return next(ctx, cmds)
+		}
+		if err := h.breaker.Allow(); err != nil {
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+			return err
+		}
+		err := next(context.WithValue(ctx, guardedKey{}, true), cmds)
+		h.breaker.Record(err)
+		return err
+	}
+}
@@ -0,0 +1,117 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sword-demon/go-react-admin/internal/pkg/cache"
+)
+
+// TestCircuitBreakerStates tests closed → open → half-open → closed transitions
+func TestCircuitBreakerStates(t *testing.T) {
+	breaker := cache.NewCircuitBreaker(3, 50*time.Millisecond)
+	failure := errors.New("dial tcp: connection refused")
+
+	// Redis replies and canceled callers are not failures
+	for _, err := range []error{nil, redis.Nil, context.Canceled} {
+		_ = breaker.Allow()
+		breaker.Record(err)
+	}
+	for i := 0; i < 2; i++ {
+		_ = breaker.Allow()
+		breaker.Record(failure)
+	}
+	if state := breaker.State(); state != cache.BreakerClosed {
+		t.Fatalf("state = %s after 2 failures, want closed", state)
+	}
+
+	_ = breaker.Allow()
+	breaker.Record(failure)
+	if state := breaker.State(); state != cache.BreakerOpen {
+		t.Fatalf("state = %s after 3 failures, want open", state)
+	}
+	if err := breaker.Allow(); err != cache.ErrCircuitOpen {
+		t.Fatalf("Allow = %v while open, want ErrCircuitOpen", err)
+	}
+
+	// Half-open: one probe only
+	time.Sleep(60 * time.Millisecond)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("probe rejected: %v", err)
+	}
+	if err := breaker.Allow(); err != cache.ErrCircuitOpen {
+		t.Errorf("second command during probe = %v, want ErrCircuitOpen", err)
+	}
+
+	// Failed probe reopens
+	breaker.Record(failure)
+	if state := breaker.State(); state != cache.BreakerOpen {
+		t.Fatalf("state = %s after failed probe, want open", state)
+	}
+
+	// Successful probe closes
+	time.Sleep(60 * time.Millisecond)
+	_ = breaker.Allow()
+	breaker.Record(nil)
+	stats := breaker.Stats()
+	if stats.State != cache.BreakerClosed || stats.ConsecutiveFailures != 0 {
+		t.Errorf("stats = %+v after successful probe, want closed", stats)
+	}
+	if stats.Opens != 2 || stats.Rejected != 2 {
+		t.Errorf("stats = %+v, want opens=2 rejected=2", stats)
+	}
+}
+
+// TestCircuitBreakerDegradedMode tests Redis outage falls back to L1 + loader
+func TestCircuitBreakerDegradedMode(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := newTestRedis(t, mr).WithBreaker(cache.NewCircuitBreaker(2, 100*time.Millisecond))
+	ctx := context.Background()
+
+	local := cache.NewLocalCache(100, time.Minute)
+	gens := cache.NewGenerations(local, redisClient, nil)
+	tiered := cache.NewTiered(local, redisClient, cache.TieredConfig[string]{})
+	loader := func(ctx context.Context) (string, error) { return "db", nil }
+
+	key, err := gens.Key(ctx, cache.PermissionNamespace, "user:permissions:1")
+	if err != nil {
+		t.Fatalf("Key failed: %v", err)
+	}
+
+	// Redis goes down
+	mr.Close()
+	for i := 0; i < 5; i++ {
+		val, err := tiered.Get(ctx, "k", loader)
+		if err != nil || val != "db" {
+			t.Fatalf("Get = %q, %v, want db", val, err)
+		}
+		local.Delete("k")
+	}
+	stats := redisClient.BreakerStats()
+	if stats.State != cache.BreakerOpen || stats.Rejected == 0 {
+		t.Fatalf("breaker stats = %+v, want open with rejected commands", stats)
+	}
+	if err := redisClient.Set(ctx, "k", "v", time.Minute); !errors.Is(err, cache.ErrCircuitOpen) {
+		t.Errorf("Set while open = %v, want ErrCircuitOpen", err)
+	}
+
+	// Last known generation keeps versioned keys stable
+	local.Clear()
+	if degraded, err := gens.Key(ctx, cache.PermissionNamespace, "user:permissions:1"); err != nil || degraded != key {
+		t.Errorf("Key while open = %q, %v, want %q", degraded, err, key)
+	}
+
+	// Redis recovers, probe closes breaker
+	if err := mr.Restart(); err != nil {
+		t.Fatalf("Restart failed: %v", err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	waitFor(t, func() bool {
+		_ = redisClient.Set(ctx, "k", "v", time.Minute)
+		return redisClient.BreakerStats().State == cache.BreakerClosed
+	}, "breaker did not close after Redis recovered")
+}
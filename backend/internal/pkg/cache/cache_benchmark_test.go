@@ -90,6 +90,48 @@ func BenchmarkLocalCacheConcurrent(b *testing.B) {
 	wg.Wait()
 }
 
+// BenchmarkLocalCacheContention compares one lock against sharded locks
+// under mixed read/write load (90% Get, 10% Set)
+// Run with -cpu 16 to show lock contention: go test -bench LocalCacheContention -cpu 1,4,16
+func BenchmarkLocalCacheContention(b *testing.B) {
+	keys := make([]string, 4096)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("user:permissions:%d", i)
+	}
+	value := []string{"user:read", "user:write"}
+
+	for _, shards := range []int{1, 16} {
+		for _, admission := range []bool{false, true} {
+			name := fmt.Sprintf("shards=%d/admission=%t", shards, admission)
+			b.Run(name, func(b *testing.B) {
+				localCache := cache.NewLocalCacheWithConfig(cache.LocalCacheConfig{
+					MaxEntries: 10000,
+					DefaultTTL: 5 * time.Minute,
+					Shards:     shards,
+					Admission:  admission,
+				})
+				for _, key := range keys {
+					localCache.Set(key, value)
+				}
+
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					i := 0
+					for pb.Next() {
+						key := keys[i&(len(keys)-1)]
+						if i%10 == 0 {
+							localCache.Set(key, value)
+						} else {
+							_, _ = localCache.Get(key)
+						}
+						i++
+					}
+				})
+			})
+		}
+	}
+}
+
 // TestCacheHitRateSimulation simulates real-world usage pattern
 func TestCacheHitRateSimulation(t *testing.T) {
 	localCache := cache.NewLocalCache(1000, 5*time.Minute)
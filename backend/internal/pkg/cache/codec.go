@@ -0,0 +1,68 @@
+// Package cache provides codecs for typed Redis (L2) values
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Codec encodes and decodes typed values stored in Redis (L2)
+type Codec[T any] interface {
+	Encode(value T) ([]byte, error)
+	Decode(data []byte) (T, error)
+}
+
+// JSONCodec stores values as JSON
+type JSONCodec[T any] struct{}
+
+// Encode encodes value as JSON
+func (JSONCodec[T]) Encode(value T) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Decode decodes JSON into a new value
+func (JSONCodec[T]) Decode(data []byte) (T, error) {
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, fmt.Errorf("failed to decode cache value: %w", err)
+	}
+	return value, nil
+}
+
+// StringCodec stores strings as-is
+type StringCodec struct{}
+
+// Encode returns string bytes
+func (StringCodec) Encode(value string) ([]byte, error) {
+	return []byte(value), nil
+}
+
+// Decode returns bytes as string
+func (StringCodec) Decode(data []byte) (string, error) {
+	return string(data), nil
+}
+
+// FuncCodec adapts encode/decode functions to Codec
+// e.g., store []string in Redis but a compiled matcher in L1
+type FuncCodec[T any] struct {
+	EncodeFunc func(value T) ([]byte, error)
+	DecodeFunc func(data []byte) (T, error)
+}
+
+// Encode calls EncodeFunc
+func (c FuncCodec[T]) Encode(value T) ([]byte, error) {
+	return c.EncodeFunc(value)
+}
+
+// Decode calls DecodeFunc
+func (c FuncCodec[T]) Decode(data []byte) (T, error) {
+	return c.DecodeFunc(data)
+}
+
+// CloneSlice returns a shallow copy of a slice (defensive copy for L1 values)
+func CloneSlice[E any](s []E) []E {
+	if s == nil {
+		return nil
+	}
+	return append(make([]E, 0, len(s)), s...)
+}
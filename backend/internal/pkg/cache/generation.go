@@ -0,0 +1,193 @@
+// Package cache provides generation-based (versioned) cache keys
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// GenerationKeyPrefix prefixes Redis keys holding namespace generations
+const GenerationKeyPrefix = "cache:generation:"
+
+// defaultGenerationMirrorTTL bounds how long an instance may use a stale
+// generation if an invalidation event is lost
+const defaultGenerationMirrorTTL = time.Minute
+
+// Generations manages namespace generations for versioned cache keys
+// Keys embed the current generation of their namespace (e.g., user:permissions:1#42),
+// so Bump invalidates a whole namespace in O(1) without deleting keys.
+// Entries of old generations are never read again and age out by TTL
+//
+// Generations are stored in Redis (shared by all instances) and mirrored in L1.
+// Without Redis, generations are kept in process memory
+// A nil *Generations is valid and returns keys unversioned
+type Generations struct {
+	local       *LocalCache
+	redis       *RedisClient
+	invalidator *Invalidator
+	mirrorTTL   time.Duration
+
+	// In-process generations (no Redis)
+	mu       sync.Mutex
+	counters map[string]uint64
+
+	// Last generation read from Redis per namespace, used while Redis is unavailable
+	known sync.Map
+}
+
+// NewGenerations creates a new generation manager
+// invalidator drops L1 mirrors of other instances on Bump (optional)
+func NewGenerations(local *LocalCache, redis *RedisClient, invalidator *Invalidator) *Generations {
+	return &Generations{
+		local:       local,
+		redis:       redis,
+		invalidator: invalidator,
+		mirrorTTL:   defaultGenerationMirrorTTL,
+		counters:    make(map[string]uint64),
+	}
+}
+
+// Key returns key versioned with current generation of namespace
+func (g *Generations) Key(ctx context.Context, namespace, key string) (string, error) {
+	if g == nil {
+		return key, nil
+	}
+	generation, err := g.Current(ctx, namespace)
+	if err != nil {
+		return "", err
+	}
+	return VersionedKey(key, generation), nil
+}
+
+// Current returns current generation of namespace (L1 mirror → Redis)
+func (g *Generations) Current(ctx context.Context, namespace string) (uint64, error) {
+	if g == nil {
+		return 0, nil
+	}
+	if g.redis == nil {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		return g.counters[namespace], nil
+	}
+
+	genKey := GenerationKey(namespace)
+	if val, ok := g.local.Get(genKey); ok {
+		if generation, ok := val.(uint64); ok {
+			return generation, nil
+		}
+	}
+
+	generation, err := g.redis.Client.Get(ctx, genKey).Uint64()
+	if err == redis.Nil {
+		generation, err = g.seed(ctx, genKey)
+	}
+	if err != nil {
+		// Degraded mode: keep using last known generation, so L1 stays usable
+		// (generations cannot be bumped while Redis is down)
+		if known, ok := g.known.Load(namespace); ok {
+			return known.(uint64), nil
+		}
+		return 0, fmt.Errorf("failed to get cache generation: %w", err)
+	}
+
+	g.known.Store(namespace, generation)
+	g.local.SetWithTTL(genKey, generation, g.mirrorTTL)
+	return generation, nil
+}
+
+// Bump increments generation of namespace, invalidating all its keys
+// Returns the new generation
+func (g *Generations) Bump(ctx context.Context, namespace string) (uint64, error) {
+	if g == nil {
+		return 0, nil
+	}
+	if g.redis == nil {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		g.counters[namespace]++
+		return g.counters[namespace], nil
+	}
+
+	genKey := GenerationKey(namespace)
+	var incr *redis.IntCmd
+	_, err := g.redis.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SetNX(ctx, genKey, generationSeed(), 0)
+		incr = pipe.Incr(ctx, genKey)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to bump cache generation: %w", err)
+	}
+
+	generation := uint64(incr.Val())
+	g.known.Store(namespace, generation)
+	g.local.SetWithTTL(genKey, generation, g.mirrorTTL)
+
+	// Other instances re-read generation from Redis
+	return generation, g.invalidator.Publish(ctx, genKey)
+}
+
+// Local reports whether generations are kept in process memory (no Redis)
+// Process-local generations are lost on restart unless persisted (see SnapshotConfig)
+func (g *Generations) Local() bool {
+	return g != nil && g.redis == nil
+}
+
+// Counters returns a copy of process-local generations (nil with Redis)
+func (g *Generations) Counters() map[string]uint64 {
+	if !g.Local() {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	counters := make(map[string]uint64, len(g.counters))
+	for namespace, generation := range g.counters {
+		counters[namespace] = generation
+	}
+	return counters
+}
+
+// RestoreCounters raises process-local generations to at least counters (no-op with Redis)
+// Used by LoadSnapshot, so keys invalidated in a previous run are not read again
+func (g *Generations) RestoreCounters(counters map[string]uint64) {
+	if !g.Local() {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for namespace, generation := range counters {
+		if generation > g.counters[namespace] {
+			g.counters[namespace] = generation
+		}
+	}
+}
+
+// seed initializes a missing generation
+// Seeded from clock, so a lost generation key never reuses old generations
+func (g *Generations) seed(ctx context.Context, genKey string) (uint64, error) {
+	if err := g.redis.Client.SetNX(ctx, genKey, generationSeed(), 0).Err(); err != nil {
+		return 0, err
+	}
+	return g.redis.Client.Get(ctx, genKey).Uint64()
+}
+
+// generationSeed returns initial generation value
+func generationSeed() uint64 {
+	return uint64(time.Now().UnixNano())
+}
+
+// GenerationKey returns Redis key holding generation of namespace
+func GenerationKey(namespace string) string {
+	return GenerationKeyPrefix + namespace
+}
+
+// VersionedKey appends generation to key
+// Suffix keeps key prefixes intact (user:1:profile#3 is still under user:1:)
+func VersionedKey(key string, generation uint64) string {
+	return key + "#" + strconv.FormatUint(generation, 10)
+}
@@ -0,0 +1,110 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/sword-demon/go-react-admin/internal/pkg/cache"
+)
+
+// TestGenerationsLocal tests in-process generations (no Redis)
+func TestGenerationsLocal(t *testing.T) {
+	ctx := context.Background()
+	gens := cache.NewGenerations(cache.NewLocalCache(100, time.Minute), nil, nil)
+
+	before, _ := gens.Key(ctx, "permissions", "user:permissions:1")
+	if before != "user:permissions:1#0" {
+		t.Errorf("Key = %q, want generation 0", before)
+	}
+	if _, err := gens.Bump(ctx, "permissions"); err != nil {
+		t.Fatalf("Bump failed: %v", err)
+	}
+	after, _ := gens.Key(ctx, "permissions", "user:permissions:1")
+	if after == before {
+		t.Error("Bump should change versioned key")
+	}
+
+	// Other namespaces are unaffected
+	if key, _ := gens.Key(ctx, "role:1", "role:permissions:1"); key != "role:permissions:1#0" {
+		t.Errorf("Key = %q, want generation 0", key)
+	}
+
+	// Nil generations return keys unversioned
+	var none *cache.Generations
+	if key, err := none.Key(ctx, "permissions", "k"); err != nil || key != "k" {
+		t.Errorf("nil Key = %q, %v", key, err)
+	}
+}
+
+// TestGenerationsTwoInstances tests Bump is visible to other instances
+func TestGenerationsTwoInstances(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+
+	a := newTestInstance(t, mr.Addr())
+	b := newTestInstance(t, mr.Addr())
+	redisA := newTestRedis(t, mr)
+	redisB := newTestRedis(t, mr)
+	gensA := cache.NewGenerations(a.local, redisA, a.invalidator)
+	gensB := cache.NewGenerations(b.local, redisB, b.invalidator)
+
+	genA, err := gensA.Current(ctx, "permissions")
+	if err != nil {
+		t.Fatalf("Current failed: %v", err)
+	}
+	if genA == 0 {
+		t.Error("generation should be seeded")
+	}
+	if genB, _ := gensB.Current(ctx, "permissions"); genB != genA {
+		t.Errorf("instances disagree on generation: %d != %d", genA, genB)
+	}
+
+	bumped, err := gensA.Bump(ctx, "permissions")
+	if err != nil || bumped != genA+1 {
+		t.Fatalf("Bump = %d, %v, want %d", bumped, err, genA+1)
+	}
+
+	// B drops its mirror and reads new generation from Redis
+	waitFor(t, func() bool {
+		gen, _ := gensB.Current(ctx, "permissions")
+		return gen == bumped
+	}, "instance B did not see new generation")
+}
+
+// TestGenerationsInvalidateTiered tests Bump hides cached values of old generation
+func TestGenerationsInvalidateTiered(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := newTestRedis(t, mr)
+	ctx := context.Background()
+
+	local := cache.NewLocalCache(100, time.Minute)
+	gens := cache.NewGenerations(local, redisClient, nil)
+	tiered := cache.NewTiered(local, redisClient, cache.TieredConfig[string]{})
+
+	version := "v1"
+	get := func() string {
+		key, err := gens.Key(ctx, "permissions", cache.PermissionCacheKey(1))
+		if err != nil {
+			t.Fatalf("Key failed: %v", err)
+		}
+		val, _ := tiered.Get(ctx, key, func(ctx context.Context) (string, error) { return version, nil })
+		return val
+	}
+
+	if got := get(); got != "v1" {
+		t.Fatalf("Get = %q, want v1", got)
+	}
+	version = "v2"
+	if got := get(); got != "v1" {
+		t.Errorf("Get = %q, want cached v1", got)
+	}
+
+	if _, err := gens.Bump(ctx, "permissions"); err != nil {
+		t.Fatalf("Bump failed: %v", err)
+	}
+	if got := get(); got != "v2" {
+		t.Errorf("Get after Bump = %q, want v2", got)
+	}
+}
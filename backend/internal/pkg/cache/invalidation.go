@@ -0,0 +1,218 @@
+// Package cache provides cross-instance L1 cache invalidation over Redis Pub/Sub
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultInvalidationChannel is the Redis channel for L1 invalidation events
+const DefaultInvalidationChannel = "cache:invalidation"
+
+// Reconnect backoff of the subscribe loop
+const (
+	minReconnectDelay = 100 * time.Millisecond
+	maxReconnectDelay = 5 * time.Second
+)
+
+// InvalidationEvent represents an L1 invalidation broadcast to other instances
+type InvalidationEvent struct {
+	InstanceID string   `json:"instance_id"`      // Publisher instance (self-echo is skipped)
+	Keys       []string `json:"keys,omitempty"`   // Exact keys to delete
+	Prefix     string   `json:"prefix,omitempty"` // Key prefix to delete
+}
+
+// Invalidator keeps L1 (LocalCache) consistent across server instances
+// Publisher: Publish/PublishPrefix after deleting L1/L2 locally
+// Subscriber: Start applies events from other instances to local L1
+//
+// A nil *Invalidator is valid and does nothing (single instance / no Redis)
+type Invalidator struct {
+	local      *LocalCache
+	redis      *RedisClient
+	channel    string
+	instanceID string
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	ready  chan struct{} // Closed after first successful subscribe
+	once   sync.Once
+}
+
+// NewInvalidator creates a new invalidator
+// Returns nil if redis is nil (invalidation is local only)
+func NewInvalidator(local *LocalCache, redis *RedisClient) *Invalidator {
+	if redis == nil {
+		return nil
+	}
+	return &Invalidator{
+		local:      local,
+		redis:      redis,
+		channel:    DefaultInvalidationChannel,
+		instanceID: newInstanceID(),
+		ready:      make(chan struct{}),
+	}
+}
+
+// InstanceID returns ID of current instance
+func (i *Invalidator) InstanceID() string {
+	if i == nil {
+		return ""
+	}
+	return i.instanceID
+}
+
+// Start subscribes to invalidation channel in background
+// Reconnects with exponential backoff until Close is called
+func (i *Invalidator) Start(ctx context.Context) {
+	if i == nil {
+		return
+	}
+
+	ctx, i.cancel = context.WithCancel(ctx)
+	i.done = make(chan struct{})
+
+	go func() {
+		defer close(i.done)
+		i.subscribeLoop(ctx)
+	}()
+}
+
+// Ready returns a channel closed after first successful subscription
+func (i *Invalidator) Ready() <-chan struct{} {
+	if i == nil {
+		ready := make(chan struct{})
+		close(ready)
+		return ready
+	}
+	return i.ready
+}
+
+// Close stops the subscribe loop and waits for it to exit
+func (i *Invalidator) Close() {
+	if i == nil || i.cancel == nil {
+		return
+	}
+	i.cancel()
+	<-i.done
+}
+
+// Publish broadcasts exact key invalidation to other instances
+func (i *Invalidator) Publish(ctx context.Context, keys ...string) error {
+	if i == nil || len(keys) == 0 {
+		return nil
+	}
+	return i.publish(ctx, &InvalidationEvent{Keys: keys})
+}
+
+// PublishPrefix broadcasts prefix invalidation to other instances
+func (i *Invalidator) PublishPrefix(ctx context.Context, prefix string) error {
+	if i == nil || prefix == "" {
+		return nil
+	}
+	return i.publish(ctx, &InvalidationEvent{Prefix: prefix})
+}
+
+// publish sends an event tagged with current instance ID
+func (i *Invalidator) publish(ctx context.Context, event *InvalidationEvent) error {
+	event.InstanceID = i.instanceID
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal invalidation event: %w", err)
+	}
+	if err := i.redis.Publish(ctx, i.channel, data).Err(); err != nil {
+		return fmt.Errorf("failed to publish invalidation event: %w", err)
+	}
+	return nil
+}
+
+// subscribeLoop subscribes and applies events, reconnecting on failure
+func (i *Invalidator) subscribeLoop(ctx context.Context) {
+	delay := minReconnectDelay
+	connected := false
+
+	for ctx.Err() == nil {
+		err := i.subscribe(ctx, func() {
+			// Events published while disconnected are lost, drop all L1 entries
+			if connected {
+				i.local.Clear()
+				log.Printf("✅ Cache invalidation resubscribed, local cache cleared")
+			}
+			connected = true
+			delay = minReconnectDelay
+			i.once.Do(func() { close(i.ready) })
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Printf("⚠️  Cache invalidation subscription lost, retry in %v: %v", delay, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+// subscribe runs one subscription until error or ctx done
+func (i *Invalidator) subscribe(ctx context.Context, onSubscribed func()) error {
+	pubsub := i.redis.Subscribe(ctx, i.channel)
+	defer pubsub.Close()
+
+	// ReceiveMessage blocks on connection read, close pubsub to unblock on ctx done
+	stop := context.AfterFunc(ctx, func() { _ = pubsub.Close() })
+	defer stop()
+
+	// Wait for subscription confirmation
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return err
+	}
+	onSubscribed()
+
+	for {
+		msg, err := pubsub.ReceiveMessage(ctx)
+		if err != nil {
+			return err
+		}
+		i.apply(msg.Payload)
+	}
+}
+
+// apply applies an invalidation event to local cache (skips self-echo)
+func (i *Invalidator) apply(payload string) {
+	var event InvalidationEvent
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		log.Printf("⚠️  Invalid cache invalidation event: %v", err)
+		return
+	}
+	if event.InstanceID == i.instanceID {
+		return
+	}
+
+	if len(event.Keys) > 0 {
+		i.local.DeleteKeys(event.Keys...)
+	}
+	if event.Prefix != "" {
+		i.local.DeletePrefix(event.Prefix)
+	}
+}
+
+// newInstanceID generates a unique instance ID (hostname-pid-random)
+func newInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(b))
+}
@@ -0,0 +1,182 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sword-demon/go-react-admin/internal/pkg/cache"
+)
+
+// testInstance represents one server process (own L1, shared L2)
+type testInstance struct {
+	local       *cache.LocalCache
+	invalidator *cache.Invalidator
+	tiered      *cache.ThreeTierCache
+}
+
+// newTestInstance creates an instance connected to miniredis
+func newTestInstance(t *testing.T, addr string) *testInstance {
+	t.Helper()
+
+	redisClient := &cache.RedisClient{Client: redis.NewClient(&redis.Options{Addr: addr})}
+	t.Cleanup(func() { _ = redisClient.Client.Close() })
+
+	local := cache.NewLocalCache(100, time.Minute)
+	invalidator := cache.NewInvalidator(local, redisClient)
+	invalidator.Start(context.Background())
+	t.Cleanup(invalidator.Close)
+
+	select {
+	case <-invalidator.Ready():
+	case <-time.After(2 * time.Second):
+		t.Fatal("invalidator did not subscribe")
+	}
+
+	return &testInstance{
+		local:       local,
+		invalidator: invalidator,
+		tiered:      cache.NewThreeTierCache(local, redisClient).WithInvalidator(invalidator),
+	}
+}
+
+// waitFor polls until cond is true
+func waitFor(t *testing.T, cond func() bool, msg string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal(msg)
+}
+
+// hasKey checks if key is in local cache
+func hasKey(local *cache.LocalCache, key string) bool {
+	_, ok := local.Get(key)
+	return ok
+}
+
+// TestInvalidatorTwoInstances tests L1 consistency between two instances
+func TestInvalidatorTwoInstances(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+
+	a := newTestInstance(t, mr.Addr())
+	b := newTestInstance(t, mr.Addr())
+
+	if a.invalidator.InstanceID() == b.invalidator.InstanceID() {
+		t.Fatal("instances must have different IDs")
+	}
+
+	// Both instances have key in L1
+	key := cache.PermissionCacheKey(1)
+	a.local.Set(key, []string{"user:*"})
+	b.local.Set(key, []string{"user:*"})
+
+	// Delete on A removes key from B's L1
+	if err := a.tiered.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	waitFor(t, func() bool { return !hasKey(b.local, key) }, "key not invalidated on instance B")
+
+	// Prefix delete on B removes keys from A's L1
+	a.local.Set("user:1:profile", "a")
+	a.local.Set("user:1:roles", "a")
+	a.local.Set("user:2:profile", "a")
+	if _, _, err := b.tiered.DeletePrefix(ctx, cache.UserCacheKeyPrefix(1)); err != nil {
+		t.Fatalf("DeletePrefix failed: %v", err)
+	}
+	waitFor(t, func() bool {
+		return !hasKey(a.local, "user:1:profile") && !hasKey(a.local, "user:1:roles")
+	}, "prefix not invalidated on instance A")
+	if !hasKey(a.local, "user:2:profile") {
+		t.Error("key outside prefix should be kept")
+	}
+
+	// Set on A invalidates stale value on B (B reloads from L2)
+	b.local.Set(key, []string{"stale"})
+	if err := a.tiered.Set(ctx, key, []string{"user:read"}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	waitFor(t, func() bool { return !hasKey(b.local, key) }, "stale value not invalidated on instance B")
+	if !hasKey(a.local, key) {
+		t.Error("publisher should keep its own value")
+	}
+}
+
+// TestInvalidatorSkipsSelfEcho tests that instance ignores its own events
+func TestInvalidatorSkipsSelfEcho(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+
+	a := newTestInstance(t, mr.Addr())
+	b := newTestInstance(t, mr.Addr())
+
+	a.local.Set("k", "fresh")
+	b.local.Set("k", "stale")
+	if err := a.invalidator.Publish(ctx, "k"); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	waitFor(t, func() bool { return !hasKey(b.local, "k") }, "key not invalidated on instance B")
+	if !hasKey(a.local, "k") {
+		t.Error("instance should skip its own invalidation event")
+	}
+}
+
+// TestInvalidatorReconnect tests resubscription after Redis restart
+func TestInvalidatorReconnect(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+
+	a := newTestInstance(t, mr.Addr())
+	b := newTestInstance(t, mr.Addr())
+
+	b.local.Set("before", "v")
+
+	// Drop all connections, events may be lost while disconnected
+	mr.Close()
+	if err := mr.Restart(); err != nil {
+		t.Fatalf("Restart failed: %v", err)
+	}
+
+	// B clears L1 after resubscribing (missed events)
+	waitFor(t, func() bool { return !hasKey(b.local, "before") }, "local cache not cleared after reconnect")
+
+	// Events after reconnect are delivered again
+	waitFor(t, func() bool {
+		b.local.Set("after", "v")
+		_ = a.invalidator.Publish(ctx, "after")
+		time.Sleep(20 * time.Millisecond)
+		return !hasKey(b.local, "after")
+	}, "events not delivered after reconnect")
+}
+
+// TestInvalidatorNil tests nil invalidator (no Redis) is a no-op
+func TestInvalidatorNil(t *testing.T) {
+	local := cache.NewLocalCache(10, time.Minute)
+	invalidator := cache.NewInvalidator(local, nil)
+	if invalidator != nil {
+		t.Fatal("invalidator should be nil without Redis")
+	}
+
+	invalidator.Start(context.Background())
+	defer invalidator.Close()
+	if err := invalidator.Publish(context.Background(), "k"); err != nil {
+		t.Errorf("Publish on nil invalidator: %v", err)
+	}
+
+	tiered := cache.NewThreeTierCache(local, nil).WithInvalidator(invalidator)
+	local.Set("k", "v")
+	if err := tiered.Delete(context.Background(), "k"); err != nil {
+		t.Errorf("Delete failed: %v", err)
+	}
+	if hasKey(local, "k") {
+		t.Error("key should be deleted locally")
+	}
+}
@@ -0,0 +1,116 @@
+// Package cache provides stampede protection for cache loads
+package cache
+
+import (
+	"log"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// LoadStats represents loader statistics
+type LoadStats struct {
+	Loads          uint64 `json:"loads"`           // Loader executions
+	Coalesced      uint64 `json:"coalesced"`       // Callers that shared an in-flight load
+	EarlyRefreshes uint64 `json:"early_refreshes"` // Background reloads before expiration
+	RefreshErrors  uint64 `json:"refresh_errors"`  // Failed background reloads
+}
+
+// LoadGroup coalesces concurrent loads of the same key (singleflight)
+// and optionally refreshes hot keys in background before they expire
+// A nil *LoadGroup runs every load directly
+//
+// Early refresh is probabilistic (XFetch): on each hit, refresh starts if
+// remaining TTL <= refreshWindow * -ln(rand), so hot keys are almost
+// always reloaded before expiration while cold keys rarely are
+type LoadGroup struct {
+	group         singleflight.Group
+	refreshWindow time.Duration // 0 disables early refresh
+	refreshing    sync.Map      // Keys with background refresh in flight
+
+	loads          atomic.Uint64
+	coalesced      atomic.Uint64
+	earlyRefreshes atomic.Uint64
+	refreshErrors  atomic.Uint64
+}
+
+// NewLoadGroup creates a new load group
+// refreshWindow: typical early refresh distance before expiration (0 = disabled)
+func NewLoadGroup(refreshWindow time.Duration) *LoadGroup {
+	return &LoadGroup{refreshWindow: refreshWindow}
+}
+
+// Do executes loader once for concurrent callers of the same key
+// All callers receive the result of the single execution
+func (g *LoadGroup) Do(key string, loader func() (interface{}, error)) (interface{}, error) {
+	if g == nil {
+		return loader()
+	}
+
+	executed := false
+	val, err, _ := g.group.Do(key, func() (interface{}, error) {
+		executed = true
+		g.loads.Add(1)
+		return loader()
+	})
+	if !executed {
+		g.coalesced.Add(1)
+	}
+	return val, err
+}
+
+// ShouldRefresh decides if a cached value expiring at expiresAt should be refreshed early
+func (g *LoadGroup) ShouldRefresh(expiresAt time.Time) bool {
+	if g == nil || g.refreshWindow <= 0 || expiresAt.IsZero() {
+		return false
+	}
+	remaining := time.Until(expiresAt)
+	if remaining <= 0 {
+		return true
+	}
+	// -ln(rand) is exponential with mean 1 (1-Float64 avoids ln(0))
+	threshold := float64(g.refreshWindow) * -math.Log(1-rand.Float64())
+	return float64(remaining) <= threshold
+}
+
+// Refresh reloads key in background (coalesced with in-flight loads)
+// loader is responsible for writing the fresh value back to cache
+// No-op if a refresh of the key is already running
+func (g *LoadGroup) Refresh(key string, loader func() (interface{}, error)) {
+	if g == nil {
+		return
+	}
+	if _, running := g.refreshing.LoadOrStore(key, struct{}{}); running {
+		return
+	}
+	g.earlyRefreshes.Add(1)
+
+	go func() {
+		defer g.refreshing.Delete(key)
+		_, err, _ := g.group.Do(key, func() (interface{}, error) {
+			g.loads.Add(1)
+			return loader()
+		})
+		if err != nil {
+			g.refreshErrors.Add(1)
+			log.Printf("⚠️  Cache early refresh failed (key: %s): %v", key, err)
+		}
+	}()
+}
+
+// Stats returns loader statistics
+func (g *LoadGroup) Stats() LoadStats {
+	if g == nil {
+		return LoadStats{}
+	}
+	return LoadStats{
+		Loads:          g.loads.Load(),
+		Coalesced:      g.coalesced.Load(),
+		EarlyRefreshes: g.earlyRefreshes.Load(),
+		RefreshErrors:  g.refreshErrors.Load(),
+	}
+}
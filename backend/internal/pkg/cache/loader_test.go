@@ -0,0 +1,159 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sword-demon/go-react-admin/internal/pkg/cache"
+)
+
+// TestLoadGroupCoalesce tests concurrent loads of the same key run once
+func TestLoadGroupCoalesce(t *testing.T) {
+	group := cache.NewLoadGroup(0)
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	loader := func() (interface{}, error) {
+		calls.Add(1)
+		<-release
+		return []string{"*:*"}, nil
+	}
+
+	const callers = 50
+	var started, wg sync.WaitGroup
+	started.Add(callers)
+	wg.Add(callers)
+	for i := 0; i < callers; i++ {
+		go func() {
+			defer wg.Done()
+			started.Done()
+			val, err := group.Do("user:permissions:1", loader)
+			if err != nil || len(val.([]string)) != 1 {
+				t.Errorf("unexpected result: %v, %v", val, err)
+			}
+		}()
+	}
+
+	// Let all callers join the in-flight load before releasing it
+	started.Wait()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("loader called %d times, want 1", calls.Load())
+	}
+	stats := group.Stats()
+	if stats.Loads != 1 || stats.Coalesced != callers-1 {
+		t.Errorf("stats = %+v, want loads=1 coalesced=%d", stats, callers-1)
+	}
+}
+
+// TestLoadGroupError tests load errors are shared and not cached
+func TestLoadGroupError(t *testing.T) {
+	group := cache.NewLoadGroup(0)
+	loadErr := errors.New("db down")
+
+	if _, err := group.Do("k", func() (interface{}, error) { return nil, loadErr }); err != loadErr {
+		t.Fatalf("err = %v, want %v", err, loadErr)
+	}
+	val, err := group.Do("k", func() (interface{}, error) { return "ok", nil })
+	if err != nil || val != "ok" {
+		t.Errorf("next load = %v, %v, want ok", val, err)
+	}
+}
+
+// TestLoadGroupShouldRefresh tests early refresh decision
+func TestLoadGroupShouldRefresh(t *testing.T) {
+	disabled := cache.NewLoadGroup(0)
+	if disabled.ShouldRefresh(time.Now().Add(time.Millisecond)) {
+		t.Error("disabled group should never refresh")
+	}
+
+	group := cache.NewLoadGroup(time.Second)
+	if !group.ShouldRefresh(time.Now().Add(-time.Second)) {
+		t.Error("expired key should refresh")
+	}
+
+	// Far from expiration: probability e^-300 ≈ 0
+	for i := 0; i < 1000; i++ {
+		if group.ShouldRefresh(time.Now().Add(5 * time.Minute)) {
+			t.Fatal("key far from expiration should not refresh")
+		}
+	}
+
+	// Close to expiration: probability e^-0.001 ≈ 1
+	refreshed := 0
+	for i := 0; i < 1000; i++ {
+		if group.ShouldRefresh(time.Now().Add(time.Millisecond)) {
+			refreshed++
+		}
+	}
+	if refreshed < 950 {
+		t.Errorf("key close to expiration refreshed %d/1000 times", refreshed)
+	}
+}
+
+// TestLoadGroupRefresh tests background refresh runs once per key
+func TestLoadGroupRefresh(t *testing.T) {
+	group := cache.NewLoadGroup(time.Second)
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	done := make(chan struct{})
+	loader := func() (interface{}, error) {
+		calls.Add(1)
+		<-release
+		close(done)
+		return "fresh", nil
+	}
+
+	group.Refresh("k", loader)
+	group.Refresh("k", loader) // Skipped, refresh in flight
+	close(release)
+	<-done
+
+	// Wait for refresh goroutine to finish bookkeeping
+	time.Sleep(10 * time.Millisecond)
+	if calls.Load() != 1 {
+		t.Errorf("loader called %d times, want 1", calls.Load())
+	}
+	if stats := group.Stats(); stats.EarlyRefreshes != 1 || stats.Loads != 1 {
+		t.Errorf("stats = %+v, want early_refreshes=1 loads=1", stats)
+	}
+}
+
+// TestThreeTierCacheStampede tests concurrent misses hit the database once
+func TestThreeTierCacheStampede(t *testing.T) {
+	tiered := cache.NewThreeTierCache(cache.NewLocalCache(100, time.Minute), nil)
+
+	var calls atomic.Int32
+	dbLoader := func() (string, error) {
+		calls.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		return "value", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if val, _, err := tiered.GetString(context.Background(), "hot", dbLoader); err != nil || val != "value" {
+				t.Errorf("GetString = %q, %v", val, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("database loaded %d times, want 1", calls.Load())
+	}
+	if stats := tiered.Stats().Loads; stats.Loads != 1 || stats.Coalesced == 0 {
+		t.Errorf("load stats = %+v, want loads=1 and coalesced > 0", stats)
+	}
+}
@@ -4,27 +4,69 @@ package cache
 import (
 	"container/list"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// Local cache defaults
+const (
+	defaultLocalMaxEntries = 1000
+	defaultLocalTTL        = 5 * time.Minute
+	maxLocalShards         = 256
+	autoLocalShards        = 16
+	minShardEntries        = 256 // Auto sharding keeps at least this many entries per shard
+)
+
+// LocalCacheConfig represents local cache configuration
+type LocalCacheConfig struct {
+	MaxEntries int           // Maximum number of entries (default: 1000)
+	MaxBytes   int64         // Maximum estimated memory in bytes (0 = entry limit only)
+	DefaultTTL time.Duration // Default expiration time (default: 5 minutes)
+	Shards     int           // Lock shards, rounded down to a power of two (0 = auto, by MaxEntries)
+	Sizer      Sizer         // Entry cost estimator (default: EstimateSize)
+	Admission  bool          // TinyLFU admission: new keys must be used more often than the entries they evict
+}
+
 // LocalCache implements a thread-safe LRU cache with TTL support
 // Layer 1 cache: in-memory, 5min TTL, 80%+ hit rate, <1ms latency
+//
+// Keys are spread over shards by hash; each shard has its own lock, LRU list
+// and an equal part of the entry/byte budget, so eviction is LRU per shard
 type LocalCache struct {
-	mu         sync.RWMutex
-	cache      map[string]*cacheEntry
-	lruList    *list.List
+	shards     []*localShard
+	shardMask  uint64
 	maxSize    int
+	maxBytes   int64
 	defaultTTL time.Duration
+	sizer      Sizer
 
 	// Metrics
-	hits   uint64
-	misses uint64
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+	rejected  atomic.Uint64 // Not admitted or larger than shard budget
+}
+
+// localShard is one lock domain of LocalCache
+type localShard struct {
+	mu       sync.Mutex
+	cache    map[string]*cacheEntry
+	lruList  *list.List
+	index    *prefixIndex // Key segment trie for DeletePrefix
+	maxSize  int
+	maxBytes int64 // 0 = unlimited
+	bytes    int64
+	sketch   *frequencySketch // Access frequencies, nil without admission
+
+	expirations uint64 // Expired entries removed (on read or by cleanup)
 }
 
 // cacheEntry represents a cache entry with expiration
 type cacheEntry struct {
 	key       string
+	hash      uint64
 	value     interface{}
+	size      int64
 	expiresAt time.Time
 	element   *list.Element // LRU list element
 }
@@ -33,45 +75,134 @@ type cacheEntry struct {
 // maxSize: maximum number of entries (recommend: 1000-10000)
 // defaultTTL: default expiration time (recommend: 5 minutes)
 func NewLocalCache(maxSize int, defaultTTL time.Duration) *LocalCache {
-	if maxSize <= 0 {
-		maxSize = 1000 // Default to 1000 entries
+	return NewLocalCacheWithConfig(LocalCacheConfig{
+		MaxEntries: maxSize,
+		DefaultTTL: defaultTTL,
+	})
+}
+
+// NewLocalCacheWithConfig creates a new local cache with memory bound, sharding and admission options
+func NewLocalCacheWithConfig(cfg LocalCacheConfig) *LocalCache {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = defaultLocalMaxEntries
+	}
+	if cfg.DefaultTTL <= 0 {
+		cfg.DefaultTTL = defaultLocalTTL
+	}
+	if cfg.MaxBytes < 0 {
+		cfg.MaxBytes = 0
+	}
+	if cfg.Sizer == nil {
+		cfg.Sizer = EstimateSize
 	}
-	if defaultTTL <= 0 {
-		defaultTTL = 5 * time.Minute
+
+	shardCount := shardsFor(cfg.Shards, cfg.MaxEntries)
+	c := &LocalCache{
+		shards:     make([]*localShard, shardCount),
+		shardMask:  uint64(shardCount - 1),
+		maxSize:    cfg.MaxEntries,
+		maxBytes:   cfg.MaxBytes,
+		defaultTTL: cfg.DefaultTTL,
+		sizer:      cfg.Sizer,
+	}
+
+	shardSize := (cfg.MaxEntries + shardCount - 1) / shardCount
+	for i := range c.shards {
+		s := &localShard{
+			cache:    make(map[string]*cacheEntry),
+			lruList:  list.New(),
+			index:    newPrefixIndex(),
+			maxSize:  shardSize,
+			maxBytes: cfg.MaxBytes / int64(shardCount),
+		}
+		if cfg.Admission {
+			s.sketch = newFrequencySketch(shardSize)
+		}
+		c.shards[i] = s
+	}
+	return c
+}
+
+// shardsFor returns shard count: configured value rounded down to a power of two,
+// or auto (up to 16 shards, small caches keep a single exact LRU)
+func shardsFor(configured, maxEntries int) int {
+	if configured > 0 {
+		if configured > maxLocalShards {
+			configured = maxLocalShards
+		}
+		n := 1
+		for n*2 <= configured {
+			n *= 2
+		}
+		return n
 	}
 
-	return &LocalCache{
-		cache:      make(map[string]*cacheEntry, maxSize),
-		lruList:    list.New(),
-		maxSize:    maxSize,
-		defaultTTL: defaultTTL,
+	n := autoLocalShards
+	for n > 1 && maxEntries/n < minShardEntries {
+		n /= 2
 	}
+	return n
+}
+
+// shardFor returns shard owning key and key hash
+func (c *LocalCache) shardFor(key string) (*localShard, uint64) {
+	hash := hashKey(key)
+	return c.shards[hash&c.shardMask], hash
 }
 
 // Get retrieves a value from cache
 // Returns (value, true) if found and not expired
 // Returns (nil, false) if not found or expired
 func (c *LocalCache) Get(key string) (interface{}, bool) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	value, _, ok := c.GetWithExpiry(key)
+	return value, ok
+}
 
-	entry, exists := c.cache[key]
+// GetWithExpiry retrieves a value and its expiration time from cache
+// Used by early refresh to decide if a hot key should be reloaded
+func (c *LocalCache) GetWithExpiry(key string) (interface{}, time.Time, bool) {
+	s, hash := c.shardFor(key)
+
+	s.mu.Lock()
+	entry, ok := s.getEntry(key, hash)
+	var (
+		value     interface{}
+		expiresAt time.Time
+	)
+	if ok {
+		value, expiresAt = entry.value, entry.expiresAt
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		c.misses.Add(1)
+		return nil, time.Time{}, false
+	}
+	c.hits.Add(1)
+	return value, expiresAt, true
+}
+
+// getEntry looks up an entry and updates LRU order and access frequency (must be called with lock held)
+func (s *localShard) getEntry(key string, hash uint64) (*cacheEntry, bool) {
+	if s.sketch != nil {
+		s.sketch.increment(hash)
+	}
+
+	entry, exists := s.cache[key]
 	if !exists {
-		c.misses++
 		return nil, false
 	}
 
 	// Check if expired
 	if time.Now().After(entry.expiresAt) {
-		c.removeEntry(entry)
-		c.misses++
+		s.removeEntry(entry)
+		s.expirations++
 		return nil, false
 	}
 
 	// Move to front (most recently used)
-	c.lruList.MoveToFront(entry.element)
-	c.hits++
-	return entry.value, true
+	s.lruList.MoveToFront(entry.element)
+	return entry, true
 }
 
 // Set stores a value in cache with default TTL
@@ -80,110 +211,229 @@ func (c *LocalCache) Set(key string, value interface{}) {
 }
 
 // SetWithTTL stores a value in cache with custom TTL
+// New keys may be rejected by admission policy, or if larger than shard memory budget
 func (c *LocalCache) SetWithTTL(key string, value interface{}, ttl time.Duration) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	s, hash := c.shardFor(key)
+	size := c.sizer(key, value)
+
+	s.mu.Lock()
+	evicted, admitted := s.set(key, hash, value, size, time.Now().Add(ttl))
+	s.mu.Unlock()
+
+	if evicted > 0 {
+		c.evictions.Add(uint64(evicted))
+	}
+	if !admitted {
+		c.rejected.Add(1)
+	}
+}
+
+// set stores an entry and evicts to fit shard budget (must be called with lock held)
+// Returns number of evicted entries and whether value was stored
+func (s *localShard) set(key string, hash uint64, value interface{}, size int64, expiresAt time.Time) (int, bool) {
+	existing, exists := s.cache[key]
+
+	// Never fits, drop stale value too
+	if s.maxBytes > 0 && size > s.maxBytes {
+		if exists {
+			s.removeEntry(existing)
+		}
+		return 0, false
+	}
 
 	// If key exists, update it
-	if entry, exists := c.cache[key]; exists {
-		entry.value = value
-		entry.expiresAt = time.Now().Add(ttl)
-		c.lruList.MoveToFront(entry.element)
-		return
+	if exists {
+		s.bytes += size - existing.size
+		existing.value = value
+		existing.size = size
+		existing.expiresAt = expiresAt
+		s.lruList.MoveToFront(existing.element)
+		return s.evict(existing), true
 	}
 
-	// Evict least recently used if cache is full
-	if c.lruList.Len() >= c.maxSize {
-		c.evictOldest()
+	// TinyLFU: a full shard only admits keys used more often than its LRU victim
+	if s.sketch != nil && s.full(size) {
+		if oldest := s.lruList.Back(); oldest != nil {
+			victim := oldest.Value.(*cacheEntry)
+			if time.Now().Before(victim.expiresAt) && s.sketch.estimate(hash) <= s.sketch.estimate(victim.hash) {
+				return 0, false
+			}
+		}
 	}
 
-	// Create new entry
+	return s.insert(key, hash, value, size, expiresAt), true
+}
+
+// insert adds a new entry and evicts to fit shard budget (must be called with lock held, key must not exist)
+func (s *localShard) insert(key string, hash uint64, value interface{}, size int64, expiresAt time.Time) int {
 	entry := &cacheEntry{
 		key:       key,
+		hash:      hash,
 		value:     value,
-		expiresAt: time.Now().Add(ttl),
+		size:      size,
+		expiresAt: expiresAt,
 	}
-	entry.element = c.lruList.PushFront(entry)
-	c.cache[key] = entry
+	entry.element = s.lruList.PushFront(entry)
+	s.cache[key] = entry
+	s.index.add(key)
+	s.bytes += size
+	return s.evict(entry)
+}
+
+// full checks if adding an entry of given size requires eviction
+func (s *localShard) full(size int64) bool {
+	return s.lruList.Len() >= s.maxSize || (s.maxBytes > 0 && s.bytes+size > s.maxBytes)
+}
+
+// evict removes least recently used entries until shard fits its budget, never evicting keep
+func (s *localShard) evict(keep *cacheEntry) int {
+	count := 0
+	for s.lruList.Len() > s.maxSize || (s.maxBytes > 0 && s.bytes > s.maxBytes) {
+		oldest := s.lruList.Back()
+		if oldest == nil || oldest == keep.element {
+			break
+		}
+		s.removeEntry(oldest.Value.(*cacheEntry))
+		count++
+	}
+	return count
 }
 
 // Delete removes a key from cache
 func (c *LocalCache) Delete(key string) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	s, _ := c.shardFor(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	if entry, exists := c.cache[key]; exists {
-		c.removeEntry(entry)
+	if entry, exists := s.cache[key]; exists {
+		s.removeEntry(entry)
 	}
 }
 
+// DeleteKeys removes multiple keys, taking each shard lock once
+// Returns number of keys removed
+func (c *LocalCache) DeleteKeys(keys ...string) int {
+	if len(c.shards) == 1 {
+		return c.shards[0].deleteKeys(keys)
+	}
+
+	byShard := make([][]string, len(c.shards))
+	for _, key := range keys {
+		i := hashKey(key) & c.shardMask
+		byShard[i] = append(byShard[i], key)
+	}
+
+	count := 0
+	for i, shardKeys := range byShard {
+		if len(shardKeys) > 0 {
+			count += c.shards[i].deleteKeys(shardKeys)
+		}
+	}
+	return count
+}
+
+// deleteKeys removes keys of this shard under a single lock
+func (s *localShard) deleteKeys(keys []string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, key := range keys {
+		if entry, exists := s.cache[key]; exists {
+			s.removeEntry(entry)
+			count++
+		}
+	}
+	return count
+}
+
 // DeletePrefix removes all keys with the given prefix
 // Useful for invalidating related cache entries
 // Example: DeletePrefix("user:permissions:") clears all user permission cache
+// Uses prefix index, cost is proportional to matching keys rather than cache size
 func (c *LocalCache) DeletePrefix(prefix string) int {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	count := 0
-	for key, entry := range c.cache {
-		if len(key) >= len(prefix) && key[:len(prefix)] == prefix {
-			c.removeEntry(entry)
-			count++
+	for _, s := range c.shards {
+		s.mu.Lock()
+		for _, key := range s.index.keysWithPrefix(prefix) {
+			if entry, exists := s.cache[key]; exists {
+				s.removeEntry(entry)
+				count++
+			}
 		}
+		s.mu.Unlock()
 	}
 	return count
 }
 
 // Clear removes all entries from cache
 func (c *LocalCache) Clear() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	for _, s := range c.shards {
+		s.mu.Lock()
+		s.cache = make(map[string]*cacheEntry)
+		s.lruList.Init()
+		s.index = newPrefixIndex()
+		s.bytes = 0
+		s.expirations = 0
+		if s.sketch != nil {
+			s.sketch.reset()
+		}
+		s.mu.Unlock()
+	}
 
-	c.cache = make(map[string]*cacheEntry, c.maxSize)
-	c.lruList.Init()
-	c.hits = 0
-	c.misses = 0
+	c.hits.Store(0)
+	c.misses.Store(0)
+	c.evictions.Store(0)
+	c.rejected.Store(0)
 }
 
 // Stats returns cache statistics
 func (c *LocalCache) Stats() CacheStats {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	total := c.hits + c.misses
-	hitRate := 0.0
-	if total > 0 {
-		hitRate = float64(c.hits) / float64(total) * 100
+	stats := CacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		MaxSize:   c.maxSize,
+		MaxBytes:  c.maxBytes,
+		Evictions: c.evictions.Load(),
+		Rejected:  c.rejected.Load(),
+		Shards:    len(c.shards),
+	}
+	for _, s := range c.shards {
+		s.mu.Lock()
+		stats.Size += s.lruList.Len()
+		stats.Bytes += s.bytes
+		stats.Expirations += s.expirations
+		s.mu.Unlock()
 	}
 
-	return CacheStats{
-		Hits:    c.hits,
-		Misses:  c.misses,
-		HitRate: hitRate,
-		Size:    c.lruList.Len(),
-		MaxSize: c.maxSize,
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total) * 100
 	}
+	return stats
 }
 
 // CleanupExpired removes expired entries (call periodically in background)
 func (c *LocalCache) CleanupExpired() int {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	now := time.Now()
 	count := 0
 
-	// Iterate from back (least recently used)
-	for e := c.lruList.Back(); e != nil; {
-		entry := e.Value.(*cacheEntry)
-		prev := e.Prev()
+	for _, s := range c.shards {
+		s.mu.Lock()
+		// Iterate from back (least recently used)
+		for e := s.lruList.Back(); e != nil; {
+			entry := e.Value.(*cacheEntry)
+			prev := e.Prev()
+
+			if now.After(entry.expiresAt) {
+				s.removeEntry(entry)
+				s.expirations++
+				count++
+			}
 
-		if now.After(entry.expiresAt) {
-			c.removeEntry(entry)
-			count++
+			e = prev
 		}
-
-		e = prev
+		s.mu.Unlock()
 	}
 
 	return count
@@ -214,26 +464,35 @@ func (c *LocalCache) StartCleanupWorker(interval time.Duration) chan struct{} {
 	return stopCh
 }
 
-// removeEntry removes an entry from cache (must be called with lock held)
-func (c *LocalCache) removeEntry(entry *cacheEntry) {
-	c.lruList.Remove(entry.element)
-	delete(c.cache, entry.key)
+// removeEntry removes an entry from shard (must be called with lock held)
+func (s *localShard) removeEntry(entry *cacheEntry) {
+	s.lruList.Remove(entry.element)
+	delete(s.cache, entry.key)
+	s.index.remove(entry.key)
+	s.bytes -= entry.size
 }
 
-// evictOldest removes the least recently used entry
-func (c *LocalCache) evictOldest() {
-	oldest := c.lruList.Back()
-	if oldest != nil {
-		entry := oldest.Value.(*cacheEntry)
-		c.removeEntry(entry)
+// hashKey returns 64-bit FNV-1a hash of key (inlined, no allocation)
+func hashKey(key string) uint64 {
+	hash := uint64(14695981039346656037)
+	for i := 0; i < len(key); i++ {
+		hash ^= uint64(key[i])
+		hash *= 1099511628211
 	}
+	return hash
 }
 
 // CacheStats represents cache statistics
 type CacheStats struct {
-	Hits    uint64  `json:"hits"`
-	Misses  uint64  `json:"misses"`
-	HitRate float64 `json:"hit_rate"` // Percentage
-	Size    int     `json:"size"`
-	MaxSize int     `json:"max_size"`
-}
\ No newline at end of file
+	Hits        uint64  `json:"hits"`
+	Misses      uint64  `json:"misses"`
+	HitRate     float64 `json:"hit_rate"` // Percentage
+	Size        int     `json:"size"`
+	MaxSize     int     `json:"max_size"`
+	Bytes       int64   `json:"bytes"`     // Estimated memory of cached entries
+	MaxBytes    int64   `json:"max_bytes"` // 0 = unlimited
+	Evictions   uint64  `json:"evictions"`
+	Expirations uint64  `json:"expirations"`
+	Rejected    uint64  `json:"rejected"` // Not admitted or too large
+	Shards      int     `json:"shards"`
+}
@@ -0,0 +1,218 @@
+package cache_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sword-demon/go-react-admin/internal/pkg/cache"
+)
+
+// TestLocalCacheDeletePrefixIndex tests prefix index matches string prefix semantics
+func TestLocalCacheDeletePrefixIndex(t *testing.T) {
+	keys := []string{
+		"user:1",
+		"user:1:profile",
+		"user:1:roles:admin",
+		"user:12:profile",
+		"user:2:profile",
+		"user:permissions:1",
+		"role:1:menus",
+		"plain",
+		"",
+	}
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"user:1:", []string{"user:1:profile", "user:1:roles:admin"}},
+		{"user:1", []string{"user:1", "user:1:profile", "user:1:roles:admin", "user:12:profile"}},
+		{"user:", []string{"user:1", "user:1:profile", "user:1:roles:admin", "user:12:profile", "user:2:profile", "user:permissions:1"}},
+		{"user:p", []string{"user:permissions:1"}},
+		{"pl", []string{"plain"}},
+		{"user:3:", nil},
+		{"", keys},
+	}
+
+	for _, tt := range tests {
+		localCache := cache.NewLocalCache(100, time.Minute)
+		for _, key := range keys {
+			localCache.Set(key, "v")
+		}
+
+		deleted := localCache.DeletePrefix(tt.prefix)
+		if deleted != len(tt.want) {
+			t.Errorf("DeletePrefix(%q) deleted %d keys, want %d", tt.prefix, deleted, len(tt.want))
+		}
+
+		var remaining []string
+		for _, key := range keys {
+			if _, ok := localCache.Get(key); ok {
+				remaining = append(remaining, key)
+			}
+		}
+		if len(remaining)+len(tt.want) != len(keys) {
+			t.Errorf("DeletePrefix(%q) remaining = %v", tt.prefix, remaining)
+		}
+		for _, key := range tt.want {
+			if _, ok := localCache.Get(key); ok {
+				t.Errorf("DeletePrefix(%q) kept %q", tt.prefix, key)
+			}
+		}
+	}
+}
+
+// TestLocalCacheIndexEviction tests evicted and deleted keys leave the index
+func TestLocalCacheIndexEviction(t *testing.T) {
+	localCache := cache.NewLocalCache(2, time.Minute)
+	localCache.Set("user:1:a", "v")
+	localCache.Set("user:1:b", "v")
+	localCache.Set("user:1:c", "v") // Evicts user:1:a
+	localCache.Delete("user:1:b")
+
+	if deleted := localCache.DeletePrefix("user:1:"); deleted != 1 {
+		t.Errorf("DeletePrefix deleted %d keys, want 1", deleted)
+	}
+
+	// Re-added key is indexed again
+	localCache.Set("user:1:a", "v")
+	localCache.Clear()
+	localCache.Set("user:1:b", "v")
+	if deleted := localCache.DeletePrefix("user:1:"); deleted != 1 {
+		t.Errorf("DeletePrefix after Clear deleted %d keys, want 1", deleted)
+	}
+	if size := localCache.Stats().Size; size != 0 {
+		t.Errorf("size = %d, want 0", size)
+	}
+}
+
+// TestLocalCacheMaxBytes tests memory bound evicts by cost and rejects oversized values
+func TestLocalCacheMaxBytes(t *testing.T) {
+	localCache := cache.NewLocalCacheWithConfig(cache.LocalCacheConfig{
+		MaxEntries: 100,
+		MaxBytes:   1000,
+		DefaultTTL: time.Minute,
+		Sizer:      func(key string, value interface{}) int64 { return int64(len(value.(string))) },
+	})
+
+	value := strings.Repeat("x", 200)
+	for i := 0; i < 10; i++ {
+		localCache.Set(fmt.Sprintf("user:permissions:%d", i), value)
+	}
+	stats := localCache.Stats()
+	if stats.Size != 5 || stats.Bytes != 1000 || stats.Evictions != 5 {
+		t.Errorf("stats = %+v, want size=5 bytes=1000 evictions=5", stats)
+	}
+	if _, ok := localCache.Get("user:permissions:9"); !ok {
+		t.Error("most recent key should be cached")
+	}
+
+	// Growing an entry evicts others, never the entry itself
+	localCache.Set("user:permissions:9", strings.Repeat("x", 900))
+	if stats := localCache.Stats(); stats.Size != 1 || stats.Bytes != 900 {
+		t.Errorf("stats = %+v, want size=1 bytes=900", stats)
+	}
+
+	// Larger than budget: rejected, stale value dropped
+	localCache.Set("user:permissions:9", strings.Repeat("x", 1001))
+	if _, ok := localCache.Get("user:permissions:9"); ok {
+		t.Error("oversized value should not be cached")
+	}
+	if stats := localCache.Stats(); stats.Rejected != 1 || stats.Bytes != 0 {
+		t.Errorf("stats = %+v, want rejected=1 bytes=0", stats)
+	}
+}
+
+// TestLocalCacheAdmission tests TinyLFU keeps hot keys when one-off keys fill the cache
+func TestLocalCacheAdmission(t *testing.T) {
+	localCache := cache.NewLocalCacheWithConfig(cache.LocalCacheConfig{
+		MaxEntries: 10,
+		DefaultTTL: time.Minute,
+		Admission:  true,
+	})
+
+	for i := 0; i < 10; i++ {
+		key := fmt.Sprintf("user:permissions:%d", i)
+		localCache.Set(key, []string{"user:read"})
+		for j := 0; j < 5; j++ {
+			localCache.Get(key)
+		}
+	}
+
+	// Scan of one-off keys (miss → load → set)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("user:profile:%d", i)
+		if _, ok := localCache.Get(key); !ok {
+			localCache.Set(key, "v")
+		}
+	}
+	for i := 0; i < 10; i++ {
+		if _, ok := localCache.Get(fmt.Sprintf("user:permissions:%d", i)); !ok {
+			t.Errorf("hot key user:permissions:%d evicted by one-off keys", i)
+		}
+	}
+	if stats := localCache.Stats(); stats.Rejected == 0 {
+		t.Errorf("stats = %+v, want rejected one-off keys", stats)
+	}
+
+	// Key that becomes frequent is admitted
+	for i := 0; i < 20; i++ {
+		localCache.Get("user:profile:rising")
+	}
+	localCache.Set("user:profile:rising", "v")
+	if _, ok := localCache.Get("user:profile:rising"); !ok {
+		t.Error("frequent key should be admitted")
+	}
+}
+
+// TestLocalCacheShards tests sharded cache keeps entry limit and prefix deletion
+func TestLocalCacheShards(t *testing.T) {
+	localCache := cache.NewLocalCacheWithConfig(cache.LocalCacheConfig{
+		MaxEntries: 1600,
+		DefaultTTL: time.Minute,
+		Shards:     20, // Rounded down to 16
+	})
+	for i := 0; i < 5000; i++ {
+		localCache.Set(fmt.Sprintf("user:%d:profile", i), "v")
+	}
+
+	stats := localCache.Stats()
+	if stats.Shards != 16 || stats.Size > 1600 || stats.Size < 1500 {
+		t.Errorf("stats = %+v, want 16 shards and ~1600 entries", stats)
+	}
+	if deleted := localCache.DeletePrefix("user:"); deleted != stats.Size {
+		t.Errorf("DeletePrefix deleted %d keys, want %d", deleted, stats.Size)
+	}
+	if stats := localCache.Stats(); stats.Size != 0 || stats.Bytes != 0 {
+		t.Errorf("stats = %+v, want empty", stats)
+	}
+}
+
+// BenchmarkLocalCacheDeletePrefix tests prefix deletion cost on a large cache
+func BenchmarkLocalCacheDeletePrefix(b *testing.B) {
+	localCache := cache.NewLocalCache(200000, 5*time.Minute)
+	for userID := 0; userID < 20000; userID++ {
+		for entryID := 0; entryID < 5; entryID++ {
+			localCache.Set(fmt.Sprintf("user:%d:cache:%d", userID, entryID), "v")
+		}
+	}
+
+	prefixes := make([]string, 1000)
+	for i := range prefixes {
+		prefixes[i] = fmt.Sprintf("user:%d:", i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		prefix := prefixes[i%len(prefixes)]
+		localCache.DeletePrefix(prefix)
+
+		// Restore deleted keys outside timing
+		b.StopTimer()
+		for entryID := 0; entryID < 5; entryID++ {
+			localCache.Set(prefix+fmt.Sprintf("cache:%d", entryID), "v")
+		}
+		b.StartTimer()
+	}
+}
@@ -0,0 +1,72 @@
+package cache_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sword-demon/go-react-admin/internal/admin/biz/permission"
+)
+
+// permissionRuleSet generates n permission rules (module actions, path wildcards, deny rules)
+func permissionRuleSet(n int) []string {
+	rules := make([]string, 0, n)
+	for i := 0; len(rules) < n; i++ {
+		switch i % 4 {
+		case 0:
+			rules = append(rules, fmt.Sprintf("module%d:read", i))
+		case 1:
+			rules = append(rules, fmt.Sprintf("/api/v1/module%d/*", i))
+		case 2:
+			rules = append(rules, fmt.Sprintf("/api/v1/module%d/:id:GET|PUT", i))
+		case 3:
+			rules = append(rules, fmt.Sprintf("!module%d:delete", i))
+		}
+	}
+	return rules
+}
+
+// permissionRequests are request forms checked by permission middleware
+var permissionRequests = [][]string{
+	{"module0:list:GET", "/api/v1/module0:GET"},
+	{"module1:update:PUT", "/api/v1/module1/42:PUT"},
+	{"module3:delete:DELETE", "/api/v1/module3/42:DELETE"},
+	{"unknown:list:GET", "/api/v1/unknown/42/items:GET"}, // Worst case: no rule matches
+}
+
+// BenchmarkPermissionMatch compares linear rule scan with compiled matcher
+func BenchmarkPermissionMatch(b *testing.B) {
+	for _, size := range []int{1000, 10000} {
+		rules := permissionRuleSet(size)
+
+		b.Run(fmt.Sprintf("Linear-%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = permission.MatchRules(rules, permissionRequests[i%len(permissionRequests)]...)
+			}
+		})
+
+		b.Run(fmt.Sprintf("Compiled-%d", size), func(b *testing.B) {
+			matcher := permission.NewMatcher(rules)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = matcher.Allowed(permissionRequests[i%len(permissionRequests)]...)
+			}
+		})
+	}
+}
+
+// BenchmarkPermissionCompile tests matcher compile cost (paid once per L1 cache miss)
+func BenchmarkPermissionCompile(b *testing.B) {
+	for _, size := range []int{1000, 10000} {
+		rules := permissionRuleSet(size)
+
+		b.Run(fmt.Sprintf("Rules-%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = permission.NewMatcher(rules)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+// Package cache provides key prefix index for local cache
+package cache
+
+import "strings"
+
+// keySeparator separates key segments (e.g., user:123:profile)
+const keySeparator = ":"
+
+// prefixIndex is a trie of key segments used by LocalCache.DeletePrefix
+// Prefix lookup visits only matching keys (plus siblings of the last
+// partial segment) instead of scanning the whole cache
+// Not thread-safe, guarded by LocalCache lock
+type prefixIndex struct {
+	root *indexNode
+}
+
+// indexNode represents one key segment
+type indexNode struct {
+	children map[string]*indexNode
+	key      string // Full key if a key ends at this node
+	terminal bool
+}
+
+// newPrefixIndex creates an empty prefix index
+func newPrefixIndex() *prefixIndex {
+	return &prefixIndex{root: &indexNode{}}
+}
+
+// add inserts a key into the index
+func (idx *prefixIndex) add(key string) {
+	node := idx.root
+	for _, segment := range strings.Split(key, keySeparator) {
+		child, ok := node.children[segment]
+		if !ok {
+			if node.children == nil {
+				node.children = make(map[string]*indexNode)
+			}
+			child = &indexNode{}
+			node.children[segment] = child
+		}
+		node = child
+	}
+	node.key = key
+	node.terminal = true
+}
+
+// remove deletes a key from the index and prunes empty nodes
+func (idx *prefixIndex) remove(key string) {
+	segments := strings.Split(key, keySeparator)
+	path := make([]*indexNode, 0, len(segments)+1)
+
+	node := idx.root
+	path = append(path, node)
+	for _, segment := range segments {
+		child, ok := node.children[segment]
+		if !ok {
+			return
+		}
+		node = child
+		path = append(path, node)
+	}
+	node.terminal = false
+	node.key = ""
+
+	// Prune from leaf to root
+	for i := len(segments) - 1; i >= 0; i-- {
+		child := path[i+1]
+		if child.terminal || len(child.children) > 0 {
+			break
+		}
+		delete(path[i].children, segments[i])
+	}
+}
+
+// keysWithPrefix returns all indexed keys starting with prefix
+func (idx *prefixIndex) keysWithPrefix(prefix string) []string {
+	segments := strings.Split(prefix, keySeparator)
+	full, partial := segments[:len(segments)-1], segments[len(segments)-1]
+
+	// Walk complete segments
+	node := idx.root
+	for _, segment := range full {
+		child, ok := node.children[segment]
+		if !ok {
+			return nil
+		}
+		node = child
+	}
+
+	// Last segment may be partial (e.g., "user:1" matches user:1:* and user:12:*)
+	var keys []string
+	for segment, child := range node.children {
+		if strings.HasPrefix(segment, partial) {
+			keys = child.collect(keys)
+		}
+	}
+	return keys
+}
+
+// collect appends all keys in subtree
+func (n *indexNode) collect(keys []string) []string {
+	if n.terminal {
+		keys = append(keys, n.key)
+	}
+	for _, child := range n.children {
+		keys = child.collect(keys)
+	}
+	return keys
+}
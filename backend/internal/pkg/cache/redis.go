@@ -6,14 +6,29 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math/rand/v2"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// scanBatchSize is the number of keys per SCAN/UNLINK round trip
+const scanBatchSize = 500
+
+// KeySetPrefix prefixes Redis sets that track keys of a group (see KeyGroup)
+const KeySetPrefix = "keyset:"
+
 // RedisClient wraps redis.Client with additional utilities
 type RedisClient struct {
 	*redis.Client
+
+	// keyTracking maintains a Redis set of keys per group (user:123:, role:5:),
+	// so DeleteByPrefix of a group is O(group size) instead of a keyspace SCAN
+	keyTracking bool
+
+	// breaker fails commands fast with ErrCircuitOpen while Redis is down (optional)
+	breaker *CircuitBreaker
 }
 
 // Config represents Redis configuration
@@ -25,6 +40,12 @@ type Config struct {
 	// Connection pool settings
 	PoolSize     int
 	MinIdleConns int
+	// KeyTracking enables group key sets for prefix deletion
+	// All instances sharing Redis must use the same setting
+	KeyTracking bool
+	// Circuit breaker settings (see CircuitBreaker)
+	BreakerThreshold   int
+	BreakerOpenTimeout time.Duration
 }
 
 // InitRedis initializes Redis connection
@@ -50,7 +71,50 @@ func InitRedis(cfg *Config) (*RedisClient, error) {
 	}
 
 	log.Printf("✅ Redis connected successfully: %s:%d (DB: %d)", cfg.Host, cfg.Port, cfg.DB)
-	return &RedisClient{Client: rdb}, nil
+
+	// Fail fast instead of waiting for timeouts if Redis goes down later
+	client := &RedisClient{Client: rdb, keyTracking: cfg.KeyTracking}
+	return client.WithBreaker(NewCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerOpenTimeout)), nil
+}
+
+// WithBreaker guards all commands with circuit breaker
+// Must be called once, before the client is used concurrently
+func (c *RedisClient) WithBreaker(breaker *CircuitBreaker) *RedisClient {
+	c.breaker = breaker
+	c.Client.AddHook(breakerHook{breaker: breaker})
+	return c
+}
+
+// BreakerStats returns circuit breaker statistics
+func (c *RedisClient) BreakerStats() BreakerStats {
+	if c == nil {
+		return BreakerStats{State: BreakerDisabled}
+	}
+	return c.breaker.Stats()
+}
+
+// PoolStats returns connection pool statistics (zero without Redis)
+func (c *RedisClient) PoolStats() RedisStats {
+	if c == nil || c.Client == nil {
+		return RedisStats{}
+	}
+	stats := c.Client.PoolStats()
+	return RedisStats{
+		Hits:         stats.Hits,
+		Misses:       stats.Misses,
+		Timeouts:     stats.Timeouts,
+		WaitCount:    stats.WaitCount,
+		WaitDuration: time.Duration(stats.WaitDurationNs).Seconds(),
+		TotalConns:   stats.TotalConns,
+		IdleConns:    stats.IdleConns,
+		StaleConns:   stats.StaleConns,
+	}
+}
+
+// WithKeyTracking enables or disables group key sets (see Config.KeyTracking)
+func (c *RedisClient) WithKeyTracking(enabled bool) *RedisClient {
+	c.keyTracking = enabled
+	return c
 }
 
 // Close closes the Redis connection
@@ -65,18 +129,39 @@ func (c *RedisClient) Close() error {
 // DefaultConfig returns default Redis configuration
 func DefaultConfig() *Config {
 	return &Config{
-		Host:         "localhost",
-		Port:         6379,
-		Password:     "",
-		DB:           0,
-		PoolSize:     10,
-		MinIdleConns: 5,
+		Host:               "localhost",
+		Port:               6379,
+		Password:           "",
+		DB:                 0,
+		PoolSize:           10,
+		MinIdleConns:       5,
+		BreakerThreshold:   defaultBreakerThreshold,
+		BreakerOpenTimeout: defaultBreakerOpenTimeout,
 	}
 }
 
 // Set sets a key-value pair with expiration
+// With key tracking, grouped keys are also added to their group key set
 func (c *RedisClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
-	return c.Client.Set(ctx, key, value, expiration).Err()
+	group, ok := KeyGroup(key)
+	if !c.keyTracking || !ok {
+		return c.Client.Set(ctx, key, value, expiration).Err()
+	}
+
+	// Key set lives as long as its longest-lived key (EXPIRE NX/GT requires Redis 7)
+	setKey := KeySetKey(group)
+	_, err := c.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, key, value, expiration)
+		pipe.SAdd(ctx, setKey, key)
+		if expiration > 0 {
+			pipe.ExpireNX(ctx, setKey, expiration)
+			pipe.ExpireGT(ctx, setKey, expiration)
+		} else {
+			pipe.Persist(ctx, setKey)
+		}
+		return nil
+	})
+	return err
 }
 
 // Get gets a value by key
@@ -133,18 +218,110 @@ func (c *RedisClient) GetJSON(ctx context.Context, key string, target interface{
 	return json.Unmarshal([]byte(data), target)
 }
 
-// DeleteByPrefix deletes all keys matching the prefix pattern
+// DeleteByPrefix deletes all keys with prefix
+// Uses group key set when key tracking is enabled and prefix is a group (e.g., user:123:),
+// otherwise SCAN + UNLINK in batches (never blocks Redis like KEYS)
 // Returns the number of keys deleted
 func (c *RedisClient) DeleteByPrefix(ctx context.Context, prefix string) (int, error) {
-	keys, err := c.Keys(ctx, prefix+"*")
-	if err != nil {
-		return 0, err
+	if c.keyTracking {
+		if group, ok := KeyGroup(prefix); ok && group == prefix {
+			return c.deleteGroup(ctx, group)
+		}
 	}
-	if len(keys) == 0 {
-		return 0, nil
+	return c.scanDelete(ctx, escapePattern(prefix)+"*")
+}
+
+// scanDelete deletes keys matching pattern using cursor-based SCAN
+func (c *RedisClient) scanDelete(ctx context.Context, pattern string) (int, error) {
+	deleted := 0
+	var cursor uint64
+	for {
+		keys, next, err := c.Client.Scan(ctx, cursor, pattern, scanBatchSize).Result()
+		if err != nil {
+			return deleted, err
+		}
+		if len(keys) > 0 {
+			n, err := c.Client.Unlink(ctx, keys...).Result()
+			if err != nil {
+				return deleted, err
+			}
+			deleted += int(n)
+		}
+		if next == 0 {
+			return deleted, nil
+		}
+		cursor = next
 	}
-	if err := c.Del(ctx, keys...); err != nil {
+}
+
+// deleteGroup deletes all keys tracked in group key set
+// The set is renamed first, so keys written during deletion go to a new set
+func (c *RedisClient) deleteGroup(ctx context.Context, group string) (int, error) {
+	setKey := KeySetKey(group)
+	deletingKey := fmt.Sprintf("%s:deleting:%x", setKey, rand.Uint64())
+	if err := c.Client.Rename(ctx, setKey, deletingKey).Err(); err != nil {
+		if strings.Contains(err.Error(), "no such key") {
+			return 0, nil
+		}
 		return 0, err
 	}
-	return len(keys), nil
+
+	deleted := 0
+	var cursor uint64
+	for {
+		keys, next, err := c.Client.SScan(ctx, deletingKey, cursor, "", scanBatchSize).Result()
+		if err != nil {
+			return deleted, err
+		}
+		if len(keys) > 0 {
+			n, err := c.Client.Unlink(ctx, keys...).Result()
+			if err != nil {
+				return deleted, err
+			}
+			deleted += int(n)
+		}
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+
+	return deleted, c.Client.Unlink(ctx, deletingKey).Err()
+}
+
+// KeyGroup returns the group prefix of a key in "<name>:<id>:..." form
+// e.g., user:123:profile → user:123:, role:5:menus → role:5:
+func KeyGroup(key string) (string, bool) {
+	name := strings.Index(key, keySeparator)
+	if name <= 0 {
+		return "", false
+	}
+	id := strings.Index(key[name+1:], keySeparator)
+	if id <= 0 {
+		return "", false
+	}
+	for _, r := range key[name+1 : name+1+id] {
+		if r < '0' || r > '9' {
+			return "", false
+		}
+	}
+	return key[:name+1+id+1], true
+}
+
+// KeySetKey returns the Redis set key tracking keys of a group
+func KeySetKey(group string) string {
+	return KeySetPrefix + group
+}
+
+// escapePattern escapes glob characters for SCAN MATCH
+func escapePattern(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
 }
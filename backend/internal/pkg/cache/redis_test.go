@@ -0,0 +1,117 @@
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/sword-demon/go-react-admin/internal/pkg/cache"
+)
+
+// TestRedisDeleteByPrefixScan tests SCAN-based prefix deletion across batches
+func TestRedisDeleteByPrefixScan(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := newTestRedis(t, mr)
+	ctx := context.Background()
+
+	// More keys than one SCAN batch
+	for i := 0; i < 1200; i++ {
+		mr.Set(fmt.Sprintf("user:1:cache:%d", i), "v")
+	}
+	mr.Set("user:10:profile", "v")
+	mr.Set("user:2:profile", "v")
+
+	deleted, err := redisClient.DeleteByPrefix(ctx, "user:1:")
+	if err != nil {
+		t.Fatalf("DeleteByPrefix failed: %v", err)
+	}
+	if deleted != 1200 {
+		t.Errorf("deleted %d keys, want 1200", deleted)
+	}
+	if !mr.Exists("user:10:profile") || !mr.Exists("user:2:profile") {
+		t.Error("keys outside prefix should be kept")
+	}
+}
+
+// TestRedisDeleteByPrefixEscape tests glob characters in prefix are literal
+func TestRedisDeleteByPrefixEscape(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := newTestRedis(t, mr)
+
+	mr.Set("menu:[1]:tree", "v")
+	mr.Set("menu:1:tree", "v")
+
+	deleted, err := redisClient.DeleteByPrefix(context.Background(), "menu:[1]:")
+	if err != nil || deleted != 1 {
+		t.Fatalf("DeleteByPrefix = %d, %v, want 1", deleted, err)
+	}
+	if !mr.Exists("menu:1:tree") {
+		t.Error("glob characters should not match other keys")
+	}
+}
+
+// TestRedisKeyTracking tests group key sets
+func TestRedisKeyTracking(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := newTestRedis(t, mr).WithKeyTracking(true)
+	ctx := context.Background()
+
+	_ = redisClient.Set(ctx, "user:1:profile", "v", time.Minute)
+	_ = redisClient.Set(ctx, "user:1:roles", "v", 5*time.Minute)
+	_ = redisClient.Set(ctx, "user:1:menus", "v", time.Minute)
+	_ = redisClient.Set(ctx, "user:2:profile", "v", time.Minute)
+	_ = redisClient.Set(ctx, "user:permissions:1", "v", time.Minute) // Not grouped
+
+	members, err := mr.Members(cache.KeySetKey("user:1:"))
+	if err != nil || len(members) != 3 {
+		t.Fatalf("key set = %v, %v, want 3 members", members, err)
+	}
+	if ttl := mr.TTL(cache.KeySetKey("user:1:")); ttl != 5*time.Minute {
+		t.Errorf("key set TTL = %v, want longest key TTL 5m", ttl)
+	}
+
+	deleted, err := redisClient.DeleteByPrefix(ctx, "user:1:")
+	if err != nil || deleted != 3 {
+		t.Fatalf("DeleteByPrefix = %d, %v, want 3", deleted, err)
+	}
+	if mr.Exists(cache.KeySetKey("user:1:")) {
+		t.Error("key set should be deleted")
+	}
+	if !mr.Exists("user:2:profile") || !mr.Exists("user:permissions:1") {
+		t.Error("keys outside group should be kept")
+	}
+
+	// Empty group
+	if deleted, err := redisClient.DeleteByPrefix(ctx, "user:3:"); err != nil || deleted != 0 {
+		t.Errorf("DeleteByPrefix of empty group = %d, %v", deleted, err)
+	}
+
+	// Non-group prefix falls back to SCAN
+	if deleted, err := redisClient.DeleteByPrefix(ctx, "user:permissions:"); err != nil || deleted != 1 {
+		t.Errorf("DeleteByPrefix of non-group prefix = %d, %v, want 1", deleted, err)
+	}
+}
+
+// TestKeyGroup tests group extraction from keys
+func TestKeyGroup(t *testing.T) {
+	tests := []struct {
+		key   string
+		group string
+		ok    bool
+	}{
+		{"user:123:profile", "user:123:", true},
+		{"role:5:", "role:5:", true},
+		{"user:permissions:1", "", false},
+		{"user:123", "", false},
+		{":1:x", "", false},
+		{"user::x", "", false},
+	}
+	for _, tt := range tests {
+		group, ok := cache.KeyGroup(tt.key)
+		if group != tt.group || ok != tt.ok {
+			t.Errorf("KeyGroup(%q) = %q, %v, want %q, %v", tt.key, group, ok, tt.group, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+// Package cache provides cache entry cost estimation
+package cache
+
+// Entry cost estimates, in bytes
+const (
+	entryOverhead    = 160 // cacheEntry, list element, map slot and prefix index node
+	stringOverhead   = 16  // String header
+	defaultValueSize = 64  // Values of unknown type
+)
+
+// Sizer estimates memory cost of a cache entry in bytes
+type Sizer func(key string, value interface{}) int64
+
+// Sized is implemented by values that report their own memory cost
+// (e.g., compiled permission matchers)
+type Sized interface {
+	CacheSize() int64
+}
+
+// EstimateSize is the default Sizer
+// Counts key, entry overhead and value: Sized values report their own size,
+// strings/bytes/string slices are measured, other values count as a fixed size
+func EstimateSize(key string, value interface{}) int64 {
+	size := int64(entryOverhead + len(key))
+
+	switch v := value.(type) {
+	case Sized:
+		size += v.CacheSize()
+	case string:
+		size += int64(len(v))
+	case []byte:
+		size += int64(len(v))
+	case []string:
+		for _, s := range v {
+			size += int64(stringOverhead + len(s))
+		}
+	default:
+		size += defaultValueSize
+	}
+	return size
+}
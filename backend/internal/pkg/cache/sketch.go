@@ -0,0 +1,95 @@
+// Package cache provides access frequency sketch for TinyLFU admission
+package cache
+
+// Frequency sketch parameters
+const (
+	sketchDepth     = 4  // Hash rows, estimate is the minimum over rows
+	sketchMaxCount  = 15 // Counters saturate, only relative frequency matters
+	sketchWidthMult = 8  // Counters per row per cached entry
+	sketchSampleMul = 10 // Counters are halved every sampleMul*capacity accesses
+)
+
+// sketchSeeds derive independent row indexes from one key hash
+var sketchSeeds = [sketchDepth]uint64{
+	0x9e3779b97f4a7c15,
+	0xbf58476d1ce4e5b9,
+	0x94d049bb133111eb,
+	0xd6e8feb86659fd93,
+}
+
+// frequencySketch is a count-min sketch of recent key access frequency
+// Counters are halved periodically, so keys that were hot long ago lose
+// their advantage and can be replaced (must be used with shard lock held)
+type frequencySketch struct {
+	rows       [sketchDepth][]uint8
+	mask       uint64
+	additions  int
+	sampleSize int
+}
+
+// newFrequencySketch creates a sketch sized for capacity cached entries
+func newFrequencySketch(capacity int) *frequencySketch {
+	width := 64
+	for width < capacity*sketchWidthMult {
+		width *= 2
+	}
+
+	s := &frequencySketch{
+		mask:       uint64(width - 1),
+		sampleSize: capacity * sketchSampleMul,
+	}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+// increment records one access of key hash
+func (s *frequencySketch) increment(hash uint64) {
+	for i := range s.rows {
+		idx := s.index(hash, i)
+		if s.rows[i][idx] < sketchMaxCount {
+			s.rows[i][idx]++
+		}
+	}
+
+	s.additions++
+	if s.additions >= s.sampleSize {
+		s.age()
+	}
+}
+
+// estimate returns approximate access count of key hash
+func (s *frequencySketch) estimate(hash uint64) uint8 {
+	count := uint8(sketchMaxCount)
+	for i := range s.rows {
+		if c := s.rows[i][s.index(hash, i)]; c < count {
+			count = c
+		}
+	}
+	return count
+}
+
+// age halves all counters
+func (s *frequencySketch) age() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.additions /= 2
+}
+
+// reset clears all counters
+func (s *frequencySketch) reset() {
+	for i := range s.rows {
+		clear(s.rows[i])
+	}
+	s.additions = 0
+}
+
+// index returns counter index of key hash in row
+func (s *frequencySketch) index(hash uint64, row int) uint64 {
+	h := (hash ^ sketchSeeds[row]) * 0xff51afd7ed558ccd
+	return (h ^ h>>32) & s.mask
+}
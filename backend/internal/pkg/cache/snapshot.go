@@ -0,0 +1,435 @@
+// Package cache provides persisted local cache (L1) snapshots
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSnapshotDiscarded is returned by LoadSnapshot for corrupt, incompatible or too old snapshots
+var ErrSnapshotDiscarded = errors.New("cache snapshot discarded")
+
+// snapshotMagic identifies L1 snapshot files
+const snapshotMagic = "GRAL1SNP"
+
+// snapshotFormat is the file format version, bump on layout changes
+// 2: process-local generations section before entries
+const snapshotFormat uint32 = 2
+
+// snapshotHeaderSize: magic, format, type fingerprint, created at, entry count, payload length, payload CRC32C
+const snapshotHeaderSize = len(snapshotMagic) + 4 + 4 + 8 + 4 + 8 + 4
+
+// crcTable is the CRC32 (Castagnoli) table of snapshot checksums
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// SnapshotConfig represents L1 snapshot options
+type SnapshotConfig struct {
+	Path   string        // Snapshot file
+	MaxAge time.Duration // Older snapshots are discarded (0 = no limit)
+
+	// Generations versioning cached keys (optional)
+	// Process-local generations (no Redis) are saved with the entries and restored
+	// before them, otherwise keys invalidated by a bump would be served again after restart
+	Generations *Generations
+}
+
+// SnapshotStats represents the result of saving or loading a snapshot
+type SnapshotStats struct {
+	Entries  int           `json:"entries"` // Entries written or restored
+	Skipped  int           `json:"skipped"` // Unregistered types, expired, unknown types or not restored
+	Bytes    int64         `json:"bytes"`   // File size
+	Duration time.Duration `json:"duration_ms"`
+}
+
+// snapshotType encodes values of one registered type
+type snapshotType struct {
+	name   string
+	encode func(value interface{}) ([]byte, error)
+	decode func(data []byte) (interface{}, error)
+}
+
+// snapshotTypes is the registry of snapshot types, by value type and by name
+var snapshotTypes = struct {
+	sync.RWMutex
+	byType map[reflect.Type]*snapshotType
+	byName map[string]*snapshotType
+}{
+	byType: make(map[reflect.Type]*snapshotType),
+	byName: make(map[string]*snapshotType),
+}
+
+// RegisterSnapshotType registers values of type T for L1 snapshots
+// name identifies the encoding in snapshot files; change it (e.g., "matcher.v2")
+// when the encoding changes, snapshots written with other type sets are discarded
+// Values of unregistered types are not persisted
+func RegisterSnapshotType[T any](name string, codec Codec[T]) {
+	t := &snapshotType{
+		name: name,
+		encode: func(value interface{}) ([]byte, error) {
+			return codec.Encode(value.(T))
+		},
+		decode: func(data []byte) (interface{}, error) {
+			return codec.Decode(data)
+		},
+	}
+
+	snapshotTypes.Lock()
+	defer snapshotTypes.Unlock()
+	if old, ok := snapshotTypes.byType[reflect.TypeFor[T]()]; ok {
+		delete(snapshotTypes.byName, old.name)
+	}
+	snapshotTypes.byType[reflect.TypeFor[T]()] = t
+	snapshotTypes.byName[name] = t
+}
+
+// Built-in snapshot types (ThreeTierCache values, role permissions, negative entries)
+func init() {
+	RegisterSnapshotType[string]("string", StringCodec{})
+	RegisterSnapshotType("bytes", FuncCodec[[]byte]{
+		EncodeFunc: func(value []byte) ([]byte, error) { return value, nil },
+		DecodeFunc: func(data []byte) ([]byte, error) { return CloneSlice(data), nil },
+	})
+	RegisterSnapshotType[[]string]("strings", JSONCodec[[]string]{})
+	RegisterSnapshotType("negative", FuncCodec[negativeEntry]{
+		EncodeFunc: func(negativeEntry) ([]byte, error) { return nil, nil },
+		DecodeFunc: func([]byte) (negativeEntry, error) { return negativeEntry{}, nil },
+	})
+}
+
+// snapshotFingerprint returns a checksum of registered type names
+// Snapshots of a different type set are incompatible
+func snapshotFingerprint() uint32 {
+	snapshotTypes.RLock()
+	names := make([]string, 0, len(snapshotTypes.byName))
+	for name := range snapshotTypes.byName {
+		names = append(names, name)
+	}
+	snapshotTypes.RUnlock()
+
+	sort.Strings(names)
+	hash := crc32.New(crcTable)
+	for _, name := range names {
+		hash.Write([]byte(name))
+		hash.Write([]byte{0})
+	}
+	return hash.Sum32()
+}
+
+// snapshotEntry is an entry copied out of a shard
+type snapshotEntry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+// SaveSnapshot writes non-expired entries of registered types to cfg.Path
+// Call on graceful shutdown; the file is replaced atomically (temp file + rename)
+//
+// Entries are written least recently used first per shard, so a restore keeps LRU order
+func (c *LocalCache) SaveSnapshot(cfg SnapshotConfig) (*SnapshotStats, error) {
+	startTime := time.Now()
+	stats := &SnapshotStats{}
+
+	// Process-local generations first: a flag and the counters (read before copying
+	// entries, so no entry is newer than the saved generations)
+	var payload bytes.Buffer
+	writeSnapshotGenerations(&payload, cfg.Generations)
+
+	// Copy entries out of shards, encode without holding locks (cached values are immutable)
+	var entries []snapshotEntry
+	for _, s := range c.shards {
+		s.mu.Lock()
+		for e := s.lruList.Back(); e != nil; e = e.Prev() {
+			entry := e.Value.(*cacheEntry)
+			if startTime.After(entry.expiresAt) {
+				stats.Skipped++
+				continue
+			}
+			entries = append(entries, snapshotEntry{key: entry.key, value: entry.value, expiresAt: entry.expiresAt})
+		}
+		s.mu.Unlock()
+	}
+
+	snapshotTypes.RLock()
+	for _, entry := range entries {
+		t, ok := snapshotTypes.byType[reflect.TypeOf(entry.value)]
+		if !ok {
+			stats.Skipped++
+			continue
+		}
+		data, err := t.encode(entry.value)
+		if err != nil {
+			stats.Skipped++
+			continue
+		}
+		writeSnapshotString(&payload, entry.key)
+		writeSnapshotString(&payload, t.name)
+		payload.Write(binary.BigEndian.AppendUint64(nil, uint64(entry.expiresAt.UnixNano())))
+		writeSnapshotString(&payload, string(data))
+		stats.Entries++
+	}
+	snapshotTypes.RUnlock()
+
+	header := make([]byte, 0, snapshotHeaderSize)
+	header = append(header, snapshotMagic...)
+	header = binary.BigEndian.AppendUint32(header, snapshotFormat)
+	header = binary.BigEndian.AppendUint32(header, snapshotFingerprint())
+	header = binary.BigEndian.AppendUint64(header, uint64(startTime.UnixNano()))
+	header = binary.BigEndian.AppendUint32(header, uint32(stats.Entries))
+	header = binary.BigEndian.AppendUint64(header, uint64(payload.Len()))
+	header = binary.BigEndian.AppendUint32(header, crc32.Checksum(payload.Bytes(), crcTable))
+
+	if err := writeFileAtomic(cfg.Path, header, payload.Bytes()); err != nil {
+		return nil, err
+	}
+
+	stats.Bytes = int64(len(header) + payload.Len())
+	stats.Duration = time.Since(startTime)
+	return stats, nil
+}
+
+// LoadSnapshot restores entries from cfg.Path with their remaining TTL
+// Call on startup before serving traffic. Missing file is not an error (stats are empty)
+// Corrupt, incompatible (format or registered types) and too old snapshots return ErrSnapshotDiscarded
+//
+// The file is removed after reading, so a crash later cannot restore stale entries.
+// Restored entries missed invalidations published while the instance was down:
+//   - Redis generations: versioned keys stay correct (bumps are visible in Redis)
+//   - Process-local generations: counters saved with the snapshot are restored into
+//     cfg.Generations first, so entries of bumped generations are never read again.
+//     Versioned keys of snapshots saved without local generations are skipped
+//   - Unversioned keys may be stale until they expire
+func (c *LocalCache) LoadSnapshot(cfg SnapshotConfig) (*SnapshotStats, error) {
+	startTime := time.Now()
+	stats := &SnapshotStats{}
+
+	data, err := os.ReadFile(cfg.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache snapshot: %w", err)
+	}
+	_ = os.Remove(cfg.Path)
+	stats.Bytes = int64(len(data))
+
+	payload, count, err := checkSnapshotHeader(data, cfg.MaxAge, startTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse all entries first, a malformed payload restores nothing
+	type restoredEntry struct {
+		key       string
+		name      string
+		expiresAt time.Time
+		data      string
+	}
+	r := bytes.NewReader(payload)
+	hasGenerations, counters, err := readSnapshotGenerations(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed generations: %v", ErrSnapshotDiscarded, err)
+	}
+	restored := make([]restoredEntry, 0, count)
+	for range count {
+		key, err1 := readSnapshotString(r)
+		name, err2 := readSnapshotString(r)
+		var expiresAt uint64
+		err3 := binary.Read(r, binary.BigEndian, &expiresAt)
+		data, err4 := readSnapshotString(r)
+		if err := errors.Join(err1, err2, err3, err4); err != nil {
+			return nil, fmt.Errorf("%w: malformed entry: %v", ErrSnapshotDiscarded, err)
+		}
+		restored = append(restored, restoredEntry{key: key, name: name, expiresAt: time.Unix(0, int64(expiresAt)), data: data})
+	}
+
+	// Generations before entries: a versioned key is reachable only if current
+	cfg.Generations.RestoreCounters(counters)
+	skipVersioned := cfg.Generations.Local() && !hasGenerations
+
+	snapshotTypes.RLock()
+	defer snapshotTypes.RUnlock()
+	for _, entry := range restored {
+		t, ok := snapshotTypes.byName[entry.name]
+		if !ok || !startTime.Before(entry.expiresAt) || (skipVersioned && isVersionedKey(entry.key)) {
+			stats.Skipped++
+			continue
+		}
+		value, err := t.decode([]byte(entry.data))
+		if err != nil || !c.restore(entry.key, value, entry.expiresAt) {
+			stats.Skipped++
+			continue
+		}
+		stats.Entries++
+	}
+
+	stats.Duration = time.Since(startTime)
+	return stats, nil
+}
+
+// restore stores a snapshot entry, bypassing admission (live values win)
+// Returns false if key exists or value is larger than shard budget
+func (c *LocalCache) restore(key string, value interface{}, expiresAt time.Time) bool {
+	s, hash := c.shardFor(key)
+	size := c.sizer(key, value)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.cache[key]; exists || (s.maxBytes > 0 && size > s.maxBytes) {
+		return false
+	}
+	if evicted := s.insert(key, hash, value, size, expiresAt); evicted > 0 {
+		c.evictions.Add(uint64(evicted))
+	}
+	return true
+}
+
+// checkSnapshotHeader validates header and checksum, returns payload and entry count
+func checkSnapshotHeader(data []byte, maxAge time.Duration, now time.Time) ([]byte, int, error) {
+	if len(data) < snapshotHeaderSize || string(data[:len(snapshotMagic)]) != snapshotMagic {
+		return nil, 0, fmt.Errorf("%w: not a cache snapshot", ErrSnapshotDiscarded)
+	}
+	header := data[len(snapshotMagic):snapshotHeaderSize]
+
+	if format := binary.BigEndian.Uint32(header[0:4]); format != snapshotFormat {
+		return nil, 0, fmt.Errorf("%w: format version %d, want %d", ErrSnapshotDiscarded, format, snapshotFormat)
+	}
+	if binary.BigEndian.Uint32(header[4:8]) != snapshotFingerprint() {
+		return nil, 0, fmt.Errorf("%w: registered types changed", ErrSnapshotDiscarded)
+	}
+	createdAt := time.Unix(0, int64(binary.BigEndian.Uint64(header[8:16])))
+	if maxAge > 0 && now.Sub(createdAt) > maxAge {
+		return nil, 0, fmt.Errorf("%w: created %v ago, max age %v", ErrSnapshotDiscarded, now.Sub(createdAt).Round(time.Second), maxAge)
+	}
+
+	count := binary.BigEndian.Uint32(header[16:20])
+	length := binary.BigEndian.Uint64(header[20:28])
+	payload := data[snapshotHeaderSize:]
+	if uint64(len(payload)) != length || crc32.Checksum(payload, crcTable) != binary.BigEndian.Uint32(header[28:32]) {
+		return nil, 0, fmt.Errorf("%w: checksum mismatch", ErrSnapshotDiscarded)
+	}
+	return payload, int(count), nil
+}
+
+// writeSnapshotGenerations writes process-local generations: flag, count, (namespace, generation)...
+func writeSnapshotGenerations(buf *bytes.Buffer, generations *Generations) {
+	if !generations.Local() {
+		buf.WriteByte(0)
+		return
+	}
+	counters := generations.Counters()
+	namespaces := make([]string, 0, len(counters))
+	for namespace := range counters {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	buf.WriteByte(1)
+	buf.Write(binary.AppendUvarint(nil, uint64(len(namespaces))))
+	for _, namespace := range namespaces {
+		writeSnapshotString(buf, namespace)
+		buf.Write(binary.BigEndian.AppendUint64(nil, counters[namespace]))
+	}
+}
+
+// readSnapshotGenerations reads the section written by writeSnapshotGenerations
+// Returns false if the snapshot was saved without process-local generations
+func readSnapshotGenerations(r *bytes.Reader) (bool, map[string]uint64, error) {
+	flag, err := r.ReadByte()
+	if err != nil || flag == 0 {
+		return false, nil, err
+	}
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return false, nil, err
+	}
+	if n > uint64(r.Len()) {
+		return false, nil, io.ErrUnexpectedEOF
+	}
+	counters := make(map[string]uint64, n)
+	for range n {
+		namespace, err := readSnapshotString(r)
+		if err != nil {
+			return false, nil, err
+		}
+		var generation uint64
+		if err := binary.Read(r, binary.BigEndian, &generation); err != nil {
+			return false, nil, err
+		}
+		counters[namespace] = generation
+	}
+	return true, counters, nil
+}
+
+// isVersionedKey checks if key has a generation suffix (see VersionedKey)
+func isVersionedKey(key string) bool {
+	i := strings.LastIndexByte(key, '#')
+	if i < 0 || i == len(key)-1 {
+		return false
+	}
+	for _, r := range key[i+1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// writeSnapshotString writes a length-prefixed string
+func writeSnapshotString(buf *bytes.Buffer, s string) {
+	buf.Write(binary.AppendUvarint(nil, uint64(len(s))))
+	buf.WriteString(s)
+}
+
+// readSnapshotString reads a length-prefixed string
+func readSnapshotString(r *bytes.Reader) (string, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", err
+	}
+	if n > uint64(r.Len()) {
+		return "", io.ErrUnexpectedEOF
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// writeFileAtomic writes header and payload to a temp file and renames it to path
+func writeFileAtomic(path string, header, payload []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	f, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create cache snapshot: %w", err)
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // No-op after rename
+
+	_, err1 := f.Write(header)
+	_, err2 := f.Write(payload)
+	if err := errors.Join(err1, err2, f.Sync(), f.Close()); err != nil {
+		return fmt.Errorf("failed to write cache snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write cache snapshot: %w", err)
+	}
+	return nil
+}
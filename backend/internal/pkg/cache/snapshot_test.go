@@ -0,0 +1,177 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sword-demon/go-react-admin/internal/pkg/cache"
+)
+
+// snapshotUser is a custom type registered for snapshots
+type snapshotUser struct {
+	ID   uint64 `json:"id"`
+	Name string `json:"name"`
+}
+
+// unregisteredValue is not registered, never persisted
+type unregisteredValue struct{}
+
+// TestLocalCacheSnapshot tests snapshot round trip, remaining TTL and discarded snapshots
+func TestLocalCacheSnapshot(t *testing.T) {
+	cache.RegisterSnapshotType("test.user", cache.JSONCodec[snapshotUser]{})
+	cfg := cache.SnapshotConfig{Path: filepath.Join(t.TempDir(), "l1.snapshot"), MaxAge: time.Minute}
+
+	source := cache.NewLocalCache(100, time.Minute)
+	source.Set("string", "value")
+	source.Set("strings", []string{"user:read", "!user:delete"})
+	source.Set("user", snapshotUser{ID: 1, Name: "admin"})
+	source.SetWithTTL("short", "value", 50*time.Millisecond)
+	source.Set("unregistered", unregisteredValue{})
+
+	saved, err := source.SaveSnapshot(cfg)
+	if err != nil {
+		t.Fatalf("SaveSnapshot failed: %v", err)
+	}
+	if saved.Entries != 4 || saved.Skipped != 1 {
+		t.Errorf("saved = %+v, want 4 entries, 1 skipped", saved)
+	}
+
+	// "short" expires before restore
+	time.Sleep(60 * time.Millisecond)
+
+	restored := cache.NewLocalCache(100, time.Minute)
+	loaded, err := restored.LoadSnapshot(cfg)
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	if loaded.Entries != 3 || loaded.Skipped != 1 {
+		t.Errorf("loaded = %+v, want 3 entries, 1 skipped", loaded)
+	}
+
+	if val, ok := restored.Get("string"); !ok || val != "value" {
+		t.Errorf("string = %v, %v", val, ok)
+	}
+	if val, ok := restored.Get("strings"); !ok || len(val.([]string)) != 2 || val.([]string)[1] != "!user:delete" {
+		t.Errorf("strings = %v, %v", val, ok)
+	}
+	if val, ok := restored.Get("user"); !ok || val.(snapshotUser).Name != "admin" {
+		t.Errorf("user = %v, %v", val, ok)
+	}
+	if _, ok := restored.Get("short"); ok {
+		t.Error("expired entry should not be restored")
+	}
+
+	// Remaining TTL, not a fresh default TTL
+	_, expiresAt, _ := restored.GetWithExpiry("string")
+	_, originalExpiresAt, _ := source.GetWithExpiry("string")
+	if !expiresAt.Equal(originalExpiresAt) {
+		t.Errorf("expiresAt = %v, want %v", expiresAt, originalExpiresAt)
+	}
+
+	// Snapshot is consumed
+	if _, err := os.Stat(cfg.Path); !os.IsNotExist(err) {
+		t.Errorf("snapshot file should be removed after load, stat error: %v", err)
+	}
+	if loaded, err := restored.LoadSnapshot(cfg); err != nil || loaded.Entries != 0 {
+		t.Errorf("LoadSnapshot without file = %+v, %v, want empty", loaded, err)
+	}
+
+	// Corrupt payload is discarded
+	if _, err := source.SaveSnapshot(cfg); err != nil {
+		t.Fatalf("SaveSnapshot failed: %v", err)
+	}
+	data, _ := os.ReadFile(cfg.Path)
+	data[len(data)-1] ^= 0xff
+	if err := os.WriteFile(cfg.Path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	empty := cache.NewLocalCache(100, time.Minute)
+	if _, err := empty.LoadSnapshot(cfg); !errors.Is(err, cache.ErrSnapshotDiscarded) {
+		t.Errorf("corrupt snapshot error = %v, want ErrSnapshotDiscarded", err)
+	}
+	if empty.Stats().Size != 0 {
+		t.Error("corrupt snapshot should restore nothing")
+	}
+
+	// Too old snapshot is discarded
+	if _, err := source.SaveSnapshot(cfg); err != nil {
+		t.Fatalf("SaveSnapshot failed: %v", err)
+	}
+	if _, err := empty.LoadSnapshot(cache.SnapshotConfig{Path: cfg.Path, MaxAge: time.Nanosecond}); !errors.Is(err, cache.ErrSnapshotDiscarded) {
+		t.Errorf("old snapshot error = %v, want ErrSnapshotDiscarded", err)
+	}
+
+	// Snapshot of another type set is discarded
+	if _, err := source.SaveSnapshot(cfg); err != nil {
+		t.Fatalf("SaveSnapshot failed: %v", err)
+	}
+	cache.RegisterSnapshotType("test.user.v2", cache.JSONCodec[snapshotUser]{})
+	if _, err := empty.LoadSnapshot(cfg); !errors.Is(err, cache.ErrSnapshotDiscarded) {
+		t.Errorf("incompatible snapshot error = %v, want ErrSnapshotDiscarded", err)
+	}
+}
+
+// TestSnapshotGenerations tests a restart with process-local generations never serves bumped keys
+func TestSnapshotGenerations(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "l1.snapshot")
+
+	// Run 1: cache under generation 0, bump, cache again under generation 1
+	source := cache.NewLocalCache(100, time.Minute)
+	gens := cache.NewGenerations(source, nil, nil)
+	oldKey, _ := gens.Key(ctx, "permission", "user:permissions:1")
+	source.Set(oldKey, "old")
+	if _, err := gens.Bump(ctx, "permission"); err != nil {
+		t.Fatalf("Bump failed: %v", err)
+	}
+	newKey, _ := gens.Key(ctx, "permission", "user:permissions:1")
+	source.Set(newKey, "new")
+	source.Set("unversioned", "value")
+	if _, err := source.SaveSnapshot(cache.SnapshotConfig{Path: path, Generations: gens}); err != nil {
+		t.Fatalf("SaveSnapshot failed: %v", err)
+	}
+
+	// Run 2: fresh cache and generations
+	restored := cache.NewLocalCache(100, time.Minute)
+	restoredGens := cache.NewGenerations(restored, nil, nil)
+	if _, err := restored.LoadSnapshot(cache.SnapshotConfig{Path: path, Generations: restoredGens}); err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	key, _ := restoredGens.Key(ctx, "permission", "user:permissions:1")
+	if key != newKey {
+		t.Errorf("key after restart = %s, want %s", key, newKey)
+	}
+	if val, ok := restored.Get(key); !ok || val != "new" {
+		t.Errorf("value after restart = %v, %v, want new", val, ok)
+	}
+
+	// Bump after restart moves past generations of the previous run
+	if _, err := restoredGens.Bump(ctx, "permission"); err != nil {
+		t.Fatalf("Bump failed: %v", err)
+	}
+	for _, stale := range []string{oldKey, newKey} {
+		if key, _ := restoredGens.Key(ctx, "permission", "user:permissions:1"); key == stale {
+			t.Errorf("key after bump = %s, reuses a generation of the previous run", key)
+		}
+	}
+
+	// Snapshot saved without generations: versioned keys are skipped, unversioned restored
+	if _, err := source.SaveSnapshot(cache.SnapshotConfig{Path: path}); err != nil {
+		t.Fatalf("SaveSnapshot failed: %v", err)
+	}
+	fresh := cache.NewLocalCache(100, time.Minute)
+	loaded, err := fresh.LoadSnapshot(cache.SnapshotConfig{Path: path, Generations: cache.NewGenerations(fresh, nil, nil)})
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	if _, ok := fresh.Get(oldKey); ok {
+		t.Error("versioned key without saved generations should not be restored")
+	}
+	if val, ok := fresh.Get("unversioned"); !ok || val != "value" || loaded.Entries != 1 {
+		t.Errorf("unversioned = %v, %v (loaded %+v), want restored", val, ok, loaded)
+	}
+}
@@ -0,0 +1,165 @@
+// Package cache provides per-tier cache statistics
+package cache
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Cache tiers
+const (
+	TierLocal    = "l1" // LocalCache
+	TierRedis    = "l2" // Redis
+	TierDatabase = "l3" // Loader (MySQL)
+)
+
+// defaultCacheName names caches without TieredConfig.Name
+const defaultCacheName = "default"
+
+// LoadLatencyBuckets are upper bounds (seconds) of L3 load latency histogram
+var LoadLatencyBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+
+// TierCounters represents request counters of one tier
+// L3: hits = loaded, misses = record not found, errors = loader errors
+type TierCounters struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	Errors uint64 `json:"errors"` // Redis errors (incl. open breaker), decode or loader errors
+}
+
+// LatencyHistogram represents a latency histogram snapshot
+type LatencyHistogram struct {
+	Buckets []float64 `json:"buckets"` // Upper bounds in seconds
+	Counts  []uint64  `json:"counts"`  // Cumulative count per bucket
+	Count   uint64    `json:"count"`
+	Sum     float64   `json:"sum"` // In seconds
+}
+
+// TierStats represents statistics of a named cache, per tier
+type TierStats struct {
+	L1          TierCounters     `json:"l1"`
+	L2          TierCounters     `json:"l2"`
+	L3          TierCounters     `json:"l3"`
+	LoadLatency LatencyHistogram `json:"load_latency"` // L3 loads
+}
+
+// tierCounters holds live counters of one tier
+type tierCounters struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	errors atomic.Uint64
+}
+
+// tierMetrics holds live statistics of a named cache
+// Shared by all caches with the same name (biz instances are created per request)
+type tierMetrics struct {
+	tiers [3]tierCounters // l1, l2, l3
+
+	// Load latency histogram (non-cumulative bucket counts, last bucket is +Inf)
+	buckets  []atomic.Uint64
+	count    atomic.Uint64
+	sumNanos atomic.Uint64
+}
+
+// tierRegistry maps cache name to *tierMetrics
+var tierRegistry sync.Map
+
+// metricsFor returns statistics of named cache, creating them on first use
+func metricsFor(name string) *tierMetrics {
+	if name == "" {
+		name = defaultCacheName
+	}
+	if m, ok := tierRegistry.Load(name); ok {
+		return m.(*tierMetrics)
+	}
+	m, _ := tierRegistry.LoadOrStore(name, &tierMetrics{
+		buckets: make([]atomic.Uint64, len(LoadLatencyBuckets)+1),
+	})
+	return m.(*tierMetrics)
+}
+
+// tier returns counters of tier (TierLocal, TierRedis, TierDatabase)
+func (m *tierMetrics) tier(tier string) *tierCounters {
+	switch tier {
+	case TierLocal:
+		return &m.tiers[0]
+	case TierRedis:
+		return &m.tiers[1]
+	default:
+		return &m.tiers[2]
+	}
+}
+
+// hit records a hit in tier
+func (m *tierMetrics) hit(tier string) {
+	m.tier(tier).hits.Add(1)
+}
+
+// miss records a miss in tier
+func (m *tierMetrics) miss(tier string) {
+	m.tier(tier).misses.Add(1)
+}
+
+// fail records an error in tier
+func (m *tierMetrics) fail(tier string) {
+	m.tier(tier).errors.Add(1)
+}
+
+// observeLoad records duration of an L3 load
+func (m *tierMetrics) observeLoad(d time.Duration) {
+	seconds := d.Seconds()
+	i := sort.SearchFloat64s(LoadLatencyBuckets, seconds)
+	m.buckets[i].Add(1)
+	m.count.Add(1)
+	m.sumNanos.Add(uint64(d.Nanoseconds()))
+}
+
+// stats returns a snapshot
+func (m *tierMetrics) stats() TierStats {
+	snapshot := func(c *tierCounters) TierCounters {
+		return TierCounters{Hits: c.hits.Load(), Misses: c.misses.Load(), Errors: c.errors.Load()}
+	}
+
+	latency := LatencyHistogram{
+		Buckets: LoadLatencyBuckets,
+		Counts:  make([]uint64, len(LoadLatencyBuckets)),
+		Count:   m.count.Load(),
+		Sum:     time.Duration(m.sumNanos.Load()).Seconds(),
+	}
+	var cumulative uint64
+	for i := range LoadLatencyBuckets {
+		cumulative += m.buckets[i].Load()
+		latency.Counts[i] = cumulative
+	}
+
+	return TierStats{
+		L1:          snapshot(&m.tiers[0]),
+		L2:          snapshot(&m.tiers[1]),
+		L3:          snapshot(&m.tiers[2]),
+		LoadLatency: latency,
+	}
+}
+
+// AllTierStats returns statistics of all named caches (Tiered, ThreeTierCache)
+func AllTierStats() map[string]TierStats {
+	stats := make(map[string]TierStats)
+	tierRegistry.Range(func(name, m interface{}) bool {
+		stats[name.(string)] = m.(*tierMetrics).stats()
+		return true
+	})
+	return stats
+}
+
+// RedisStats represents Redis connection pool statistics
+type RedisStats struct {
+	Hits         uint32  `json:"hits"`     // Free connection found in pool
+	Misses       uint32  `json:"misses"`   // New connection dialed
+	Timeouts     uint32  `json:"timeouts"` // Waits for a connection that timed out
+	WaitCount    uint32  `json:"wait_count"`
+	WaitDuration float64 `json:"wait_duration"` // Total wait time in seconds
+	TotalConns   uint32  `json:"total_conns"`
+	IdleConns    uint32  `json:"idle_conns"`
+	StaleConns   uint32  `json:"stale_conns"` // Stale connections removed
+}
@@ -3,6 +3,8 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -15,105 +17,214 @@ import (
 // - L2: Redis, 30min TTL, 95%+ hit rate, <10ms latency
 // - L3: MySQL, persistent, 100% hit rate, 10-50ms latency
 type ThreeTierCache struct {
-	local *LocalCache
-	redis *RedisClient
+	local       *LocalCache
+	redis       *RedisClient
+	invalidator *Invalidator // Broadcasts L1 invalidation to other instances (optional)
+	loads       *LoadGroup   // Coalesces concurrent L3 loads per key
+	metrics     *tierMetrics // Per-tier statistics ("three_tier")
 
 	// TTL configuration
 	localTTL time.Duration
 	redisTTL time.Duration
 }
 
+// threeTierCacheName labels ThreeTierCache statistics
+const threeTierCacheName = "three_tier"
+
 // NewThreeTierCache creates a new three-tier cache
 func NewThreeTierCache(local *LocalCache, redis *RedisClient) *ThreeTierCache {
 	return &ThreeTierCache{
 		local:    local,
 		redis:    redis,
+		loads:    NewLoadGroup(0),
+		metrics:  metricsFor(threeTierCacheName),
 		localTTL: 5 * time.Minute,
 		redisTTL: 30 * time.Minute,
 	}
 }
 
+// WithEarlyRefresh enables probabilistic background refresh of hot keys
+// window: typical distance before L1 expiration at which refresh starts
+func (c *ThreeTierCache) WithEarlyRefresh(window time.Duration) *ThreeTierCache {
+	c.loads = NewLoadGroup(window)
+	return c
+}
+
+// WithInvalidator enables cross-instance L1 invalidation on Set/Delete/DeletePrefix
+func (c *ThreeTierCache) WithInvalidator(invalidator *Invalidator) *ThreeTierCache {
+	c.invalidator = invalidator
+	return c
+}
+
 // GetString retrieves a string value from three-tier cache
 // Returns (value, cacheLevel, error)
 // cacheLevel: "L1"=local, "L2"=redis, "L3"=database
 func (c *ThreeTierCache) GetString(ctx context.Context, key string, dbLoader func() (string, error)) (string, string, error) {
 	// Layer 1: Try local cache
-	if val, ok := c.local.Get(key); ok {
+	if val, expiresAt, ok := c.local.GetWithExpiry(key); ok {
+		c.metrics.hit(TierLocal)
+		// Hot key close to expiration: reload in background
+		if c.loads.ShouldRefresh(expiresAt) {
+			refreshCtx := context.WithoutCancel(ctx)
+			c.loads.Refresh(key, func() (interface{}, error) {
+				return c.loadString(refreshCtx, key, dbLoader)
+			})
+		}
 		return val.(string), "L1", nil
 	}
+	c.metrics.miss(TierLocal)
 
 	// Layer 2: Try Redis
 	if c.redis != nil {
 		val, err := c.redis.Get(ctx, key)
 		if err == nil {
+			c.metrics.hit(TierRedis)
 			// Backfill to local cache
 			c.local.Set(key, val)
 			return val, "L2", nil
 		}
-		// Ignore redis.Nil (key not found), propagate other errors
-		if err != redis.Nil {
-			return "", "L2", fmt.Errorf("redis error: %w", err)
+		if err := c.redisMiss(err); err != nil {
+			return "", "L2", err
 		}
 	}
 
-	// Layer 3: Load from database
-	val, err := dbLoader()
+	// Layer 3: Load from database (concurrent callers share one load)
+	val, err := c.loads.Do(key, func() (interface{}, error) {
+		return c.loadString(ctx, key, dbLoader)
+	})
 	if err != nil {
 		return "", "L3", err
 	}
 
+	return val.(string), "L3", nil
+}
+
+// loadString loads a string from database and backfills L2 and L1
+func (c *ThreeTierCache) loadString(ctx context.Context, key string, dbLoader func() (string, error)) (string, error) {
+	loaded, err := c.observe(func() (interface{}, error) { return dbLoader() })
+	if err != nil {
+		return "", err
+	}
+	val := loaded.(string)
+
 	// Backfill to L2 and L1
 	if c.redis != nil {
 		_ = c.redis.Set(ctx, key, val, c.redisTTL) // Ignore error
 	}
 	c.local.Set(key, val)
 
-	return val, "L3", nil
+	return val, nil
 }
 
-// GetJSON retrieves a JSON object from three-tier cache
-// target must be a pointer
+// observe runs an L3 load and records its latency and outcome
+func (c *ThreeTierCache) observe(dbLoader func() (interface{}, error)) (interface{}, error) {
+	start := time.Now()
+	val, err := dbLoader()
+	c.metrics.observeLoad(time.Since(start))
+
+	switch {
+	case err == nil:
+		c.metrics.hit(TierDatabase)
+	case errors.Is(err, ErrNotFound):
+		c.metrics.miss(TierDatabase)
+	default:
+		c.metrics.fail(TierDatabase)
+	}
+	return val, err
+}
+
+// redisMiss records an L2 miss or error
+// Ignores redis.Nil (key not found) and open breaker (degraded mode), returns other errors
+func (c *ThreeTierCache) redisMiss(err error) error {
+	if err == redis.Nil {
+		c.metrics.miss(TierRedis)
+		return nil
+	}
+	c.metrics.fail(TierRedis)
+	if errors.Is(err, ErrCircuitOpen) {
+		return nil
+	}
+	return fmt.Errorf("redis error: %w", err)
+}
+
+// GetJSON retrieves a JSON object from three-tier cache and decodes it into target
+// target must be a non-nil pointer (e.g., *[]string, *model.User)
+// L1 stores encoded JSON, so every call decodes a fresh copy and callers cannot mutate cached values
+// Prefer Tiered[T] for new code
 func (c *ThreeTierCache) GetJSON(ctx context.Context, key string, target interface{}, dbLoader func() (interface{}, error)) (string, error) {
 	// Layer 1: Try local cache
 	if val, ok := c.local.Get(key); ok {
-		// Deep copy to avoid mutation
-		if ptr, ok := val.(interface{}); ok {
-			*target.(*interface{}) = ptr
+		if err := decodeJSON(val, target); err == nil {
+			c.metrics.hit(TierLocal)
 			return "L1", nil
 		}
 	}
+	c.metrics.miss(TierLocal)
 
 	// Layer 2: Try Redis
 	if c.redis != nil {
-		err := c.redis.GetJSON(ctx, key, target)
+		data, err := c.redis.Client.Get(ctx, key).Bytes()
 		if err == nil {
+			if err := json.Unmarshal(data, target); err != nil {
+				c.metrics.fail(TierRedis)
+				return "L2", fmt.Errorf("failed to decode cache value: %w", err)
+			}
+			c.metrics.hit(TierRedis)
 			// Backfill to local cache
-			c.local.Set(key, target)
+			c.local.Set(key, data)
 			return "L2", nil
 		}
-		if err != redis.Nil {
-			return "L2", fmt.Errorf("redis error: %w", err)
+		if err := c.redisMiss(err); err != nil {
+			return "L2", err
 		}
 	}
 
-	// Layer 3: Load from database
-	val, err := dbLoader()
+	// Layer 3: Load from database (concurrent callers share one load)
+	val, err := c.loads.Do(key, func() (interface{}, error) {
+		val, err := c.observe(dbLoader)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(val)
+		if err != nil {
+			return nil, err
+		}
+
+		// Backfill to L2 and L1
+		if c.redis != nil {
+			_ = c.redis.Set(ctx, key, data, c.redisTTL)
+		}
+		c.local.Set(key, data)
+		return data, nil
+	})
 	if err != nil {
 		return "L3", err
 	}
 
-	// Backfill to L2 and L1
-	if c.redis != nil {
-		_ = c.redis.SetJSON(ctx, key, val, c.redisTTL)
+	// Decode into target
+	if err := decodeJSON(val, target); err != nil {
+		return "L3", err
 	}
-	c.local.Set(key, val)
-
-	// Copy to target
-	*target.(*interface{}) = val
 
 	return "L3", nil
 }
 
+// decodeJSON decodes a cached value into target
+// []byte values are JSON, other values (stored by Set) are re-encoded first
+func decodeJSON(val interface{}, target interface{}) error {
+	data, ok := val.([]byte)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(val); err != nil {
+			return err
+		}
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("failed to decode cache value: %w", err)
+	}
+	return nil
+}
+
 // Set stores a value in all cache tiers
 func (c *ThreeTierCache) Set(ctx context.Context, key string, value interface{}) error {
 	// Store in local cache
@@ -121,10 +232,13 @@ func (c *ThreeTierCache) Set(ctx context.Context, key string, value interface{})
 
 	// Store in Redis
 	if c.redis != nil {
-		return c.redis.SetJSON(ctx, key, value, c.redisTTL)
+		if err := c.redis.SetJSON(ctx, key, value, c.redisTTL); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	// Other instances reload from L2
+	return c.invalidator.Publish(ctx, key)
 }
 
 // Delete removes a key from all cache tiers
@@ -134,10 +248,13 @@ func (c *ThreeTierCache) Delete(ctx context.Context, key string) error {
 
 	// Delete from Redis
 	if c.redis != nil {
-		return c.redis.Del(ctx, key)
+		if err := c.redis.Del(ctx, key); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	// Delete from other instances' local cache
+	return c.invalidator.Publish(ctx, key)
 }
 
 // DeletePrefix removes all keys with prefix from all cache tiers
@@ -146,32 +263,45 @@ func (c *ThreeTierCache) DeletePrefix(ctx context.Context, prefix string) (int,
 	localCount := c.local.DeletePrefix(prefix)
 
 	redisCount := 0
-	var err error
 	if c.redis != nil {
+		var err error
 		redisCount, err = c.redis.DeleteByPrefix(ctx, prefix)
+		if err != nil {
+			return localCount, redisCount, err
+		}
 	}
 
-	return localCount, redisCount, err
+	// Delete from other instances' local cache
+	return localCount, redisCount, c.invalidator.PublishPrefix(ctx, prefix)
 }
 
 // Stats returns cache statistics for all tiers
 func (c *ThreeTierCache) Stats() ThreeTierStats {
 	stats := ThreeTierStats{
-		Local: c.local.Stats(),
+		Local:   c.local.Stats(),
+		Loads:   c.loads.Stats(),
+		Breaker: c.redis.BreakerStats(),
+		Redis:   c.redis.PoolStats(),
+		Tiers:   c.metrics.stats(),
 	}
 
-	// TODO: Add Redis stats if needed
-	// stats.Redis = RedisStats{...}
-
 	return stats
 }
 
 // ThreeTierStats represents statistics for all cache tiers
 type ThreeTierStats struct {
-	Local CacheStats `json:"local"`
-	// Redis RedisStats  `json:"redis"` // TODO: Add Redis stats
+	Local   CacheStats   `json:"local"`
+	Loads   LoadStats    `json:"loads"`
+	Breaker BreakerStats `json:"breaker"` // Redis circuit breaker
+	Redis   RedisStats   `json:"redis"`   // Redis connection pool
+	Tiers   TierStats    `json:"tiers"`   // Per-tier hits/misses/errors and load latency
 }
 
+// PermissionNamespace is the generation namespace of user permission cache
+// Bumping it drops permission cache of all users at once (see Generations),
+// role changes evict only users of the role
+const PermissionNamespace = "permissions"
+
 // PermissionCacheKey generates cache key for user permissions
 func PermissionCacheKey(userID uint64) string {
 	return fmt.Sprintf("user:permissions:%d", userID)
@@ -187,6 +317,26 @@ func UserCacheKeyPrefix(userID uint64) string {
 	return fmt.Sprintf("user:%d:", userID)
 }
 
+// RoleNamespace returns generation namespace of role-related cache
+func RoleNamespace(roleID uint64) string {
+	return fmt.Sprintf("role:%d", roleID)
+}
+
+// DeptNamespace is the generation namespace of data scope department cache
+// (department subtrees and departments of users)
+// Bumped when the department tree or the department of a user changes
+const DeptNamespace = "depts"
+
+// DeptIDsCacheKey generates cache key for IDs of a department and its descendants
+func DeptIDsCacheKey(deptID uint64) string {
+	return fmt.Sprintf("dept:ids:%d", deptID)
+}
+
+// UserDeptCacheKey generates cache key for department ID of a user
+func UserDeptCacheKey(userID uint64) string {
+	return fmt.Sprintf("user:dept:%d", userID)
+}
+
 // RoleCacheKeyPrefix returns prefix for all role-related cache keys
 func RoleCacheKeyPrefix(roleID uint64) string {
 	return fmt.Sprintf("role:%d:", roleID)
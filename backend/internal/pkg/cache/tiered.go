@@ -0,0 +1,332 @@
+// Package cache provides typed three-tier cache implementation
+package cache
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TieredConfig defines typed three-tier cache configuration
+type TieredConfig[T any] struct {
+	// Name labels per-tier statistics (default: "default")
+	// Caches with the same name share statistics
+	Name string
+
+	// Codec encodes values for Redis (default: JSONCodec)
+	Codec Codec[T]
+
+	// Clone returns a defensive copy of a value, applied when writing to
+	// and reading from L1 so callers cannot mutate cached values
+	// Leave nil for immutable values (strings, compiled matchers, ...)
+	Clone func(T) T
+
+	// LocalTTL and RedisTTL are default TTLs (default: 5min / 30min)
+	LocalTTL time.Duration
+	RedisTTL time.Duration
+
+	// Invalidator broadcasts deletes to other instances (optional)
+	Invalidator *Invalidator
+
+	// Loads coalesces concurrent L2/L3 loads and refreshes hot keys (optional)
+	Loads *LoadGroup
+
+	// NegativeTTL is TTL of "not found" sentinels and empty values in both tiers (default: 1min)
+	// Kept short so newly created records become visible without invalidation
+	NegativeTTL time.Duration
+
+	// IsEmpty reports empty values cached with NegativeTTL (optional, e.g., no permissions)
+	IsEmpty func(T) bool
+}
+
+// ErrNotFound is returned by loaders for missing records
+// Tiered caches it as a negative entry, so repeated lookups of missing
+// records (deleted users, unknown roles) do not reach the database
+var ErrNotFound = errors.New("cache: record not found")
+
+// negativeMarker is the Redis value of a negative entry
+const negativeMarker = "\x00cache:not-found"
+
+// negativeEntry is the L1 value of a negative entry
+type negativeEntry struct{}
+
+// Tiered implements typed three-tier cache: Local (L1) → Redis (L2) → loader (L3)
+// L1 stores T, L2 stores Codec-encoded bytes
+type Tiered[T any] struct {
+	local       *LocalCache
+	redis       *RedisClient
+	codec       Codec[T]
+	clone       func(T) T
+	localTTL    time.Duration
+	redisTTL    time.Duration
+	invalidator *Invalidator
+	loads       *LoadGroup
+	negativeTTL time.Duration
+	isEmpty     func(T) bool
+	metrics     *tierMetrics
+}
+
+// Loader loads a value from the source of truth (L3)
+type Loader[T any] func(ctx context.Context) (T, error)
+
+// CacheOption overrides per-call cache behavior
+type CacheOption func(*cacheOptions)
+
+// cacheOptions represents per-call options
+type cacheOptions struct {
+	localTTL time.Duration
+	redisTTL time.Duration
+}
+
+// WithTTL overrides L1 and L2 TTL for a single call (0 keeps default)
+func WithTTL(localTTL, redisTTL time.Duration) CacheOption {
+	return func(o *cacheOptions) {
+		o.localTTL = localTTL
+		o.redisTTL = redisTTL
+	}
+}
+
+// NewTiered creates a new typed three-tier cache
+// redis may be nil (L1 + loader only)
+func NewTiered[T any](local *LocalCache, redis *RedisClient, cfg TieredConfig[T]) *Tiered[T] {
+	if cfg.Codec == nil {
+		cfg.Codec = JSONCodec[T]{}
+	}
+	if cfg.LocalTTL <= 0 {
+		cfg.LocalTTL = 5 * time.Minute
+	}
+	if cfg.RedisTTL <= 0 {
+		cfg.RedisTTL = 30 * time.Minute
+	}
+	if cfg.NegativeTTL <= 0 {
+		cfg.NegativeTTL = time.Minute
+	}
+
+	return &Tiered[T]{
+		local:       local,
+		redis:       redis,
+		codec:       cfg.Codec,
+		clone:       cfg.Clone,
+		localTTL:    cfg.LocalTTL,
+		redisTTL:    cfg.RedisTTL,
+		invalidator: cfg.Invalidator,
+		loads:       cfg.Loads,
+		negativeTTL: cfg.NegativeTTL,
+		isEmpty:     cfg.IsEmpty,
+		metrics:     metricsFor(cfg.Name),
+	}
+}
+
+// Get retrieves a value from L1 → L2 → loader and backfills upper tiers
+// Concurrent misses of the same key share one load (if Loads is set)
+// Returns ErrNotFound (from cache or loader) for missing records
+func (c *Tiered[T]) Get(ctx context.Context, key string, loader Loader[T], opts ...CacheOption) (T, error) {
+	o := c.options(opts)
+
+	// Layer 1: Try local cache
+	if val, expiresAt, ok := c.local.GetWithExpiry(key); ok {
+		if _, ok := val.(negativeEntry); ok {
+			c.metrics.hit(TierLocal)
+			var zero T
+			return zero, ErrNotFound
+		}
+		if value, ok := val.(T); ok {
+			c.metrics.hit(TierLocal)
+			// Hot key close to expiration: reload from L3 in background
+			if c.loads.ShouldRefresh(expiresAt) {
+				refreshCtx := context.WithoutCancel(ctx)
+				c.loads.Refresh(key, func() (interface{}, error) {
+					return c.load(refreshCtx, key, loader, o, false)
+				})
+			}
+			return c.copy(value), nil
+		}
+	}
+	c.metrics.miss(TierLocal)
+
+	// Layer 2 + 3: Load once for concurrent callers
+	val, err := c.loads.Do(key, func() (interface{}, error) {
+		return c.load(ctx, key, loader, o, true)
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	value, ok := val.(T)
+	if !ok {
+		// Key shared with another value type in LoadGroup, load directly
+		return c.load(ctx, key, loader, o, true)
+	}
+	return c.copy(value), nil
+}
+
+// Set stores a value in L1 and L2 and invalidates other instances' L1
+func (c *Tiered[T]) Set(ctx context.Context, key string, value T, opts ...CacheOption) error {
+	o := c.options(opts)
+
+	o = c.ttlFor(value, o)
+	if err := c.setRedis(ctx, key, value, o.redisTTL); err != nil {
+		return err
+	}
+	c.local.SetWithTTL(key, c.copy(value), o.localTTL)
+
+	return c.invalidator.Publish(ctx, key)
+}
+
+// Delete removes keys from all tiers (including other instances' L1)
+func (c *Tiered[T]) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	c.local.DeleteKeys(keys...)
+	if c.redis != nil {
+		if err := c.redis.Del(ctx, keys...); err != nil {
+			return err
+		}
+	}
+
+	return c.invalidator.Publish(ctx, keys...)
+}
+
+// DeletePrefix removes keys with prefix from all tiers (including other instances' L1)
+// Returns (localCount, redisCount, error)
+func (c *Tiered[T]) DeletePrefix(ctx context.Context, prefix string) (int, int, error) {
+	localCount := c.local.DeletePrefix(prefix)
+
+	redisCount := 0
+	if c.redis != nil {
+		var err error
+		redisCount, err = c.redis.DeleteByPrefix(ctx, prefix)
+		if err != nil {
+			return localCount, redisCount, err
+		}
+	}
+
+	return localCount, redisCount, c.invalidator.PublishPrefix(ctx, prefix)
+}
+
+// LoadStats returns load coalescing statistics
+func (c *Tiered[T]) LoadStats() LoadStats {
+	return c.loads.Stats()
+}
+
+// TierStats returns per-tier statistics of this cache name
+func (c *Tiered[T]) TierStats() TierStats {
+	return c.metrics.stats()
+}
+
+// load reads L2 (if useRedis) or calls loader, then backfills cache
+// Redis errors (including open circuit breaker) fall through to loader
+func (c *Tiered[T]) load(ctx context.Context, key string, loader Loader[T], o cacheOptions, useRedis bool) (T, error) {
+	// Layer 2: Try Redis
+	if useRedis && c.redis != nil {
+		data, err := c.redis.Client.Get(ctx, key).Bytes()
+		switch {
+		case err == nil:
+			if string(data) == negativeMarker {
+				c.metrics.hit(TierRedis)
+				c.local.SetWithTTL(key, negativeEntry{}, c.negativeTTL)
+				var zero T
+				return zero, ErrNotFound
+			}
+			value, err := c.codec.Decode(data)
+			if err == nil {
+				c.metrics.hit(TierRedis)
+				c.local.SetWithTTL(key, c.copy(value), c.ttlFor(value, o).localTTL)
+				return value, nil
+			}
+			c.metrics.fail(TierRedis)
+			log.Printf("⚠️  Failed to decode cache value (key: %s): %v", key, err)
+		case err == redis.Nil:
+			c.metrics.miss(TierRedis)
+		default:
+			c.metrics.fail(TierRedis)
+			if !errors.Is(err, ErrCircuitOpen) {
+				log.Printf("⚠️  Redis get failed (key: %s): %v", key, err)
+			}
+		}
+	}
+
+	// Layer 3: Load from source
+	start := time.Now()
+	value, err := loader(ctx)
+	c.metrics.observeLoad(time.Since(start))
+	if errors.Is(err, ErrNotFound) {
+		c.metrics.miss(TierDatabase)
+		c.setNegative(ctx, key)
+		return value, err
+	}
+	if err != nil {
+		c.metrics.fail(TierDatabase)
+		return value, err
+	}
+	c.metrics.hit(TierDatabase)
+
+	// Backfill to L2 and L1 (empty values with negative TTL)
+	o = c.ttlFor(value, o)
+	if err := c.setRedis(ctx, key, value, o.redisTTL); err != nil && !errors.Is(err, ErrCircuitOpen) {
+		log.Printf("⚠️  Redis backfill failed (key: %s): %v", key, err)
+	}
+	c.local.SetWithTTL(key, c.copy(value), o.localTTL)
+
+	return value, nil
+}
+
+// setRedis encodes and stores a value in Redis
+func (c *Tiered[T]) setRedis(ctx context.Context, key string, value T, ttl time.Duration) error {
+	if c.redis == nil {
+		return nil
+	}
+	data, err := c.codec.Encode(value)
+	if err != nil {
+		return err
+	}
+	return c.redis.Set(ctx, key, data, ttl)
+}
+
+// setNegative caches a "not found" sentinel in L2 and L1 with negative TTL
+func (c *Tiered[T]) setNegative(ctx context.Context, key string) {
+	if c.redis != nil {
+		if err := c.redis.Set(ctx, key, negativeMarker, c.negativeTTL); err != nil && !errors.Is(err, ErrCircuitOpen) {
+			log.Printf("⚠️  Redis backfill failed (key: %s): %v", key, err)
+		}
+	}
+	c.local.SetWithTTL(key, negativeEntry{}, c.negativeTTL)
+}
+
+// ttlFor shortens TTLs of empty values to negative TTL
+func (c *Tiered[T]) ttlFor(value T, o cacheOptions) cacheOptions {
+	if c.isEmpty == nil || !c.isEmpty(value) {
+		return o
+	}
+	o.localTTL = min(o.localTTL, c.negativeTTL)
+	o.redisTTL = min(o.redisTTL, c.negativeTTL)
+	return o
+}
+
+// copy returns a defensive copy of value (if Clone is set)
+func (c *Tiered[T]) copy(value T) T {
+	if c.clone == nil {
+		return value
+	}
+	return c.clone(value)
+}
+
+// options applies per-call options over defaults
+func (c *Tiered[T]) options(opts []CacheOption) cacheOptions {
+	o := cacheOptions{localTTL: c.localTTL, redisTTL: c.redisTTL}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.localTTL <= 0 {
+		o.localTTL = c.localTTL
+	}
+	if o.redisTTL <= 0 {
+		o.redisTTL = c.redisTTL
+	}
+	return o
+}
@@ -0,0 +1,336 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sword-demon/go-react-admin/internal/pkg/cache"
+)
+
+// newTestRedis creates a Redis client connected to miniredis
+func newTestRedis(t *testing.T, mr *miniredis.Miniredis) *cache.RedisClient {
+	t.Helper()
+	redisClient := &cache.RedisClient{Client: redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+	t.Cleanup(func() { _ = redisClient.Client.Close() })
+	return redisClient
+}
+
+// TestTieredGet tests L3 → L1 → L2 lookup order and backfill
+func TestTieredGet(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := newTestRedis(t, mr)
+	ctx := context.Background()
+
+	var calls atomic.Int32
+	loader := func(ctx context.Context) ([]string, error) {
+		calls.Add(1)
+		return []string{"user:read", "!user:delete"}, nil
+	}
+
+	a := cache.NewTiered(cache.NewLocalCache(100, time.Minute), redisClient, cache.TieredConfig[[]string]{})
+	for i := 0; i < 3; i++ {
+		val, err := a.Get(ctx, "user:permissions:1", loader)
+		if err != nil || len(val) != 2 || val[1] != "!user:delete" {
+			t.Fatalf("Get = %v, %v", val, err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("loader called %d times, want 1 (L1 hits)", calls.Load())
+	}
+	if got, _ := mr.Get("user:permissions:1"); got != `["user:read","!user:delete"]` {
+		t.Errorf("Redis value = %s", got)
+	}
+
+	// New instance (empty L1) is served from L2
+	b := cache.NewTiered(cache.NewLocalCache(100, time.Minute), redisClient, cache.TieredConfig[[]string]{})
+	if val, err := b.Get(ctx, "user:permissions:1", loader); err != nil || len(val) != 2 {
+		t.Fatalf("Get from L2 = %v, %v", val, err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("loader called %d times, want 1 (L2 hit)", calls.Load())
+	}
+
+	// Delete removes all tiers
+	if err := a.Delete(ctx, "user:permissions:1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if mr.Exists("user:permissions:1") {
+		t.Error("key should be deleted from Redis")
+	}
+	if _, err := a.Get(ctx, "user:permissions:1", loader); err != nil || calls.Load() != 2 {
+		t.Errorf("Get after Delete should reload, calls = %d, err = %v", calls.Load(), err)
+	}
+}
+
+// TestTieredDefensiveCopy tests callers cannot mutate cached values
+func TestTieredDefensiveCopy(t *testing.T) {
+	ctx := context.Background()
+	tiered := cache.NewTiered(cache.NewLocalCache(100, time.Minute), nil, cache.TieredConfig[[]string]{
+		Clone: cache.CloneSlice[string],
+	})
+	loader := func(ctx context.Context) ([]string, error) {
+		return []string{"user:read"}, nil
+	}
+
+	val, _ := tiered.Get(ctx, "k", loader)
+	val[0] = "*:*"
+
+	val, _ = tiered.Get(ctx, "k", loader)
+	if val[0] != "user:read" {
+		t.Errorf("cached value mutated by caller: %v", val)
+	}
+
+	// Value passed to Set is copied too
+	perms := []string{"user:write"}
+	_ = tiered.Set(ctx, "k", perms)
+	perms[0] = "*:*"
+	if val, _ = tiered.Get(ctx, "k", loader); val[0] != "user:write" {
+		t.Errorf("cached value mutated after Set: %v", val)
+	}
+}
+
+// TestTieredTTLOverride tests per-call TTL overrides
+func TestTieredTTLOverride(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+	tiered := cache.NewTiered(cache.NewLocalCache(100, time.Minute), newTestRedis(t, mr), cache.TieredConfig[string]{
+		Codec: cache.StringCodec{},
+	})
+
+	var calls atomic.Int32
+	loader := func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		return "v", nil
+	}
+
+	if _, err := tiered.Get(ctx, "short", loader, cache.WithTTL(20*time.Millisecond, time.Minute)); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if ttl := mr.TTL("short"); ttl != time.Minute {
+		t.Errorf("Redis TTL = %v, want 1m", ttl)
+	}
+	if got, _ := mr.Get("short"); got != "v" {
+		t.Errorf("Redis value = %q, want raw string", got)
+	}
+
+	// L1 expired, served from L2 without loader
+	time.Sleep(30 * time.Millisecond)
+	if val, err := tiered.Get(ctx, "short", loader); err != nil || val != "v" {
+		t.Fatalf("Get = %q, %v", val, err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("loader called %d times, want 1", calls.Load())
+	}
+
+	// Default TTL
+	_ = tiered.Set(ctx, "default", "v")
+	if ttl := mr.TTL("default"); ttl != 30*time.Minute {
+		t.Errorf("Redis TTL = %v, want 30m", ttl)
+	}
+}
+
+// TestTieredLoaderError tests loader errors are returned and not cached
+func TestTieredLoaderError(t *testing.T) {
+	ctx := context.Background()
+	tiered := cache.NewTiered(cache.NewLocalCache(100, time.Minute), nil, cache.TieredConfig[int]{})
+	loadErr := errors.New("db down")
+
+	if _, err := tiered.Get(ctx, "k", func(ctx context.Context) (int, error) { return 0, loadErr }); err != loadErr {
+		t.Fatalf("err = %v, want %v", err, loadErr)
+	}
+	if val, err := tiered.Get(ctx, "k", func(ctx context.Context) (int, error) { return 42, nil }); err != nil || val != 42 {
+		t.Errorf("Get = %d, %v, want 42", val, err)
+	}
+}
+
+// TestTieredRedisDown tests Redis errors fall through to loader
+func TestTieredRedisDown(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := newTestRedis(t, mr)
+	mr.Close()
+
+	tiered := cache.NewTiered(cache.NewLocalCache(100, time.Minute), redisClient, cache.TieredConfig[string]{})
+	val, err := tiered.Get(context.Background(), "k", func(ctx context.Context) (string, error) { return "db", nil })
+	if err != nil || val != "db" {
+		t.Errorf("Get = %q, %v, want db", val, err)
+	}
+}
+
+// TestTieredStampede tests concurrent misses share one load
+func TestTieredStampede(t *testing.T) {
+	loads := cache.NewLoadGroup(0)
+	tiered := cache.NewTiered(cache.NewLocalCache(100, time.Minute), nil, cache.TieredConfig[[]string]{
+		Clone: cache.CloneSlice[string],
+		Loads: loads,
+	})
+
+	var calls atomic.Int32
+	loader := func(ctx context.Context) ([]string, error) {
+		calls.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		return []string{"*:*"}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if val, err := tiered.Get(context.Background(), "hot", loader); err != nil || len(val) != 1 {
+				t.Errorf("Get = %v, %v", val, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("loader called %d times, want 1", calls.Load())
+	}
+	if stats := tiered.LoadStats(); stats.Loads != 1 || stats.Coalesced == 0 {
+		t.Errorf("load stats = %+v, want loads=1 and coalesced > 0", stats)
+	}
+}
+
+// TestThreeTierCacheGetJSONTyped tests GetJSON with typed pointer targets
+func TestThreeTierCacheGetJSONTyped(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := newTestRedis(t, mr)
+	ctx := context.Background()
+	loader := func() (interface{}, error) {
+		return []string{"user:read"}, nil
+	}
+
+	a := cache.NewThreeTierCache(cache.NewLocalCache(100, time.Minute), redisClient)
+	for _, want := range []string{"L3", "L1"} {
+		var perms []string
+		level, err := a.GetJSON(ctx, "k", &perms, loader)
+		if err != nil || level != want || len(perms) != 1 || perms[0] != "user:read" {
+			t.Fatalf("GetJSON = %v, %s, %v, want %s", perms, level, err, want)
+		}
+		// Mutation must not leak into cache
+		perms[0] = "*:*"
+	}
+
+	b := cache.NewThreeTierCache(cache.NewLocalCache(100, time.Minute), redisClient)
+	var perms []string
+	if level, err := b.GetJSON(ctx, "k", &perms, loader); err != nil || level != "L2" || perms[0] != "user:read" {
+		t.Errorf("GetJSON = %v, %s, %v, want L2", perms, level, err)
+	}
+}
+
+// TestTieredNegativeCache tests missing records and empty values are cached with negative TTL
+func TestTieredNegativeCache(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := newTestRedis(t, mr)
+	ctx := context.Background()
+
+	cfg := cache.TieredConfig[[]string]{
+		NegativeTTL: 10 * time.Second,
+		IsEmpty:     func(v []string) bool { return len(v) == 0 },
+	}
+	a := cache.NewTiered(cache.NewLocalCache(100, time.Minute), redisClient, cfg)
+
+	var calls atomic.Int32
+	missing := func(ctx context.Context) ([]string, error) {
+		calls.Add(1)
+		return nil, fmt.Errorf("user 404: %w", cache.ErrNotFound)
+	}
+
+	// Missing record: loader runs once, then L1 serves the sentinel
+	for i := 0; i < 3; i++ {
+		if _, err := a.Get(ctx, "user:permissions:404", missing); !errors.Is(err, cache.ErrNotFound) {
+			t.Fatalf("Get = %v, want ErrNotFound", err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("loader called %d times, want 1", calls.Load())
+	}
+	if ttl := mr.TTL("user:permissions:404"); ttl != 10*time.Second {
+		t.Errorf("negative entry TTL = %v, want 10s", ttl)
+	}
+
+	// Other instance reads the sentinel from L2
+	b := cache.NewTiered(cache.NewLocalCache(100, time.Minute), redisClient, cfg)
+	if _, err := b.Get(ctx, "user:permissions:404", missing); !errors.Is(err, cache.ErrNotFound) {
+		t.Fatalf("Get from L2 = %v, want ErrNotFound", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("loader called %d times, want 1 (L2 hit)", calls.Load())
+	}
+
+	// Empty value: cached with negative TTL
+	empty := func(ctx context.Context) ([]string, error) {
+		calls.Add(1)
+		return []string{}, nil
+	}
+	if val, err := a.Get(ctx, "user:permissions:1", empty); err != nil || len(val) != 0 {
+		t.Fatalf("Get = %v, %v, want empty", val, err)
+	}
+	if ttl := mr.TTL("user:permissions:1"); ttl != 10*time.Second {
+		t.Errorf("empty value TTL = %v, want 10s", ttl)
+	}
+
+	// Negative entry expires, record is loaded again
+	mr.FastForward(11 * time.Second)
+	c := cache.NewTiered(cache.NewLocalCache(100, time.Minute), redisClient, cfg)
+	found := func(ctx context.Context) ([]string, error) { return []string{"user:read"}, nil }
+	if val, err := c.Get(ctx, "user:permissions:404", found); err != nil || len(val) != 1 {
+		t.Errorf("Get after expiration = %v, %v", val, err)
+	}
+	if ttl := mr.TTL("user:permissions:404"); ttl != 30*time.Minute {
+		t.Errorf("value TTL = %v, want default 30m", ttl)
+	}
+}
+
+// TestTieredStats tests per-tier counters and load latency histogram
+func TestTieredStats(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := newTestRedis(t, mr)
+	ctx := context.Background()
+
+	cfg := cache.TieredConfig[string]{Name: "test_tier_stats"}
+	a := cache.NewTiered(cache.NewLocalCache(100, time.Minute), redisClient, cfg)
+	loader := func(ctx context.Context) (string, error) {
+		time.Sleep(2 * time.Millisecond)
+		return "v", nil
+	}
+	missing := func(ctx context.Context) (string, error) { return "", cache.ErrNotFound }
+	failing := func(ctx context.Context) (string, error) { return "", errors.New("db down") }
+
+	_, _ = a.Get(ctx, "k", loader)        // L1 miss, L2 miss, L3 hit
+	_, _ = a.Get(ctx, "k", loader)        // L1 hit
+	_, _ = a.Get(ctx, "missing", missing) // L1 miss, L2 miss, L3 miss
+	_, _ = a.Get(ctx, "failing", failing) // L1 miss, L2 miss, L3 error
+
+	// Same name shares statistics: L1 miss, L2 hit
+	b := cache.NewTiered(cache.NewLocalCache(100, time.Minute), redisClient, cfg)
+	_, _ = b.Get(ctx, "k", loader)
+
+	stats := a.TierStats()
+	want := cache.TierStats{
+		L1: cache.TierCounters{Hits: 1, Misses: 4},
+		L2: cache.TierCounters{Hits: 1, Misses: 3},
+		L3: cache.TierCounters{Hits: 1, Misses: 1, Errors: 1},
+	}
+	if stats.L1 != want.L1 || stats.L2 != want.L2 || stats.L3 != want.L3 {
+		t.Errorf("tier stats = %+v, want %+v", stats, want)
+	}
+
+	latency := stats.LoadLatency
+	if latency.Count != 3 || latency.Sum < 0.002 {
+		t.Errorf("load latency = %+v, want 3 loads, sum >= 2ms", latency)
+	}
+	if first := latency.Counts[0]; first != 2 {
+		t.Errorf("loads under %vs = %d, want 2 (instant loaders)", latency.Buckets[0], first)
+	}
+	if _, ok := cache.AllTierStats()["test_tier_stats"]; !ok {
+		t.Error("AllTierStats should include named cache")
+	}
+}
@@ -3,6 +3,8 @@ package cache
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"log"
 	"sync"
@@ -17,15 +19,26 @@ type Warmer interface {
 
 // WarmupConfig defines cache warmup configuration
 type WarmupConfig struct {
-	// SuperAdminUserIDs are IDs of super admin users to preload
+	// SuperAdminUserIDs are IDs of users always preloaded (in addition to data-driven targets)
 	SuperAdminUserIDs []uint64
 
-	// CommonRoleIDs are IDs of frequently used roles
+	// CommonRoleIDs are IDs of roles always preloaded (in addition to data-driven targets)
 	CommonRoleIDs []uint64
 
+	// RecentLoginDays selects users who logged in during the last N days (0 = disabled)
+	// Requires a WarmupTargetSource
+	RecentLoginDays int
+
+	// MaxUsers and MaxRoles cap the number of warmed users and roles (0 = no limit)
+	MaxUsers int
+	MaxRoles int
+
 	// Concurrency controls parallel warmup goroutines
 	Concurrency int
 
+	// RateLimit caps permission loads per second, bounding DB load (0 = no limit)
+	RateLimit int
+
 	// Timeout for entire warmup process
 	Timeout time.Duration
 
@@ -34,13 +47,16 @@ type WarmupConfig struct {
 }
 
 // DefaultWarmupConfig returns default warmup configuration
+// Targets come from the data (WithTargetSource): enabled roles, holders of *:*, recent logins
 func DefaultWarmupConfig() *WarmupConfig {
 	return &WarmupConfig{
-		SuperAdminUserIDs: []uint64{1}, // Default: user ID 1 is super admin
-		CommonRoleIDs:     []uint64{1, 2, 3}, // Admin, Manager, User
-		Concurrency:       5,
-		Timeout:           30 * time.Second,
-		EnableLogging:     true,
+		RecentLoginDays: 7,
+		MaxUsers:        1000,
+		MaxRoles:        200,
+		Concurrency:     5,
+		RateLimit:       200,
+		Timeout:         30 * time.Second,
+		EnableLogging:   true,
 	}
 }
 
@@ -60,10 +76,30 @@ type warmupResult struct {
 	err      error
 }
 
+// WarmupTargets represents users and roles to preload, most important first
+type WarmupTargets struct {
+	UserIDs []uint64
+	RoleIDs []uint64
+}
+
+// WarmupTargetQuery represents constraints for WarmupTargetSource
+type WarmupTargetQuery struct {
+	RecentLoginSince time.Time // Zero = skip recent logins
+	MaxUsers         int       // 0 = no limit
+	MaxRoles         int       // 0 = no limit
+}
+
+// WarmupTargetSource derives warmup targets from the data
+type WarmupTargetSource interface {
+	// WarmupTargets returns users and roles to preload
+	WarmupTargets(ctx context.Context, query WarmupTargetQuery) (*WarmupTargets, error)
+}
+
 // PermissionWarmer implements cache warming for permissions
 type PermissionWarmer struct {
 	config *WarmupConfig
-	loader PermissionLoader // Delegate to load permissions
+	loader PermissionLoader   // Delegate to load permissions
+	source WarmupTargetSource // Optional, data-driven targets
 }
 
 // PermissionLoader defines interface to load permissions
@@ -93,6 +129,12 @@ func NewPermissionWarmer(config *WarmupConfig, loader PermissionLoader) *Permiss
 	}
 }
 
+// WithTargetSource derives warmup targets from source (static IDs are still warmed first)
+func (w *PermissionWarmer) WithTargetSource(source WarmupTargetSource) *PermissionWarmer {
+	w.source = source
+	return w
+}
+
 // Warm preloads permissions into cache
 // This should be called on application startup
 func (w *PermissionWarmer) Warm(ctx context.Context) (*WarmupStats, error) {
@@ -103,13 +145,23 @@ func (w *PermissionWarmer) Warm(ctx context.Context) (*WarmupStats, error) {
 	defer cancel()
 
 	stats := &WarmupStats{
-		TotalItems:    len(w.config.SuperAdminUserIDs) + len(w.config.CommonRoleIDs),
 		ErrorMessages: make([]string, 0),
 	}
 
+	targets := w.targets(ctx, stats)
+	stats.TotalItems = len(targets.UserIDs) + len(targets.RoleIDs)
+
 	if w.config.EnableLogging {
 		log.Printf("🔥 Starting cache warmup: %d users, %d roles",
-			len(w.config.SuperAdminUserIDs), len(w.config.CommonRoleIDs))
+			len(targets.UserIDs), len(targets.RoleIDs))
+	}
+
+	// Rate limiter bounds DB load
+	var tick <-chan time.Time
+	if w.config.RateLimit > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(w.config.RateLimit))
+		defer ticker.Stop()
+		tick = ticker.C
 	}
 
 	// Create worker pool
@@ -119,48 +171,42 @@ func (w *PermissionWarmer) Warm(ctx context.Context) (*WarmupStats, error) {
 	// Worker pool for concurrent warmup
 	semaphore := make(chan struct{}, w.config.Concurrency)
 
-	// Warm up user permissions
-	for _, userID := range w.config.SuperAdminUserIDs {
+	warm := func(itemType string, id uint64, load func(context.Context, uint64) ([]string, error)) {
 		wg.Add(1)
-		go func(uid uint64) {
+		go func() {
 			defer wg.Done()
 
-			semaphore <- struct{}{} // Acquire
+			semaphore <- struct{}{}        // Acquire
 			defer func() { <-semaphore }() // Release
 
-			if w.config.EnableLogging {
-				log.Printf("  → Warming user permissions: userID=%d", uid)
+			result := warmupResult{itemType: itemType, id: id}
+			if tick != nil {
+				select {
+				case <-tick:
+				case <-ctx.Done():
+					result.err = ctx.Err()
+					resultCh <- result
+					return
+				}
 			}
 
-			_, err := w.loader.LoadUserPermissions(ctx, uid)
-			resultCh <- warmupResult{
-				itemType: "user",
-				id:       uid,
-				err:      err,
+			if w.config.EnableLogging {
+				log.Printf("  → Warming %s permissions: %sID=%d", itemType, itemType, id)
 			}
-		}(userID)
-	}
-
-	// Warm up role permissions
-	for _, roleID := range w.config.CommonRoleIDs {
-		wg.Add(1)
-		go func(rid uint64) {
-			defer wg.Done()
 
-			semaphore <- struct{}{} // Acquire
-			defer func() { <-semaphore }() // Release
+			_, result.err = load(ctx, id)
+			resultCh <- result
+		}()
+	}
 
-			if w.config.EnableLogging {
-				log.Printf("  → Warming role permissions: roleID=%d", rid)
-			}
+	// Warm up user permissions
+	for _, userID := range targets.UserIDs {
+		warm("user", userID, w.loader.LoadUserPermissions)
+	}
 
-			_, err := w.loader.LoadRolePermissions(ctx, rid)
-			resultCh <- warmupResult{
-				itemType: "role",
-				id:       rid,
-				err:      err,
-			}
-		}(roleID)
+	// Warm up role permissions
+	for _, roleID := range targets.RoleIDs {
+		warm("role", roleID, w.loader.LoadRolePermissions)
 	}
 
 	// Wait for all workers to complete
@@ -203,6 +249,57 @@ func (w *PermissionWarmer) Warm(ctx context.Context) (*WarmupStats, error) {
 	return stats, nil
 }
 
+// targets merges static IDs with data-driven targets, deduplicated and capped
+// Source errors are recorded in stats, warmup falls back to static IDs
+func (w *PermissionWarmer) targets(ctx context.Context, stats *WarmupStats) *WarmupTargets {
+	userIDs := append([]uint64(nil), w.config.SuperAdminUserIDs...)
+	roleIDs := append([]uint64(nil), w.config.CommonRoleIDs...)
+
+	if w.source != nil {
+		query := WarmupTargetQuery{
+			MaxUsers: w.config.MaxUsers,
+			MaxRoles: w.config.MaxRoles,
+		}
+		if w.config.RecentLoginDays > 0 {
+			query.RecentLoginSince = time.Now().AddDate(0, 0, -w.config.RecentLoginDays)
+		}
+
+		found, err := w.source.WarmupTargets(ctx, query)
+		if err != nil {
+			errMsg := fmt.Sprintf("targets - %v", err)
+			stats.ErrorMessages = append(stats.ErrorMessages, errMsg)
+			if w.config.EnableLogging {
+				log.Printf("⚠️  Failed to resolve warmup targets, using configured IDs: %v", err)
+			}
+		} else if found != nil {
+			userIDs = append(userIDs, found.UserIDs...)
+			roleIDs = append(roleIDs, found.RoleIDs...)
+		}
+	}
+
+	return &WarmupTargets{
+		UserIDs: uniqueIDs(userIDs, w.config.MaxUsers),
+		RoleIDs: uniqueIDs(roleIDs, w.config.MaxRoles),
+	}
+}
+
+// uniqueIDs removes duplicate IDs keeping order, and truncates to limit (0 = no limit)
+func uniqueIDs(ids []uint64, limit int) []uint64 {
+	seen := make(map[uint64]struct{}, len(ids))
+	result := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		result = append(result, id)
+	}
+	return result
+}
+
 // WarmAsync starts warmup in background (non-blocking)
 // Returns a channel that receives the result when warmup completes
 func (w *PermissionWarmer) WarmAsync(ctx context.Context) <-chan *WarmupStats {
@@ -219,3 +316,129 @@ func (w *PermissionWarmer) WarmAsync(ctx context.Context) <-chan *WarmupStats {
 
 	return resultCh
 }
+
+// Warmup job statuses
+const (
+	WarmupRunning   = "running"
+	WarmupCompleted = "completed"
+	WarmupFailed    = "failed" // All items failed
+)
+
+// maxWarmupJobs bounds finished jobs kept for polling
+const maxWarmupJobs = 20
+
+// WarmupJob represents a background warmup run
+type WarmupJob struct {
+	ID         string       `json:"id"`
+	Status     string       `json:"status"`
+	StartedAt  time.Time    `json:"started_at"`
+	FinishedAt *time.Time   `json:"finished_at,omitempty"`
+	Stats      *WarmupStats `json:"stats,omitempty"` // Set when finished
+}
+
+// WarmupJobs runs warmups in background as jobs that can be polled by ID
+// Only one warmup runs at a time; the last maxWarmupJobs jobs are kept
+type WarmupJobs struct {
+	warmer *PermissionWarmer
+
+	mu      sync.Mutex
+	jobs    map[string]*WarmupJob
+	order   []string // Job IDs, oldest first
+	running string   // ID of running job
+}
+
+// NewWarmupJobs creates a new warmup job tracker
+func NewWarmupJobs(warmer *PermissionWarmer) *WarmupJobs {
+	return &WarmupJobs{
+		warmer: warmer,
+		jobs:   make(map[string]*WarmupJob),
+	}
+}
+
+// Start starts a warmup job (PermissionWarmer.WarmAsync)
+// If a warmup is already running, returns the running job and started = false
+// Job outlives ctx cancellation (e.g., HTTP request finishing)
+func (j *WarmupJobs) Start(ctx context.Context) (WarmupJob, bool, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if job, ok := j.jobs[j.running]; ok {
+		return *job, false, nil
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return WarmupJob{}, false, err
+	}
+	job := &WarmupJob{
+		ID:        id,
+		Status:    WarmupRunning,
+		StartedAt: time.Now(),
+	}
+	j.add(job)
+	j.running = id
+
+	resultCh := j.warmer.WarmAsync(context.WithoutCancel(ctx))
+	go j.finish(id, resultCh)
+
+	return *job, true, nil
+}
+
+// Get returns a job by ID
+func (j *WarmupJobs) Get(id string) (WarmupJob, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	job, ok := j.jobs[id]
+	if !ok {
+		return WarmupJob{}, false
+	}
+	return *job, true
+}
+
+// finish waits for warmup result and records it
+func (j *WarmupJobs) finish(id string, resultCh <-chan *WarmupStats) {
+	stats := <-resultCh
+	finishedAt := time.Now()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.running == id {
+		j.running = ""
+	}
+	job, ok := j.jobs[id]
+	if !ok {
+		return
+	}
+	job.FinishedAt = &finishedAt
+	job.Stats = stats
+	job.Status = WarmupCompleted
+	if stats != nil && stats.TotalItems > 0 && stats.FailureCount == stats.TotalItems {
+		job.Status = WarmupFailed
+	}
+}
+
+// add stores a job and drops the oldest finished jobs over limit (must be called with lock held)
+func (j *WarmupJobs) add(job *WarmupJob) {
+	j.jobs[job.ID] = job
+	j.order = append(j.order, job.ID)
+
+	for len(j.order) > maxWarmupJobs {
+		oldest := j.order[0]
+		if oldest == j.running {
+			break
+		}
+		delete(j.jobs, oldest)
+		j.order = j.order[1:]
+	}
+}
+
+// newJobID generates a random job ID
+func newJobID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,125 @@
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sword-demon/go-react-admin/internal/pkg/cache"
+)
+
+// TestWarmupJobs tests warmup jobs run once at a time and can be polled by ID
+func TestWarmupJobs(t *testing.T) {
+	localCache := cache.NewLocalCache(100, time.Minute)
+	warmer := cache.NewPermissionWarmer(&cache.WarmupConfig{
+		SuperAdminUserIDs: []uint64{1, 2},
+		CommonRoleIDs:     []uint64{1},
+		Concurrency:       2,
+		Timeout:           time.Second,
+	}, &mockPermissionLoader{localCache: localCache})
+	jobs := cache.NewWarmupJobs(warmer)
+
+	// Request context is canceled right away, job keeps running
+	ctx, cancel := context.WithCancel(context.Background())
+	job, started, err := jobs.Start(ctx)
+	cancel()
+	if err != nil || !started || job.Status != cache.WarmupRunning {
+		t.Fatalf("Start = %+v, %v, %v, want running job", job, started, err)
+	}
+
+	// Second start joins running job
+	if again, started, _ := jobs.Start(context.Background()); started || again.ID != job.ID {
+		t.Errorf("Start while running = %s (started=%v), want %s", again.ID, started, job.ID)
+	}
+
+	waitFor(t, func() bool {
+		polled, _ := jobs.Get(job.ID)
+		return polled.Status != cache.WarmupRunning
+	}, "warmup job did not finish")
+
+	polled, _ := jobs.Get(job.ID)
+	if polled.Status != cache.WarmupCompleted || polled.FinishedAt == nil || polled.Stats == nil {
+		t.Fatalf("job = %+v, want completed with stats", polled)
+	}
+	if polled.Stats.TotalItems != 3 || polled.Stats.SuccessCount != 3 {
+		t.Errorf("stats = %+v, want 3/3 success", polled.Stats)
+	}
+	for _, key := range []string{"user:permissions:1", "user:permissions:2", "role:permissions:1"} {
+		if _, ok := localCache.Get(key); !ok {
+			t.Errorf("%s not warmed", key)
+		}
+	}
+
+	// Finished job allows a new one
+	if next, started, _ := jobs.Start(context.Background()); !started || next.ID == job.ID {
+		t.Errorf("Start after finish = %s (started=%v), want new job", next.ID, started)
+	}
+	if _, ok := jobs.Get(fmt.Sprintf("%s-unknown", job.ID)); ok {
+		t.Error("Get should not find unknown job")
+	}
+}
+
+// mockTargetSource returns fixed warmup targets and records the query
+type mockTargetSource struct {
+	targets *cache.WarmupTargets
+	err     error
+	query   cache.WarmupTargetQuery
+}
+
+func (s *mockTargetSource) WarmupTargets(ctx context.Context, query cache.WarmupTargetQuery) (*cache.WarmupTargets, error) {
+	s.query = query
+	return s.targets, s.err
+}
+
+// TestWarmupTargetSource tests data-driven targets are merged with static IDs, deduplicated and capped
+func TestWarmupTargetSource(t *testing.T) {
+	localCache := cache.NewLocalCache(100, time.Minute)
+	source := &mockTargetSource{targets: &cache.WarmupTargets{
+		UserIDs: []uint64{1, 5, 6, 7},
+		RoleIDs: []uint64{2, 3},
+	}}
+	warmer := cache.NewPermissionWarmer(&cache.WarmupConfig{
+		SuperAdminUserIDs: []uint64{1},
+		RecentLoginDays:   7,
+		MaxUsers:          3,
+		MaxRoles:          5,
+		Concurrency:       2,
+		RateLimit:         1000,
+		Timeout:           time.Second,
+	}, &mockPermissionLoader{localCache: localCache}).WithTargetSource(source)
+
+	stats, err := warmer.Warm(context.Background())
+	if err != nil {
+		t.Fatalf("Warm failed: %v", err)
+	}
+	if stats.TotalItems != 5 || stats.SuccessCount != 5 {
+		t.Errorf("stats = %+v, want 5/5 success (3 users, 2 roles)", stats)
+	}
+	for _, key := range []string{"user:permissions:1", "user:permissions:5", "user:permissions:6", "role:permissions:2", "role:permissions:3"} {
+		if _, ok := localCache.Get(key); !ok {
+			t.Errorf("%s not warmed", key)
+		}
+	}
+	if _, ok := localCache.Get("user:permissions:7"); ok {
+		t.Error("user 7 warmed over MaxUsers")
+	}
+
+	since := time.Since(source.query.RecentLoginSince)
+	if since < 7*24*time.Hour || since > 7*24*time.Hour+time.Minute {
+		t.Errorf("RecentLoginSince = %v ago, want 7 days", since)
+	}
+	if source.query.MaxUsers != 3 || source.query.MaxRoles != 5 {
+		t.Errorf("query = %+v, want caps passed to source", source.query)
+	}
+
+	// Source error falls back to static IDs
+	source.err = fmt.Errorf("database unavailable")
+	stats, err = warmer.Warm(context.Background())
+	if err != nil {
+		t.Fatalf("Warm with source error failed: %v", err)
+	}
+	if stats.TotalItems != 1 || stats.SuccessCount != 1 || len(stats.ErrorMessages) != 1 {
+		t.Errorf("stats = %+v, want static user only and source error recorded", stats)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/sword-demon/go-react-admin/internal/pkg/auth"
 	"github.com/sword-demon/go-react-admin/internal/pkg/cache"
 	"github.com/sword-demon/go-react-admin/internal/pkg/db"
 	"gopkg.in/yaml.v3"
@@ -17,6 +18,7 @@ type Config struct {
 	Database DatabaseConfig `yaml:"database"`
 	Redis    RedisConfig    `yaml:"redis"`
 	JWT      JWTConfig      `yaml:"jwt"`
+	Cache    CacheConfig    `yaml:"cache"`
 }
 
 // ServerConfig represents server configuration
@@ -45,12 +47,55 @@ type RedisConfig struct {
 	DB           int    `yaml:"db"`
 	PoolSize     int    `yaml:"pool_size"`
 	MinIdleConns int    `yaml:"min_idle_conns"`
+	KeyTracking  bool   `yaml:"key_tracking"` // Track keys per user/role group for O(group size) invalidation
+
+	// Circuit breaker: skip Redis after consecutive failures, probe again after open timeout
+	BreakerThreshold   int `yaml:"breaker_threshold"`    // Consecutive failures before opening
+	BreakerOpenTimeout int `yaml:"breaker_open_timeout"` // In seconds
 }
 
 // JWTConfig represents JWT configuration
 type JWTConfig struct {
-	Secret     string `yaml:"secret"`
-	Expiration int    `yaml:"expiration"` // in hours
+	Secret            string `yaml:"secret"`
+	Issuer            string `yaml:"issuer"`
+	Expiration        int    `yaml:"expiration"`         // Access token lifetime in hours
+	RefreshExpiration int    `yaml:"refresh_expiration"` // Refresh token lifetime in hours
+}
+
+// CacheConfig represents three-tier cache configuration
+type CacheConfig struct {
+	EarlyRefreshWindow int `yaml:"early_refresh_window"` // Early refresh of hot keys before L1 expiration, in seconds (0 = disabled)
+
+	// Local cache (L1) bounds
+	LocalMaxEntries  int  `yaml:"local_max_entries"`   // Maximum number of L1 entries
+	LocalMaxMemoryMB int  `yaml:"local_max_memory_mb"` // Estimated L1 memory bound in MB (0 = entry limit only)
+	LocalShards      int  `yaml:"local_shards"`        // L1 lock shards (0 = auto)
+	LocalAdmission   bool `yaml:"local_admission"`     // TinyLFU admission, keeps hot keys when one-off keys fill L1
+
+	Warmup   WarmupConfig   `yaml:"warmup"`
+	Snapshot SnapshotConfig `yaml:"snapshot"`
+}
+
+// SnapshotConfig represents L1 snapshot configuration
+// L1 is written to a local file on graceful shutdown and restored on startup
+type SnapshotConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Path    string `yaml:"path"`    // Snapshot file
+	MaxAge  int    `yaml:"max_age"` // Older snapshots are discarded, in seconds (0 = no limit)
+}
+
+// WarmupConfig represents permission cache warmup configuration
+// Targets: enabled roles, users holding *:* roles and users who logged in recently
+type WarmupConfig struct {
+	OnStartup       bool     `yaml:"on_startup"`        // Start a warmup job on startup
+	RecentLoginDays int      `yaml:"recent_login_days"` // Warm users who logged in during the last N days (0 = disabled)
+	MaxUsers        int      `yaml:"max_users"`         // Maximum users warmed (0 = no limit)
+	MaxRoles        int      `yaml:"max_roles"`         // Maximum roles warmed (0 = no limit)
+	Concurrency     int      `yaml:"concurrency"`       // Parallel loads
+	RateLimit       int      `yaml:"rate_limit"`        // Maximum loads per second, bounds DB load (0 = no limit)
+	Timeout         int      `yaml:"timeout"`           // In seconds
+	UserIDs         []uint64 `yaml:"user_ids"`          // Users always warmed
+	RoleIDs         []uint64 `yaml:"role_ids"`          // Roles always warmed
 }
 
 // Load loads configuration from YAML file
@@ -101,14 +146,40 @@ func (c *Config) setDefaults() {
 	if c.Redis.MinIdleConns == 0 {
 		c.Redis.MinIdleConns = 5
 	}
+	if c.Redis.BreakerThreshold == 0 {
+		c.Redis.BreakerThreshold = 5
+	}
+	if c.Redis.BreakerOpenTimeout == 0 {
+		c.Redis.BreakerOpenTimeout = 5 // 5 seconds
+	}
+
+	// Cache defaults
+	if c.Cache.LocalMaxEntries == 0 {
+		c.Cache.LocalMaxEntries = 10000
+	}
+	if c.Cache.Warmup.Concurrency == 0 {
+		c.Cache.Warmup.Concurrency = 5
+	}
+	if c.Cache.Warmup.Timeout == 0 {
+		c.Cache.Warmup.Timeout = 30 // 30 seconds
+	}
+	if c.Cache.Snapshot.Path == "" {
+		c.Cache.Snapshot.Path = "data/l1-cache.snapshot"
+	}
 
 	// JWT defaults
 	if c.JWT.Secret == "" {
 		c.JWT.Secret = "go-react-admin-secret-key-change-in-production"
 	}
+	if c.JWT.Issuer == "" {
+		c.JWT.Issuer = "go-react-admin"
+	}
 	if c.JWT.Expiration == 0 {
 		c.JWT.Expiration = 24 // 24 hours
 	}
+	if c.JWT.RefreshExpiration == 0 {
+		c.JWT.RefreshExpiration = 168 // 7 days
+	}
 }
 
 // ToDBConfig converts DatabaseConfig to db.Config
@@ -134,6 +205,59 @@ func (c *RedisConfig) ToRedisConfig() *cache.Config {
 		DB:           c.DB,
 		PoolSize:     c.PoolSize,
 		MinIdleConns: c.MinIdleConns,
+		KeyTracking:  c.KeyTracking,
+
+		BreakerThreshold:   c.BreakerThreshold,
+		BreakerOpenTimeout: time.Duration(c.BreakerOpenTimeout) * time.Second,
+	}
+}
+
+// ToAuthConfig converts JWTConfig to auth.Config
+func (c *JWTConfig) ToAuthConfig() *auth.Config {
+	return &auth.Config{
+		Secret:            c.Secret,
+		Issuer:            c.Issuer,
+		AccessExpiration:  time.Duration(c.Expiration) * time.Hour,
+		RefreshExpiration: time.Duration(c.RefreshExpiration) * time.Hour,
+	}
+}
+
+// EarlyRefreshWindowDuration returns early refresh window as time.Duration
+func (c *CacheConfig) EarlyRefreshWindowDuration() time.Duration {
+	return time.Duration(c.EarlyRefreshWindow) * time.Second
+}
+
+// ToLocalCacheConfig converts CacheConfig to cache.LocalCacheConfig
+func (c *CacheConfig) ToLocalCacheConfig() cache.LocalCacheConfig {
+	return cache.LocalCacheConfig{
+		MaxEntries: c.LocalMaxEntries,
+		MaxBytes:   int64(c.LocalMaxMemoryMB) << 20,
+		DefaultTTL: 5 * time.Minute,
+		Shards:     c.LocalShards,
+		Admission:  c.LocalAdmission,
+	}
+}
+
+// ToWarmupConfig converts WarmupConfig to cache.WarmupConfig
+func (c *WarmupConfig) ToWarmupConfig() *cache.WarmupConfig {
+	return &cache.WarmupConfig{
+		SuperAdminUserIDs: c.UserIDs,
+		CommonRoleIDs:     c.RoleIDs,
+		RecentLoginDays:   c.RecentLoginDays,
+		MaxUsers:          c.MaxUsers,
+		MaxRoles:          c.MaxRoles,
+		Concurrency:       c.Concurrency,
+		RateLimit:         c.RateLimit,
+		Timeout:           time.Duration(c.Timeout) * time.Second,
+		EnableLogging:     true,
+	}
+}
+
+// ToSnapshotConfig converts SnapshotConfig to cache.SnapshotConfig
+func (c *SnapshotConfig) ToSnapshotConfig() cache.SnapshotConfig {
+	return cache.SnapshotConfig{
+		Path:   c.Path,
+		MaxAge: time.Duration(c.MaxAge) * time.Second,
 	}
 }
 
@@ -155,13 +279,34 @@ func Default() *Config {
 			ConnMaxLifetime: 60,
 		},
 		Redis: RedisConfig{
-			Host: "localhost",
-			Port: 6379,
-			DB:   0,
+			Host:               "localhost",
+			Port:               6379,
+			DB:                 0,
+			BreakerThreshold:   5,
+			BreakerOpenTimeout: 5,
 		},
 		JWT: JWTConfig{
-			Secret:     "go-react-admin-secret-key-change-in-production",
-			Expiration: 24,
+			Secret:            "go-react-admin-secret-key-change-in-production",
+			Issuer:            "go-react-admin",
+			Expiration:        24,
+			RefreshExpiration: 168,
+		},
+		Cache: CacheConfig{
+			EarlyRefreshWindow: 30,
+			LocalMaxEntries:    10000,
+			Warmup: WarmupConfig{
+				OnStartup:       true,
+				RecentLoginDays: 7,
+				MaxUsers:        1000,
+				MaxRoles:        200,
+				Concurrency:     5,
+				RateLimit:       200,
+				Timeout:         30,
+			},
+			Snapshot: SnapshotConfig{
+				Path:   "data/l1-cache.snapshot",
+				MaxAge: 600,
+			},
 		},
 	}
 }
@@ -0,0 +1,50 @@
+// Package core provides the unified HTTP response format
+package core
+
+import (
+	stderrors "errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sword-demon/go-react-admin/internal/pkg/errors"
+)
+
+// Response represents the unified JSON response body
+// Code 0 means success, otherwise it is an errors.ErrorCode
+type Response struct {
+	Code int         `json:"code"`
+	Msg  string      `json:"msg"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+// WriteResponse writes data or error to the client
+// Business errors (*errors.Error) are mapped through Error.HTTPStatus(),
+// unknown errors are reported as internal server error without details
+func WriteResponse(c *gin.Context, err error, data interface{}) {
+	if err == nil {
+		c.JSON(http.StatusOK, Response{
+			Code: 0,
+			Msg:  "success",
+			Data: data,
+		})
+		return
+	}
+
+	var e *errors.Error
+	if !stderrors.As(err, &e) {
+		e = errors.Wrap(errors.ErrInternalServer, "internal server error", err)
+	}
+
+	c.JSON(e.HTTPStatus(), Response{
+		Code: int(e.Code),
+		Msg:  e.Message,
+		Data: data,
+	})
+}
+
+// AbortWithError writes error response and stops the handler chain
+// Used by middleware
+func AbortWithError(c *gin.Context, err error) {
+	WriteResponse(c, err, nil)
+	c.Abort()
+}
@@ -0,0 +1,110 @@
+// Package datascope provides row-level data permission filtering (Role.DataScope)
+package datascope
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Scope is the effective data scope of a user, union across enabled roles
+// A row is visible if All is set, its dept is in DeptIDs, or it belongs to (or was created by) UserID
+type Scope struct {
+	All     bool     // Any role with DataScopeAll
+	DeptIDs []uint64 // Departments of DataScopeDeptAndChild and DataScopeDeptOnly roles
+	UserID  uint64   // Own rows (DataScopeSelfOnly), 0 = none
+}
+
+// Columns maps data scope to columns of a table
+// Use qualified names (sys_user.dept_id) when the query joins other tables
+type Columns struct {
+	Dept    string // Department column, e.g. "dept_id" (empty = table has no department)
+	User    string // Owner column, e.g. "id" for sys_user (empty = none)
+	Creator string // Creator column, e.g. "created_by" (model.Auditable, empty = none)
+}
+
+// scopeContextKey is the context.Context key of lazily resolved scope
+type scopeContextKey struct{}
+
+// lazyScope resolves scope on first use, so requests without list queries skip the lookup
+type lazyScope struct {
+	once    sync.Once
+	resolve func() (*Scope, error)
+	scope   *Scope
+	err     error
+}
+
+// WithScope returns a copy of ctx carrying a resolved scope
+func WithScope(ctx context.Context, scope *Scope) context.Context {
+	return withResolver(ctx, func() (*Scope, error) { return scope, nil })
+}
+
+// withResolver returns a copy of ctx carrying a scope resolved on first use
+func withResolver(ctx context.Context, resolve func() (*Scope, error)) context.Context {
+	return context.WithValue(ctx, scopeContextKey{}, &lazyScope{resolve: resolve})
+}
+
+// FromContext returns data scope of the current request
+// Returns (nil, nil) without scope (internal calls: warmup, jobs, scripts)
+func FromContext(ctx context.Context) (*Scope, error) {
+	lazy, ok := ctx.Value(scopeContextKey{}).(*lazyScope)
+	if !ok {
+		return nil, nil
+	}
+	lazy.once.Do(func() {
+		lazy.scope, lazy.err = lazy.resolve()
+	})
+	return lazy.scope, lazy.err
+}
+
+// Filter returns a GORM scope restricting rows to the data scope in ctx
+// Usage:
+//
+//	query.Scopes(datascope.Filter(ctx, datascope.Columns{Dept: "dept_id", Creator: "created_by"}))
+//
+// Queries without scope in ctx are not filtered (see FromContext),
+// a scope matching nothing in columns returns no rows
+func Filter(ctx context.Context, columns Columns) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		scope, err := FromContext(ctx)
+		if err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		if scope == nil || scope.All {
+			return db
+		}
+
+		var (
+			conditions []string
+			args       []interface{}
+		)
+		if columns.Dept != "" && len(scope.DeptIDs) > 0 {
+			conditions = append(conditions, columns.Dept+" IN ?")
+			args = append(args, scope.DeptIDs)
+		}
+		if columns.User != "" && scope.UserID > 0 {
+			conditions = append(conditions, columns.User+" = ?")
+			args = append(args, scope.UserID)
+		}
+		if columns.Creator != "" && scope.UserID > 0 {
+			conditions = append(conditions, columns.Creator+" = ?")
+			args = append(args, scope.UserID)
+		}
+
+		if len(conditions) == 0 {
+			return db.Where("1 = 0")
+		}
+		return db.Where(strings.Join(conditions, " OR "), args...) // GORM parenthesizes OR expressions
+	}
+}
+
+// merge adds dept IDs to scope, keeping them sorted and unique
+func (s *Scope) merge(deptIDs []uint64) {
+	s.DeptIDs = append(s.DeptIDs, deptIDs...)
+	slices.Sort(s.DeptIDs)
+	s.DeptIDs = slices.Compact(s.DeptIDs)
+}
@@ -0,0 +1,188 @@
+package datascope_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sword-demon/go-react-admin/internal/pkg/cache"
+	"github.com/sword-demon/go-react-admin/internal/pkg/datascope"
+	"github.com/sword-demon/go-react-admin/internal/pkg/model"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// mockSources implements UserSource, RoleDeptSource and DeptSource
+type mockSources struct {
+	roles     []*model.Role
+	userDepts map[uint64]uint64
+	roleDepts map[uint64][]*model.RoleDept
+	subtrees  map[uint64][]uint64
+	deptCalls int
+}
+
+func (m *mockSources) GetUserRoles(ctx context.Context, userID uint64) ([]*model.Role, error) {
+	return m.roles, nil
+}
+
+func (m *mockSources) GetDeptID(ctx context.Context, userID uint64) (uint64, error) {
+	deptID, ok := m.userDepts[userID]
+	if !ok {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return deptID, nil
+}
+
+func (m *mockSources) GetRoleDepts(ctx context.Context, roleID uint64) ([]*model.RoleDept, error) {
+	return m.roleDepts[roleID], nil
+}
+
+func (m *mockSources) GetDeptIDs(ctx context.Context, deptID uint64, includeChildren bool) ([]uint64, error) {
+	m.deptCalls++
+	if !includeChildren {
+		return []uint64{deptID}, nil
+	}
+	return m.subtrees[deptID], nil
+}
+
+// role creates an enabled role with data scope
+func role(dataScope uint8) *model.Role {
+	return &model.Role{DataScope: dataScope, Status: model.StatusEnabled}
+}
+
+// TestResolve tests scope is the union of enabled roles, and dept subtrees are cached
+func TestResolve(t *testing.T) {
+	sources := &mockSources{
+		userDepts: map[uint64]uint64{7: 10},
+		subtrees:  map[uint64][]uint64{10: {10, 11, 12}, 20: {20, 21}},
+		roleDepts: map[uint64][]*model.RoleDept{
+			5: {{RoleID: 5, DeptID: 20, IncludeChildren: true}, {RoleID: 5, DeptID: 30}},
+		},
+	}
+	localCache := cache.NewLocalCache(100, time.Minute)
+	resolver := datascope.NewResolver(sources, sources, sources, localCache, nil, nil, cache.NewGenerations(localCache, nil, nil))
+	ctx := context.Background()
+
+	disabledAll := role(model.DataScopeAll)
+	disabledAll.Status = model.StatusDisabled
+	custom := role(model.DataScopeCustom)
+	custom.ID = 5
+
+	tests := []struct {
+		name  string
+		roles []*model.Role
+		want  datascope.Scope
+	}{
+		{"no roles", nil, datascope.Scope{}},
+		{"all wins", []*model.Role{role(model.DataScopeSelfOnly), role(model.DataScopeAll)}, datascope.Scope{All: true}},
+		{"disabled role ignored", []*model.Role{disabledAll, role(model.DataScopeDeptOnly)}, datascope.Scope{DeptIDs: []uint64{10}}},
+		{"dept and child", []*model.Role{role(model.DataScopeDeptAndChild)}, datascope.Scope{DeptIDs: []uint64{10, 11, 12}}},
+		{"union", []*model.Role{role(model.DataScopeDeptOnly), role(model.DataScopeDeptAndChild), role(model.DataScopeSelfOnly)},
+			datascope.Scope{DeptIDs: []uint64{10, 11, 12}, UserID: 7}},
+		{"custom", []*model.Role{custom}, datascope.Scope{DeptIDs: []uint64{20, 21, 30}}},
+		{"custom and dept", []*model.Role{custom, role(model.DataScopeDeptOnly)}, datascope.Scope{DeptIDs: []uint64{10, 20, 21, 30}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sources.roles = tt.roles
+			scope, err := resolver.Resolve(ctx, 7)
+			if err != nil {
+				t.Fatalf("Resolve failed: %v", err)
+			}
+			if scope.All != tt.want.All || scope.UserID != tt.want.UserID || !equalIDs(scope.DeptIDs, tt.want.DeptIDs) {
+				t.Errorf("Resolve = %+v, want %+v", scope, tt.want)
+			}
+		})
+	}
+
+	if sources.deptCalls != 2 {
+		t.Errorf("GetDeptIDs called %d times, want 2 (depts 10 and 20, cached)", sources.deptCalls)
+	}
+}
+
+// TestResolveUserDept tests scope follows the current department of the user, not the token
+func TestResolveUserDept(t *testing.T) {
+	sources := &mockSources{
+		roles:     []*model.Role{role(model.DataScopeDeptOnly)},
+		userDepts: map[uint64]uint64{7: 10},
+	}
+	localCache := cache.NewLocalCache(100, time.Minute)
+	generations := cache.NewGenerations(localCache, nil, nil)
+	resolver := datascope.NewResolver(sources, sources, sources, localCache, nil, nil, generations)
+	ctx := context.Background()
+
+	resolve := func(want []uint64) {
+		t.Helper()
+		scope, err := resolver.Resolve(ctx, 7)
+		if err != nil {
+			t.Fatalf("Resolve failed: %v", err)
+		}
+		if !equalIDs(scope.DeptIDs, want) {
+			t.Errorf("DeptIDs = %v, want %v", scope.DeptIDs, want)
+		}
+	}
+	resolve([]uint64{10})
+
+	// Moved to dept 20: cached until user biz bumps cache.DeptNamespace
+	sources.userDepts[7] = 20
+	resolve([]uint64{10})
+	if _, err := generations.Bump(ctx, cache.DeptNamespace); err != nil {
+		t.Fatalf("Bump failed: %v", err)
+	}
+	resolve([]uint64{20})
+
+	// Deleted user: no department
+	delete(sources.userDepts, 7)
+	if _, err := generations.Bump(ctx, cache.DeptNamespace); err != nil {
+		t.Fatalf("Bump failed: %v", err)
+	}
+	resolve(nil)
+}
+
+// TestFilter tests generated conditions
+func TestFilter(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "user:pass@tcp(localhost:3306)/test", SkipInitializeWithVersion: true}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("failed to open dry run db: %v", err)
+	}
+	columns := datascope.Columns{Dept: "dept_id", User: "id", Creator: "created_by"}
+
+	tests := []struct {
+		name  string
+		scope *datascope.Scope // nil = no scope in context
+		want  string
+	}{
+		{"no scope", nil, "SELECT * FROM `sys_user` WHERE `sys_user`.`deleted_at` IS NULL"},
+		{"all", &datascope.Scope{All: true}, "SELECT * FROM `sys_user` WHERE `sys_user`.`deleted_at` IS NULL"},
+		{"nothing", &datascope.Scope{}, "WHERE 1 = 0"},
+		{"depts", &datascope.Scope{DeptIDs: []uint64{1, 2}}, "WHERE dept_id IN (?,?) AND"},
+		{"self", &datascope.Scope{UserID: 7}, "WHERE (id = ? OR created_by = ?) AND"},
+		{"depts or self", &datascope.Scope{DeptIDs: []uint64{1}, UserID: 7}, "WHERE (dept_id IN (?) OR id = ? OR created_by = ?) AND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.scope != nil {
+				ctx = datascope.WithScope(ctx, tt.scope)
+			}
+			stmt := db.Scopes(datascope.Filter(ctx, columns)).Find(&[]model.User{}).Statement
+			if sql := stmt.SQL.String(); !strings.Contains(sql, tt.want) {
+				t.Errorf("SQL = %s, want to contain %s", sql, tt.want)
+			}
+		})
+	}
+}
+
+// equalIDs compares ID slices
+func equalIDs(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
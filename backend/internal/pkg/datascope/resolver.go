@@ -0,0 +1,201 @@
+// Package datascope provides data scope resolution from user roles
+package datascope
+
+import (
+	"context"
+	stderrors "errors"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sword-demon/go-react-admin/internal/pkg/auth"
+	"github.com/sword-demon/go-react-admin/internal/pkg/cache"
+	"github.com/sword-demon/go-react-admin/internal/pkg/model"
+	"gorm.io/gorm"
+)
+
+// UserSource loads roles and department of a user
+// Implemented by store.IUserStore
+type UserSource interface {
+	GetUserRoles(ctx context.Context, userID uint64) ([]*model.Role, error)
+	GetDeptID(ctx context.Context, userID uint64) (uint64, error)
+}
+
+// RoleDeptSource loads departments of DataScopeCustom roles
+// Implemented by store.IRoleStore
+type RoleDeptSource interface {
+	GetRoleDepts(ctx context.Context, roleID uint64) ([]*model.RoleDept, error)
+}
+
+// DeptSource loads department ID sets
+// Implemented by store.IDeptStore
+type DeptSource interface {
+	GetDeptIDs(ctx context.Context, deptID uint64, includeChildren bool) ([]uint64, error)
+}
+
+// Resolver resolves data scope of users
+// Departments of users and department subtrees (DataScopeDeptAndChild) are cached in three tiers,
+// versioned by cache.DeptNamespace (bumped by dept biz when the tree changes,
+// and by user biz when the department of a user changes)
+type Resolver struct {
+	users       UserSource
+	roleDepts   RoleDeptSource
+	depts       DeptSource
+	generations *cache.Generations
+	userDepts   *cache.Tiered[uint64]
+	deptIDs     *cache.Tiered[[]uint64]
+}
+
+// NewResolver creates a new data scope resolver
+// Share one resolver between requests (created on startup)
+func NewResolver(users UserSource, roleDepts RoleDeptSource, depts DeptSource, localCache *cache.LocalCache, redis *cache.RedisClient, invalidator *cache.Invalidator, generations *cache.Generations) *Resolver {
+	return &Resolver{
+		users:       users,
+		roleDepts:   roleDepts,
+		depts:       depts,
+		generations: generations,
+		userDepts: cache.NewTiered(localCache, redis, cache.TieredConfig[uint64]{
+			Name:        "user_dept",
+			LocalTTL:    5 * time.Minute,
+			RedisTTL:    30 * time.Minute,
+			Invalidator: invalidator,
+		}),
+		deptIDs: cache.NewTiered(localCache, redis, cache.TieredConfig[[]uint64]{
+			Name:        "dept_ids",
+			Clone:       cache.CloneSlice[uint64],
+			LocalTTL:    5 * time.Minute,
+			RedisTTL:    30 * time.Minute,
+			Invalidator: invalidator,
+		}),
+	}
+}
+
+// Resolve returns the union of data scopes of user's enabled roles
+// Department scopes use the current department of the user, not the one in the token
+func (r *Resolver) Resolve(ctx context.Context, userID uint64) (*Scope, error) {
+	roles, err := r.users.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	deptID, err := r.userDept(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := &Scope{}
+	for _, role := range roles {
+		if !role.IsEnabled() {
+			continue
+		}
+		switch role.DataScope {
+		case model.DataScopeAll:
+			return &Scope{All: true}, nil
+		case model.DataScopeDeptAndChild:
+			if deptID > 0 {
+				deptIDs, err := r.subtree(ctx, deptID)
+				if err != nil {
+					return nil, err
+				}
+				scope.merge(deptIDs)
+			}
+		case model.DataScopeDeptOnly:
+			if deptID > 0 {
+				scope.merge([]uint64{deptID})
+			}
+		case model.DataScopeSelfOnly:
+			scope.UserID = userID
+		case model.DataScopeCustom:
+			if err := r.mergeCustom(ctx, scope, role.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return scope, nil
+}
+
+// mergeCustom adds departments assigned to a DataScopeCustom role (with descendants if flagged)
+func (r *Resolver) mergeCustom(ctx context.Context, scope *Scope, roleID uint64) error {
+	roleDepts, err := r.roleDepts.GetRoleDepts(ctx, roleID)
+	if err != nil {
+		return err
+	}
+	for _, roleDept := range roleDepts {
+		if !roleDept.IncludeChildren {
+			scope.merge([]uint64{roleDept.DeptID})
+			continue
+		}
+		deptIDs, err := r.subtree(ctx, roleDept.DeptID)
+		if err != nil {
+			return err
+		}
+		scope.merge(deptIDs)
+	}
+	return nil
+}
+
+// userDept returns department ID of a user (cached, 0 = none or deleted user)
+func (r *Resolver) userDept(ctx context.Context, userID uint64) (uint64, error) {
+	load := func(ctx context.Context) (uint64, error) {
+		deptID, err := r.users.GetDeptID(ctx, userID)
+		if err == gorm.ErrRecordNotFound {
+			return 0, cache.ErrNotFound
+		}
+		return deptID, err
+	}
+
+	cacheKey, err := r.generations.Key(ctx, cache.DeptNamespace, cache.UserDeptCacheKey(userID))
+	var deptID uint64
+	if err != nil {
+		log.Printf("⚠️  User dept cache bypassed (user: %d): %v", userID, err)
+		deptID, err = load(ctx)
+	} else {
+		deptID, err = r.userDepts.Get(ctx, cacheKey, load)
+	}
+	if stderrors.Is(err, cache.ErrNotFound) {
+		return 0, nil
+	}
+	return deptID, err
+}
+
+// subtree returns IDs of a department and its descendants (cached)
+func (r *Resolver) subtree(ctx context.Context, deptID uint64) ([]uint64, error) {
+	load := func(ctx context.Context) ([]uint64, error) {
+		deptIDs, err := r.depts.GetDeptIDs(ctx, deptID, true)
+		if err == gorm.ErrRecordNotFound {
+			return nil, cache.ErrNotFound
+		}
+		return deptIDs, err
+	}
+
+	cacheKey, err := r.generations.Key(ctx, cache.DeptNamespace, cache.DeptIDsCacheKey(deptID))
+	var deptIDs []uint64
+	if err != nil {
+		log.Printf("⚠️  Dept ID cache bypassed (dept: %d): %v", deptID, err)
+		deptIDs, err = load(ctx)
+	} else {
+		deptIDs, err = r.deptIDs.Get(ctx, cacheKey, load)
+	}
+	if stderrors.Is(err, cache.ErrNotFound) {
+		// Deleted department: nothing visible through it
+		return nil, nil
+	}
+	return deptIDs, err
+}
+
+// Middleware puts the data scope of the authenticated user into request context
+// Scope is resolved on first use (datascope.Filter), use after auth.Middleware
+func Middleware(r *Resolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := auth.FromContext(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		c.Request = c.Request.WithContext(withResolver(ctx, func() (*Scope, error) {
+			return r.Resolve(ctx, claims.UserID)
+		}))
+		c.Next()
+	}
+}
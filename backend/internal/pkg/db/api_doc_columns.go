@@ -0,0 +1,45 @@
+package db
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/sword-demon/go-react-admin/internal/pkg/model"
+	"gorm.io/gorm"
+)
+
+// apiDocColumns are sys_api_doc columns added for the route scanner (column → APIDoc field)
+var apiDocColumns = []struct {
+	column, field string
+}{
+	{"api_permission", "Permission"},
+	{"status", "Status"},
+}
+
+// apiDocStatusIndex is the index of sys_api_doc.status in schema.sql
+const apiDocStatusIndex = "idx_status"
+
+// MigrateAPIDocColumns adds the declared permission and status columns to sys_api_doc created before them
+// Existing routes become active (default 1); every step checks the schema first, so it is safe on every startup
+func MigrateAPIDocColumns(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&model.APIDoc{}) {
+		return nil
+	}
+
+	for _, column := range apiDocColumns {
+		if migrator.HasColumn(&model.APIDoc{}, column.column) {
+			continue
+		}
+		if err := migrator.AddColumn(&model.APIDoc{}, column.field); err != nil {
+			return fmt.Errorf("failed to add sys_api_doc.%s: %w", column.column, err)
+		}
+		log.Printf("✅ API doc column added: sys_api_doc.%s", column.column)
+	}
+	if !migrator.HasIndex(&model.APIDoc{}, apiDocStatusIndex) {
+		if err := migrator.CreateIndex(&model.APIDoc{}, apiDocStatusIndex); err != nil {
+			return fmt.Errorf("failed to create sys_api_doc.%s: %w", apiDocStatusIndex, err)
+		}
+	}
+	return nil
+}
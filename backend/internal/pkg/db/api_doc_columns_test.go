@@ -0,0 +1,47 @@
+package db_test
+
+import (
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sword-demon/go-react-admin/internal/pkg/db"
+	"github.com/sword-demon/go-react-admin/internal/pkg/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// TestMigrateAPIDocColumns tests scanner columns are added once, existing routes staying active
+func TestMigrateAPIDocColumns(t *testing.T) {
+	database, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	// sys_api_doc of schema.sql before the route scanner
+	if err := database.Exec("CREATE TABLE `sys_api_doc` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, `api_path` VARCHAR(200) NOT NULL, `api_method` VARCHAR(10) NOT NULL," +
+		"`api_module` VARCHAR(50) NOT NULL, `api_group` VARCHAR(50), `description` VARCHAR(200)," +
+		"`create_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP, `update_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP)").Error; err != nil {
+		t.Fatalf("failed to create legacy table: %v", err)
+	}
+	if err := database.Exec("INSERT INTO `sys_api_doc` (`api_path`, `api_method`, `api_module`) VALUES ('/api/v1/users', 'GET', 'user')").Error; err != nil {
+		t.Fatalf("failed to insert legacy rows: %v", err)
+	}
+
+	// Twice: the second run finds nothing to do
+	for run := 1; run <= 2; run++ {
+		if err := db.MigrateAPIDocColumns(database); err != nil {
+			t.Fatalf("MigrateAPIDocColumns (run %d) failed: %v", run, err)
+		}
+	}
+
+	if !database.Migrator().HasIndex(&model.APIDoc{}, "idx_status") {
+		t.Error("idx_status should be created")
+	}
+	var docs []*model.APIDoc
+	if err := database.Find(&docs).Error; err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if len(docs) != 1 || docs[0].IsStale() || docs[0].Permission != "" || docs[0].CreatedAt.IsZero() {
+		t.Errorf("docs = %+v, want one active route with create_time", docs)
+	}
+}
@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// CurrentUserFunc returns ID of the user performing a request
+// e.g., auth.UserIDFromContext
+type CurrentUserFunc func(ctx context.Context) (uint64, bool)
+
+// Audit columns (model.Auditable)
+const (
+	createdByColumn = "created_by"
+	updatedByColumn = "updated_by"
+)
+
+// RegisterAudit fills created_by/updated_by of models embedding model.Auditable
+// from the statement context (db.WithContext(ctx)), using currentUser
+// Statements without a current user (jobs, migrations) keep the given values
+func RegisterAudit(db *gorm.DB, currentUser CurrentUserFunc) error {
+	if err := db.Use(auditPlugin{currentUser: currentUser}); err != nil {
+		return fmt.Errorf("failed to register GORM audit callbacks: %w", err)
+	}
+	return nil
+}
+
+// auditPlugin registers audit callbacks
+type auditPlugin struct {
+	currentUser CurrentUserFunc
+}
+
+// Name returns plugin name
+func (auditPlugin) Name() string {
+	return "audit"
+}
+
+// Initialize registers callbacks before GORM create/update processors
+func (p auditPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("audit:before_create", p.beforeCreate),
+		cb.Update().Before("gorm:update").Register("audit:before_update", p.beforeUpdate),
+	)
+}
+
+// beforeCreate sets created_by (unless set explicitly) and updated_by of new rows
+func (p auditPlugin) beforeCreate(db *gorm.DB) {
+	userID, ok := p.userID(db)
+	if !ok {
+		return
+	}
+	createdBy := db.Statement.Schema.LookUpField(createdByColumn)
+	updatedBy := db.Statement.Schema.LookUpField(updatedByColumn)
+
+	setRow := func(row reflect.Value) {
+		ctx := db.Statement.Context
+		for _, field := range []*schema.Field{createdBy, updatedBy} {
+			if field == nil {
+				continue
+			}
+			if _, zero := field.ValueOf(ctx, row); zero {
+				_ = db.AddError(field.Set(ctx, row, userID))
+			}
+		}
+	}
+
+	switch rv := db.Statement.ReflectValue; rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			setRow(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		setRow(rv)
+	}
+}
+
+// beforeUpdate sets updated_by of updated rows (struct and map updates)
+func (p auditPlugin) beforeUpdate(db *gorm.DB) {
+	userID, ok := p.userID(db)
+	if !ok || db.Statement.Schema.LookUpField(updatedByColumn) == nil {
+		return
+	}
+	db.Statement.SetColumn(updatedByColumn, userID, true)
+}
+
+// userID returns current user of auditable statements
+func (p auditPlugin) userID(db *gorm.DB) (uint64, bool) {
+	if db.Error != nil || db.Statement.Schema == nil || db.Statement.Context == nil {
+		return 0, false
+	}
+	if db.Statement.Schema.LookUpField(createdByColumn) == nil && db.Statement.Schema.LookUpField(updatedByColumn) == nil {
+		return 0, false
+	}
+	userID, ok := p.currentUser(db.Statement.Context)
+	return userID, ok && userID > 0
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/sword-demon/go-react-admin/internal/pkg/model"
+	"gorm.io/gorm"
+)
+
+// auditColumnRenames maps legacy audit columns (schema.sql before model.Auditable) to current ones
+var auditColumnRenames = []struct {
+	old, new, field string
+}{
+	{"create_by", "created_by", "CreatedBy"},
+	{"update_by", "updated_by", "UpdatedBy"},
+}
+
+// createdByIndex is the index of created_by in schema.sql (data scope creator filter)
+// Same name as in model.Auditable, so AutoMigrate does not add a duplicate
+const createdByIndex = "idx_created_by"
+
+// MigrateAuditColumns renames legacy create_by/update_by columns to created_by/updated_by
+// of tables embedding model.Auditable, adding missing columns and the created_by index
+// Every step checks the schema first, so it is safe on every startup
+func MigrateAuditColumns(db *gorm.DB) error {
+	for _, value := range []interface{}{&model.User{}, &model.Role{}, &model.Dept{}, &model.Menu{}} {
+		if err := migrateAuditColumns(db, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateAuditColumns migrates audit columns of one table
+func migrateAuditColumns(db *gorm.DB, value interface{}) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(value) {
+		return nil
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(value); err != nil {
+		return err
+	}
+	table := stmt.Schema.Table
+
+	for _, column := range auditColumnRenames {
+		if migrator.HasColumn(value, column.new) {
+			continue
+		}
+		if !migrator.HasColumn(value, column.old) {
+			if err := migrator.AddColumn(value, column.field); err != nil {
+				return fmt.Errorf("failed to add %s.%s: %w", table, column.new, err)
+			}
+			log.Printf("✅ Audit column added: %s.%s", table, column.new)
+			continue
+		}
+
+		// Legacy columns are nullable, new ones are NOT NULL DEFAULT 0 (0 = system)
+		if err := migrator.RenameColumn(value, column.old, column.new); err != nil {
+			return fmt.Errorf("failed to rename %s.%s: %w", table, column.old, err)
+		}
+		if err := db.Table(table).Where(column.new+" IS NULL").Update(column.new, 0).Error; err != nil {
+			return fmt.Errorf("failed to backfill %s.%s: %w", table, column.new, err)
+		}
+		if err := migrator.AlterColumn(value, column.field); err != nil {
+			return fmt.Errorf("failed to alter %s.%s: %w", table, column.new, err)
+		}
+		log.Printf("✅ Audit column renamed: %s.%s -> %s", table, column.old, column.new)
+	}
+
+	if !migrator.HasIndex(value, createdByIndex) {
+		if err := migrator.CreateIndex(value, createdByIndex); err != nil {
+			return fmt.Errorf("failed to create %s.%s: %w", table, createdByIndex, err)
+		}
+	}
+	return nil
+}
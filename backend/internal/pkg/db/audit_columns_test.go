@@ -0,0 +1,89 @@
+package db_test
+
+import (
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sword-demon/go-react-admin/internal/pkg/db"
+	"github.com/sword-demon/go-react-admin/internal/pkg/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// TestMigrateAuditColumns tests legacy create_by/update_by columns are renamed once, keeping data
+func TestMigrateAuditColumns(t *testing.T) {
+	database, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	// sys_role of schema.sql before model.Auditable
+	if err := database.Exec("CREATE TABLE `sys_role` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, `role_name` VARCHAR(50) NOT NULL, `role_key` VARCHAR(100) NOT NULL," +
+		"`role_sort` INTEGER NOT NULL DEFAULT 0, `data_scope` INTEGER NOT NULL DEFAULT 1, `status` INTEGER NOT NULL DEFAULT 1," +
+		"`remark` VARCHAR(500), `create_by` BIGINT DEFAULT NULL, `created_at` DATETIME, `update_by` BIGINT DEFAULT NULL," +
+		"`updated_at` DATETIME, `deleted_at` DATETIME)").Error; err != nil {
+		t.Fatalf("failed to create legacy table: %v", err)
+	}
+	if err := database.Exec("INSERT INTO `sys_role` (`role_name`, `role_key`, `create_by`, `update_by`) VALUES ('admin', 'admin', NULL, 5), ('editor', 'editor', 3, NULL)").Error; err != nil {
+		t.Fatalf("failed to insert legacy rows: %v", err)
+	}
+
+	// Twice: the second run finds nothing to do
+	for run := 1; run <= 2; run++ {
+		if err := db.MigrateAuditColumns(database); err != nil {
+			t.Fatalf("MigrateAuditColumns (run %d) failed: %v", run, err)
+		}
+	}
+
+	migrator := database.Migrator()
+	for _, column := range []string{"create_by", "update_by"} {
+		if migrator.HasColumn(&model.Role{}, column) {
+			t.Errorf("legacy column %s should be renamed", column)
+		}
+	}
+	if !migrator.HasIndex(&model.Role{}, "idx_created_by") {
+		t.Error("idx_created_by should be created")
+	}
+
+	var roles []*model.Role
+	if err := database.Order("id").Find(&roles).Error; err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if len(roles) != 2 {
+		t.Fatalf("roles = %d, want 2", len(roles))
+	}
+	if got := roles[0].Auditable; got.CreatedBy != 0 || got.UpdatedBy != 5 {
+		t.Errorf("admin audit = %+v, want created_by=0 (was NULL) updated_by=5", got)
+	}
+	if got := roles[1].Auditable; got.CreatedBy != 3 || got.UpdatedBy != 0 {
+		t.Errorf("editor audit = %+v, want created_by=3 updated_by=0 (was NULL)", got)
+	}
+}
+
+// TestMigrateAuditColumnsIndex tests model.Auditable and the migration agree on the created_by index name
+func TestMigrateAuditColumnsIndex(t *testing.T) {
+	database, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard, DisableForeignKeyConstraintWhenMigrating: true})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	if err := database.AutoMigrate(&model.Dept{}); err != nil {
+		t.Fatalf("AutoMigrate failed: %v", err)
+	}
+	if err := db.MigrateAuditColumns(database); err != nil {
+		t.Fatalf("MigrateAuditColumns failed: %v", err)
+	}
+
+	indexes, err := database.Migrator().GetIndexes(&model.Dept{})
+	if err != nil {
+		t.Fatalf("GetIndexes failed: %v", err)
+	}
+	var names []string
+	for _, index := range indexes {
+		if columns := index.Columns(); len(columns) == 1 && columns[0] == "created_by" {
+			names = append(names, index.Name())
+		}
+	}
+	if len(names) != 1 || names[0] != "idx_created_by" {
+		t.Errorf("created_by indexes = %v, want only idx_created_by", names)
+	}
+}
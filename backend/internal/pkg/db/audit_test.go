@@ -0,0 +1,53 @@
+package db_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sword-demon/go-react-admin/internal/pkg/auth"
+	"github.com/sword-demon/go-react-admin/internal/pkg/db"
+	"github.com/sword-demon/go-react-admin/internal/pkg/model"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// TestRegisterAudit tests created_by/updated_by are filled from request context
+func TestRegisterAudit(t *testing.T) {
+	database, err := gorm.Open(mysql.New(mysql.Config{DSN: "user:pass@tcp(localhost:3306)/test", SkipInitializeWithVersion: true}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open dry run db: %v", err)
+	}
+	if err := db.RegisterAudit(database, auth.UserIDFromContext); err != nil {
+		t.Fatalf("RegisterAudit failed: %v", err)
+	}
+	ctx := auth.WithClaims(context.Background(), &auth.Claims{UserID: 7})
+
+	// Create: both columns set, explicit created_by kept
+	users := []*model.User{{Username: "a"}, {Username: "b", Auditable: model.Auditable{CreatedBy: 3}}}
+	if err := database.WithContext(ctx).Create(users).Error; err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if users[0].CreatedBy != 7 || users[0].UpdatedBy != 7 {
+		t.Errorf("users[0] audit = %+v, want created_by=7 updated_by=7", users[0].Auditable)
+	}
+	if users[1].CreatedBy != 3 || users[1].UpdatedBy != 7 {
+		t.Errorf("users[1] audit = %+v, want created_by=3 updated_by=7", users[1].Auditable)
+	}
+
+	// Create without user in context: untouched
+	dept := &model.Dept{DeptName: "d"}
+	if err := database.WithContext(context.Background()).Create(dept).Error; err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if dept.CreatedBy != 0 || dept.UpdatedBy != 0 {
+		t.Errorf("dept audit = %+v, want zero without user", dept.Auditable)
+	}
+
+	// Update with map: updated_by added
+	stmt := database.WithContext(ctx).Model(&model.Role{BaseModel: model.BaseModel{ID: 1}}).
+		Updates(map[string]interface{}{"role_name": "r"}).Statement
+	if sql := stmt.SQL.String(); !strings.Contains(sql, "`updated_by`=?") {
+		t.Errorf("SQL = %s, want to set updated_by", sql)
+	}
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/sword-demon/go-react-admin/internal/pkg/model"
+	"gorm.io/gorm"
+)
+
+// MigrateDeptClosure creates sys_dept_closure and backfills it from sys_dept.ancestors
+// Runs only while the closure table is empty, so it is safe on every startup
+func MigrateDeptClosure(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&model.DeptClosure{}) {
+		if err := db.AutoMigrate(&model.DeptClosure{}); err != nil {
+			return fmt.Errorf("failed to create dept closure table: %w", err)
+		}
+	}
+
+	var count int64
+	if err := db.Model(&model.DeptClosure{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	var depts []*model.Dept
+	if err := db.Select("id", "ancestors").Find(&depts).Error; err != nil {
+		return err
+	}
+	rows := DeptClosureRows(depts)
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(rows, 500).Error
+	}); err != nil {
+		return fmt.Errorf("failed to backfill dept closure: %w", err)
+	}
+	log.Printf("✅ Dept closure backfilled: %d departments, %d rows", len(depts), len(rows))
+	return nil
+}
+
+// DeptClosureRows builds closure rows of departments from their ancestors chains
+// Ancestors missing from depts (deleted) are skipped, depth counts from the department upwards
+func DeptClosureRows(depts []*model.Dept) []*model.DeptClosure {
+	exists := make(map[uint64]bool, len(depts))
+	for _, dept := range depts {
+		exists[dept.ID] = true
+	}
+
+	var rows []*model.DeptClosure
+	for _, dept := range depts {
+		rows = append(rows, &model.DeptClosure{Ancestor: dept.ID, Descendant: dept.ID})
+		ancestors := model.ParseAncestors(dept.Ancestors)
+		for i, ancestorID := range ancestors {
+			if !exists[ancestorID] || ancestorID == dept.ID {
+				continue
+			}
+			rows = append(rows, &model.DeptClosure{
+				Ancestor:   ancestorID,
+				Descendant: dept.ID,
+				Depth:      uint32(len(ancestors) - i),
+			})
+		}
+	}
+	return rows
+}
@@ -0,0 +1,57 @@
+package db_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/sword-demon/go-react-admin/internal/pkg/db"
+	"github.com/sword-demon/go-react-admin/internal/pkg/model"
+)
+
+// TestDeptClosureRows tests backfill rows, IDs sharing a prefix (5, 51, 500) stay apart
+func TestDeptClosureRows(t *testing.T) {
+	depts := []*model.Dept{
+		{BaseModel: model.BaseModel{ID: 1}, Ancestors: "0"},
+		{BaseModel: model.BaseModel{ID: 5}, Ancestors: "0,1"},
+		{BaseModel: model.BaseModel{ID: 6}, Ancestors: "0,1,5"},
+		{BaseModel: model.BaseModel{ID: 51}, Ancestors: "0,1"},
+		{BaseModel: model.BaseModel{ID: 500}, Ancestors: "0,1,51"},
+		{BaseModel: model.BaseModel{ID: 7}, Ancestors: "0,1,99"}, // Ancestor 99 deleted
+	}
+	rows := db.DeptClosureRows(depts)
+
+	descendants := func(ancestor uint64) []uint64 {
+		var ids []uint64
+		for _, row := range rows {
+			if row.Ancestor == ancestor {
+				ids = append(ids, row.Descendant)
+			}
+		}
+		slices.Sort(ids)
+		return ids
+	}
+	tests := []struct {
+		ancestor uint64
+		want     []uint64
+	}{
+		{1, []uint64{1, 5, 6, 7, 51, 500}},
+		{5, []uint64{5, 6}},
+		{51, []uint64{51, 500}},
+		{500, []uint64{500}},
+		{99, nil},
+	}
+	for _, tt := range tests {
+		if got := descendants(tt.ancestor); !slices.Equal(got, tt.want) {
+			t.Errorf("descendants(%d) = %v, want %v", tt.ancestor, got, tt.want)
+		}
+	}
+
+	for _, row := range rows {
+		if row.Ancestor == 1 && row.Descendant == 500 && row.Depth != 2 {
+			t.Errorf("depth(1, 500) = %d, want 2", row.Depth)
+		}
+		if row.Ancestor == 1 && row.Descendant == 7 && row.Depth != 2 {
+			t.Errorf("depth(1, 7) = %d, want 2 (counted through deleted 99)", row.Depth)
+		}
+	}
+}
@@ -23,11 +23,14 @@ func AutoMigrate(db *gorm.DB) error {
 	// 	&model.User{},
 	// 	&model.Role{},
 	// 	&model.Dept{},
+	// 	&model.DeptClosure{},
 	// 	&model.Menu{},
 	// 	&model.RolePermission{},
 	// 	&model.UserRole{},
 	// 	&model.RoleMenu{},
+	// 	&model.RoleDept{},
 	// 	&model.LoginLog{},
+	// 	&model.APIDoc{},
 	// )
 	// if err != nil {
 	// 	return err
@@ -48,12 +51,27 @@ func AutoMigrate(db *gorm.DB) error {
 
 	log.Println("✅ Database auto-migration skipped (using schema.sql)")
 
+	// Data migrations (idempotent, run on every startup)
+	if err := MigrateAuditColumns(db); err != nil {
+		return err
+	}
+	if err := MigratePermissionEffect(db); err != nil {
+		return err
+	}
+	if err := MigrateAPIDocColumns(db); err != nil {
+		return err
+	}
+	if err := MigrateDeptClosure(db); err != nil {
+		return err
+	}
+
 	// Verify models are defined (no actual migration)
 	_ = model.User{}
 	_ = model.Role{}
 	_ = model.Dept{}
 	_ = model.Menu{}
 	_ = model.RolePermission{}
+	_ = model.APIDoc{}
 
 	return nil
 }
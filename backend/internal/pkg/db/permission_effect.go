@@ -0,0 +1,34 @@
+package db
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/sword-demon/go-react-admin/internal/pkg/model"
+	"gorm.io/gorm"
+)
+
+// permissionEffectIndex is the index of sys_role_permission.effect in schema.sql
+const permissionEffectIndex = "idx_effect"
+
+// MigratePermissionEffect adds sys_role_permission.effect (deny rules) to databases created before it
+// Existing rules become grants (default allow); every step checks the schema first, so it is safe on every startup
+func MigratePermissionEffect(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&model.RolePermission{}) {
+		return nil
+	}
+
+	if !migrator.HasColumn(&model.RolePermission{}, "effect") {
+		if err := migrator.AddColumn(&model.RolePermission{}, "Effect"); err != nil {
+			return fmt.Errorf("failed to add sys_role_permission.effect: %w", err)
+		}
+		log.Println("✅ Permission effect column added: sys_role_permission.effect")
+	}
+	if !migrator.HasIndex(&model.RolePermission{}, permissionEffectIndex) {
+		if err := migrator.CreateIndex(&model.RolePermission{}, permissionEffectIndex); err != nil {
+			return fmt.Errorf("failed to create sys_role_permission.%s: %w", permissionEffectIndex, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package db_test
+
+import (
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sword-demon/go-react-admin/internal/pkg/db"
+	"github.com/sword-demon/go-react-admin/internal/pkg/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// TestMigratePermissionEffect tests effect is added once, existing rules becoming grants
+func TestMigratePermissionEffect(t *testing.T) {
+	database, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	// sys_role_permission of schema.sql before deny rules
+	if err := database.Exec("CREATE TABLE `sys_role_permission` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, `role_id` BIGINT NOT NULL, `permission_pattern` VARCHAR(100) NOT NULL," +
+		"`permission_type` VARCHAR(20) NOT NULL, `description` VARCHAR(200), `status` INTEGER NOT NULL DEFAULT 1)").Error; err != nil {
+		t.Fatalf("failed to create legacy table: %v", err)
+	}
+	if err := database.Exec("INSERT INTO `sys_role_permission` (`role_id`, `permission_pattern`, `permission_type`) VALUES (2, 'user:*', 'module')").Error; err != nil {
+		t.Fatalf("failed to insert legacy rows: %v", err)
+	}
+
+	// Twice: the second run finds nothing to do
+	for run := 1; run <= 2; run++ {
+		if err := db.MigratePermissionEffect(database); err != nil {
+			t.Fatalf("MigratePermissionEffect (run %d) failed: %v", run, err)
+		}
+	}
+
+	if !database.Migrator().HasIndex(&model.RolePermission{}, "idx_effect") {
+		t.Error("idx_effect should be created")
+	}
+	var effects []string
+	if err := database.Table("sys_role_permission").Pluck("effect", &effects).Error; err != nil {
+		t.Fatalf("Pluck failed: %v", err)
+	}
+	if len(effects) != 1 || effects[0] != model.PermissionEffectAllow {
+		t.Errorf("effects = %v, want [%s]", effects, model.PermissionEffectAllow)
+	}
+
+	// Missing table: nothing to migrate
+	empty, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	if err := db.MigratePermissionEffect(empty); err != nil {
+		t.Errorf("MigratePermissionEffect without table failed: %v", err)
+	}
+}
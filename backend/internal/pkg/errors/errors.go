@@ -46,6 +46,7 @@ const (
 	ErrDeptAlreadyExists
 	ErrDeptHasChildren
 	ErrDeptHasUsers
+	ErrDeptInvalidParent
 )
 
 const (
@@ -62,6 +63,13 @@ const (
 	ErrPermissionInvalidPattern
 )
 
+const (
+	// Auth errors (7000-7999)
+	ErrTokenInvalid ErrorCode = 7000 + iota
+	ErrTokenExpired
+	ErrTokenRevoked
+)
+
 // Error represents a business error with code and message
 type Error struct {
 	Code    ErrorCode `json:"code"`
@@ -87,13 +95,13 @@ func (e *Error) HTTPStatus() int {
 	switch e.Code {
 	case ErrNotFound, ErrUserNotFound, ErrRoleNotFound, ErrDeptNotFound, ErrMenuNotFound, ErrPermissionNotFound:
 		return http.StatusNotFound
-	case ErrUnauthorized, ErrUserInvalidCredentials:
+	case ErrUnauthorized, ErrUserInvalidCredentials, ErrTokenInvalid, ErrTokenExpired, ErrTokenRevoked:
 		return http.StatusUnauthorized
-	case ErrForbidden, ErrPermissionDenied:
+	case ErrForbidden, ErrPermissionDenied, ErrUserDisabled:
 		return http.StatusForbidden
 	case ErrConflict, ErrUserAlreadyExists, ErrRoleAlreadyExists, ErrDeptAlreadyExists, ErrMenuAlreadyExists:
 		return http.StatusConflict
-	case ErrBadRequest, ErrInvalidParams:
+	case ErrBadRequest, ErrInvalidParams, ErrPermissionInvalidPattern, ErrDeptInvalidParent:
 		return http.StatusBadRequest
 	case ErrTooManyRequests:
 		return http.StatusTooManyRequests
@@ -145,26 +153,34 @@ var (
 	ErrInvalidInput        = New(ErrInvalidParams, "invalid input parameters")
 
 	// User
-	ErrUsernameExists       = New(ErrUserAlreadyExists, "username already exists")
-	ErrInvalidCredentials   = New(ErrUserInvalidCredentials, "invalid username or password")
-	ErrInvalidOldPassword   = New(ErrUserInvalidPassword, "old password is incorrect")
-	ErrUserNotFoundError    = New(ErrUserNotFound, "user not found")
-	ErrUserDisabledError    = New(ErrUserDisabled, "user is disabled")
+	ErrUsernameExists     = New(ErrUserAlreadyExists, "username already exists")
+	ErrInvalidCredentials = New(ErrUserInvalidCredentials, "invalid username or password")
+	ErrInvalidOldPassword = New(ErrUserInvalidPassword, "old password is incorrect")
+	ErrUserNotFoundError  = New(ErrUserNotFound, "user not found")
+	ErrUserDisabledError  = New(ErrUserDisabled, "user is disabled")
 
 	// Role
-	ErrRoleKeyExists        = New(ErrRoleAlreadyExists, "role key already exists")
-	ErrRoleNotFoundError    = New(ErrRoleNotFound, "role not found")
-	ErrRoleInUseError       = New(ErrRoleInUse, "role is in use by users")
+	ErrRoleKeyExists     = New(ErrRoleAlreadyExists, "role key already exists")
+	ErrRoleNotFoundError = New(ErrRoleNotFound, "role not found")
+	ErrRoleInUseError    = New(ErrRoleInUse, "role is in use by users")
 
 	// Dept
-	ErrDeptNotFoundError    = New(ErrDeptNotFound, "department not found")
-	ErrDeptHasChildrenError = New(ErrDeptHasChildren, "department has children")
-	ErrDeptHasUsersError    = New(ErrDeptHasUsers, "department has users")
+	ErrDeptNotFoundError      = New(ErrDeptNotFound, "department not found")
+	ErrDeptHasChildrenError   = New(ErrDeptHasChildren, "department has children")
+	ErrDeptHasUsersError      = New(ErrDeptHasUsers, "department has users")
+	ErrDeptInvalidParentError = New(ErrDeptInvalidParent, "department cannot be moved under itself or its descendants")
 
 	// Menu
 	ErrMenuNotFoundError    = New(ErrMenuNotFound, "menu not found")
 	ErrMenuHasChildrenError = New(ErrMenuHasChildren, "menu has children")
 
 	// Permission
-	ErrPermissionDeniedError = New(ErrPermissionDenied, "permission denied")
-)
\ No newline at end of file
+	ErrPermissionDeniedError         = New(ErrPermissionDenied, "permission denied")
+	ErrPermissionInvalidPatternError = New(ErrPermissionInvalidPattern, "invalid permission pattern")
+
+	// Auth
+	ErrTokenMissingError = New(ErrUnauthorized, "missing authorization token")
+	ErrTokenInvalidError = New(ErrTokenInvalid, "invalid token")
+	ErrTokenExpiredError = New(ErrTokenExpired, "token has expired")
+	ErrTokenRevokedError = New(ErrTokenRevoked, "token has been revoked")
+)
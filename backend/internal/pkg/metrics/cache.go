@@ -0,0 +1,148 @@
+// Package metrics provides three-tier cache metrics
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sword-demon/go-react-admin/internal/pkg/cache"
+)
+
+// Cache metric descriptors
+var (
+	cacheRequestsDesc = prometheus.NewDesc("cache_requests_total",
+		"Cache lookups by cache name, tier (l1, l2, l3) and result (hit, miss, error).",
+		[]string{"cache", "tier", "result"}, nil)
+	cacheLoadDurationDesc = prometheus.NewDesc("cache_load_duration_seconds",
+		"Latency of L3 (database) loads by cache name.",
+		[]string{"cache"}, nil)
+
+	localEntriesDesc     = prometheus.NewDesc("cache_local_entries", "Entries in local cache (L1).", nil, nil)
+	localMaxEntriesDesc  = prometheus.NewDesc("cache_local_max_entries", "Entry limit of local cache.", nil, nil)
+	localBytesDesc       = prometheus.NewDesc("cache_local_bytes", "Estimated memory of local cache entries.", nil, nil)
+	localMaxBytesDesc    = prometheus.NewDesc("cache_local_max_bytes", "Memory limit of local cache (0 = unlimited).", nil, nil)
+	localRequestsDesc    = prometheus.NewDesc("cache_local_requests_total", "All local cache lookups by result.", []string{"result"}, nil)
+	localEvictionsDesc   = prometheus.NewDesc("cache_local_evictions_total", "Entries evicted from local cache to fit its limits.", nil, nil)
+	localExpirationsDesc = prometheus.NewDesc("cache_local_expirations_total", "Expired entries removed from local cache.", nil, nil)
+	localRejectedDesc    = prometheus.NewDesc("cache_local_rejected_total", "Writes rejected by admission policy or size limit.", nil, nil)
+
+	loadsDesc          = prometheus.NewDesc("cache_loads_total", "L3 loads executed by the load group.", nil, nil)
+	loadsCoalescedDesc = prometheus.NewDesc("cache_loads_coalesced_total", "Callers that shared an in-flight load.", nil, nil)
+	refreshesDesc      = prometheus.NewDesc("cache_early_refreshes_total", "Background reloads of hot keys before expiration.", nil, nil)
+	refreshErrorsDesc  = prometheus.NewDesc("cache_refresh_errors_total", "Failed background reloads.", nil, nil)
+
+	redisPoolHitsDesc     = prometheus.NewDesc("redis_pool_hits_total", "Free connection found in Redis pool.", nil, nil)
+	redisPoolMissesDesc   = prometheus.NewDesc("redis_pool_misses_total", "Free connection not found in Redis pool.", nil, nil)
+	redisPoolTimeoutsDesc = prometheus.NewDesc("redis_pool_timeouts_total", "Redis pool wait timeouts.", nil, nil)
+	redisPoolWaitsDesc    = prometheus.NewDesc("redis_pool_waits_total", "Times a Redis connection was waited for.", nil, nil)
+	redisPoolWaitDesc     = prometheus.NewDesc("redis_pool_wait_seconds_total", "Total time spent waiting for Redis connections.", nil, nil)
+	redisPoolConnsDesc    = prometheus.NewDesc("redis_pool_conns", "Redis pool connections by state (total, idle).", []string{"state"}, nil)
+	redisPoolStaleDesc    = prometheus.NewDesc("redis_pool_stale_conns_total", "Stale connections removed from Redis pool.", nil, nil)
+
+	breakerStateDesc    = prometheus.NewDesc("redis_breaker_state", "Redis circuit breaker state (1 for current state).", []string{"state"}, nil)
+	breakerOpensDesc    = prometheus.NewDesc("redis_breaker_opens_total", "Times Redis circuit breaker opened.", nil, nil)
+	breakerRejectedDesc = prometheus.NewDesc("redis_breaker_rejected_total", "Redis commands skipped while breaker was open.", nil, nil)
+)
+
+// breakerStates are all circuit breaker states exported by redis_breaker_state
+var breakerStates = []string{cache.BreakerClosed, cache.BreakerOpen, cache.BreakerHalfOpen, cache.BreakerDisabled}
+
+// RegisterCache exposes three-tier cache statistics
+// Statistics are read on each scrape, redis and loads may be nil
+func RegisterCache(local *cache.LocalCache, redis *cache.RedisClient, loads *cache.LoadGroup) error {
+	return Registry.Register(&cacheCollector{local: local, redis: redis, loads: loads})
+}
+
+// cacheCollector converts cache statistics to Prometheus metrics
+type cacheCollector struct {
+	local *cache.LocalCache
+	redis *cache.RedisClient
+	loads *cache.LoadGroup
+}
+
+// Describe sends all metric descriptors
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		cacheRequestsDesc, cacheLoadDurationDesc,
+		localEntriesDesc, localMaxEntriesDesc, localBytesDesc, localMaxBytesDesc, localRequestsDesc,
+		localEvictionsDesc, localExpirationsDesc, localRejectedDesc,
+		loadsDesc, loadsCoalescedDesc, refreshesDesc, refreshErrorsDesc,
+		redisPoolHitsDesc, redisPoolMissesDesc, redisPoolTimeoutsDesc, redisPoolWaitsDesc,
+		redisPoolWaitDesc, redisPoolConnsDesc, redisPoolStaleDesc,
+		breakerStateDesc, breakerOpensDesc, breakerRejectedDesc,
+	} {
+		ch <- desc
+	}
+}
+
+// Collect reads current statistics
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	counter := func(desc *prometheus.Desc, value float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value, labels...)
+	}
+	gauge := func(desc *prometheus.Desc, value float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, labels...)
+	}
+
+	// Per-tier statistics of named caches
+	for name, stats := range cache.AllTierStats() {
+		for tier, counters := range map[string]cache.TierCounters{
+			cache.TierLocal:    stats.L1,
+			cache.TierRedis:    stats.L2,
+			cache.TierDatabase: stats.L3,
+		} {
+			counter(cacheRequestsDesc, float64(counters.Hits), name, tier, "hit")
+			counter(cacheRequestsDesc, float64(counters.Misses), name, tier, "miss")
+			counter(cacheRequestsDesc, float64(counters.Errors), name, tier, "error")
+		}
+
+		latency := stats.LoadLatency
+		buckets := make(map[float64]uint64, len(latency.Buckets))
+		for i, le := range latency.Buckets {
+			buckets[le] = latency.Counts[i]
+		}
+		ch <- prometheus.MustNewConstHistogram(cacheLoadDurationDesc, latency.Count, latency.Sum, buckets, name)
+	}
+
+	// Local cache (L1)
+	local := c.local.Stats()
+	gauge(localEntriesDesc, float64(local.Size))
+	gauge(localMaxEntriesDesc, float64(local.MaxSize))
+	gauge(localBytesDesc, float64(local.Bytes))
+	gauge(localMaxBytesDesc, float64(local.MaxBytes))
+	counter(localRequestsDesc, float64(local.Hits), "hit")
+	counter(localRequestsDesc, float64(local.Misses), "miss")
+	counter(localEvictionsDesc, float64(local.Evictions))
+	counter(localExpirationsDesc, float64(local.Expirations))
+	counter(localRejectedDesc, float64(local.Rejected))
+
+	// Load coalescing
+	loads := c.loads.Stats()
+	counter(loadsDesc, float64(loads.Loads))
+	counter(loadsCoalescedDesc, float64(loads.Coalesced))
+	counter(refreshesDesc, float64(loads.EarlyRefreshes))
+	counter(refreshErrorsDesc, float64(loads.RefreshErrors))
+
+	// Redis (L2)
+	breaker := c.redis.BreakerStats()
+	for _, state := range breakerStates {
+		value := 0.0
+		if state == breaker.State {
+			value = 1
+		}
+		gauge(breakerStateDesc, value, state)
+	}
+	counter(breakerOpensDesc, float64(breaker.Opens))
+	counter(breakerRejectedDesc, float64(breaker.Rejected))
+
+	if c.redis == nil {
+		return
+	}
+	pool := c.redis.PoolStats()
+	counter(redisPoolHitsDesc, float64(pool.Hits))
+	counter(redisPoolMissesDesc, float64(pool.Misses))
+	counter(redisPoolTimeoutsDesc, float64(pool.Timeouts))
+	counter(redisPoolWaitsDesc, float64(pool.WaitCount))
+	counter(redisPoolWaitDesc, pool.WaitDuration)
+	gauge(redisPoolConnsDesc, float64(pool.TotalConns), "total")
+	gauge(redisPoolConnsDesc, float64(pool.IdleConns), "idle")
+	counter(redisPoolStaleDesc, float64(pool.StaleConns))
+}
@@ -0,0 +1,94 @@
+// Package metrics provides GORM query metrics
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+// GORM metrics
+var (
+	dbDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gorm_query_duration_seconds",
+		Help:    "GORM query latency by operation and table.",
+		Buckets: []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	dbErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gorm_query_errors_total",
+		Help: "Failed GORM queries by operation and table (record not found is not an error).",
+	}, []string{"operation", "table"})
+)
+
+// startTimeKey is the statement instance key of query start time
+const startTimeKey = "metrics:start_time"
+
+// RegisterDB instruments GORM queries and exposes connection pool statistics
+// name labels pool metrics (go_sql_*{db_name="..."})
+func RegisterDB(db *gorm.DB, name string) error {
+	if err := db.Use(gormPlugin{}); err != nil {
+		return fmt.Errorf("failed to register GORM metrics: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database instance: %w", err)
+	}
+	return Registry.Register(collectors.NewDBStatsCollector(sqlDB, name))
+}
+
+// gormPlugin records latency and errors of every GORM operation
+type gormPlugin struct{}
+
+// Name returns plugin name
+func (gormPlugin) Name() string {
+	return "metrics"
+}
+
+// Initialize registers callbacks around GORM processors
+func (gormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("metrics:before_create", startTimer),
+		cb.Create().After("gorm:create").Register("metrics:after_create", observe("create")),
+		cb.Query().Before("gorm:query").Register("metrics:before_query", startTimer),
+		cb.Query().After("gorm:query").Register("metrics:after_query", observe("query")),
+		cb.Update().Before("gorm:update").Register("metrics:before_update", startTimer),
+		cb.Update().After("gorm:update").Register("metrics:after_update", observe("update")),
+		cb.Delete().Before("gorm:delete").Register("metrics:before_delete", startTimer),
+		cb.Delete().After("gorm:delete").Register("metrics:after_delete", observe("delete")),
+		cb.Row().Before("gorm:row").Register("metrics:before_row", startTimer),
+		cb.Row().After("gorm:row").Register("metrics:after_row", observe("row")),
+		cb.Raw().Before("gorm:raw").Register("metrics:before_raw", startTimer),
+		cb.Raw().After("gorm:raw").Register("metrics:after_raw", observe("raw")),
+	)
+}
+
+// startTimer stores query start time in statement instance
+func startTimer(db *gorm.DB) {
+	db.InstanceSet(startTimeKey, time.Now())
+}
+
+// observe returns callback recording latency and errors of operation
+func observe(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		start, ok := db.InstanceGet(startTimeKey)
+		if !ok {
+			return
+		}
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+
+		dbDuration.WithLabelValues(operation, table).Observe(time.Since(start.(time.Time)).Seconds())
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			dbErrors.WithLabelValues(operation, table).Inc()
+		}
+	}
+}
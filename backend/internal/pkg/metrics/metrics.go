@@ -0,0 +1,63 @@
+// Package metrics provides Prometheus metrics (HTTP, GORM, three-tier cache)
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds all application metrics, exposed by Handler
+var Registry = prometheus.NewRegistry()
+
+// HTTP metrics
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by method, route and status code.",
+	}, []string{"method", "path", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by method and route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "path"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		dbDuration,
+		dbErrors,
+	)
+}
+
+// Handler returns /metrics handler (Prometheus text format)
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+}
+
+// Middleware records HTTP request count and latency
+// Requests are labeled by route template (/api/v1/users/:id), not raw path,
+// so label cardinality stays bounded; unknown routes are labeled "unmatched"
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		path := c.FullPath()
+		if path == "" {
+			path = "unmatched"
+		}
+		method := c.Request.Method
+		httpRequests.WithLabelValues(method, path, strconv.Itoa(c.Writer.Status())).Inc()
+		httpDuration.WithLabelValues(method, path).Observe(time.Since(start).Seconds())
+	}
+}
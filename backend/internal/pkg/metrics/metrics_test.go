@@ -0,0 +1,58 @@
+package metrics_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sword-demon/go-react-admin/internal/pkg/cache"
+	"github.com/sword-demon/go-react-admin/internal/pkg/metrics"
+)
+
+// TestMetricsEndpoint tests /metrics exposes HTTP, cache tier and Redis pool metrics
+func TestMetricsEndpoint(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := &cache.RedisClient{Client: redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+	t.Cleanup(func() { _ = redisClient.Client.Close() })
+
+	local := cache.NewLocalCache(100, time.Minute)
+	loads := cache.NewLoadGroup(0)
+	if err := metrics.RegisterCache(local, redisClient, loads); err != nil {
+		t.Fatalf("RegisterCache failed: %v", err)
+	}
+
+	tiered := cache.NewTiered(local, redisClient, cache.TieredConfig[string]{Name: "metrics_test", Loads: loads})
+	_, _ = tiered.Get(context.Background(), "k", func(ctx context.Context) (string, error) { return "v", nil })
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(metrics.Middleware())
+	r.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/metrics", metrics.Handler())
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		`http_requests_total{method="GET",path="/users/:id",status="200"} 1`,
+		`cache_requests_total{cache="metrics_test",result="hit",tier="l3"} 1`,
+		`cache_requests_total{cache="metrics_test",result="miss",tier="l2"} 1`,
+		`cache_load_duration_seconds_count{cache="metrics_test"} 1`,
+		`cache_local_entries 1`,
+		`cache_loads_total 1`,
+		`redis_breaker_state{state="closed"} 1`,
+		`redis_pool_conns{state="total"}`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("/metrics missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// APIDoc represents sys_api_doc table (auto-synced by API scanner, for display only)
+// NOTE: Not used for permission validation, see RolePermission
+// The table has create_time/update_time and no soft delete (stale routes keep Status), so no BaseModel
+type APIDoc struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	APIPath     string    `gorm:"column:api_path;type:varchar(200);not null;uniqueIndex:idx_path_method" json:"api_path"` // e.g., /api/v1/users/:id
+	APIMethod   string    `gorm:"column:api_method;type:varchar(10);not null;uniqueIndex:idx_path_method" json:"api_method"`
+	APIModule   string    `gorm:"column:api_module;type:varchar(50);not null;index:idx_module" json:"api_module"` // e.g., user
+	APIGroup    string    `gorm:"column:api_group;type:varchar(50)" json:"api_group"`                             // e.g., users
+	Permission  string    `gorm:"column:api_permission;type:varchar(100)" json:"permission"`                      // e.g., user:create
+	Description string    `gorm:"column:description;type:varchar(200)" json:"description"`
+	Status      uint8     `gorm:"column:status;type:tinyint;not null;default:1;index:idx_status;comment:'1=Active,0=Stale'" json:"status"`
+	CreatedAt   time.Time `gorm:"column:create_time;autoCreateTime;not null" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:update_time;autoUpdateTime;not null" json:"updated_at"`
+}
+
+// TableName returns the table name
+func (APIDoc) TableName() string {
+	return "sys_api_doc"
+}
+
+// IsStale checks if the route no longer exists
+func (a *APIDoc) IsStale() bool {
+	return a.Status == APIDocStatusStale
+}
+
+// RouteKey returns the unique key of the route (METHOD path)
+func (a *APIDoc) RouteKey() string {
+	return a.APIMethod + " " + a.APIPath
+}
@@ -15,6 +15,13 @@ type BaseModel struct {
 	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;index" json:"-"` // Soft delete
 }
 
+// Auditable records who created and last updated a row
+// Filled from request context by GORM callbacks (db.RegisterAudit), 0 = system
+type Auditable struct {
+	CreatedBy uint64 `gorm:"column:created_by;not null;default:0;index:idx_created_by" json:"created_by"`
+	UpdatedBy uint64 `gorm:"column:updated_by;not null;default:0" json:"updated_by"`
+}
+
 // Status constants
 const (
 	StatusEnabled  uint8 = 1 // Active/Enabled
@@ -34,6 +41,7 @@ const (
 	DataScopeDeptAndChild uint8 = 2 // Current dept + child depts
 	DataScopeDeptOnly     uint8 = 3 // Current dept only
 	DataScopeSelfOnly     uint8 = 4 // Only own data
+	DataScopeCustom       uint8 = 5 // Chosen depts (sys_role_dept)
 )
 
 // MenuType constants
@@ -51,6 +59,21 @@ const (
 	PermissionTypePath   string = "path"   // /api/users/*
 )
 
+// PermissionEffect constants (grant or deny)
+const (
+	PermissionEffectAllow string = "allow" // Grants matching requests
+	PermissionEffectDeny  string = "deny"  // Denies matching requests (overrides any matching allow)
+)
+
+// DenyRulePrefix marks a deny rule in permission rule lists (e.g., !user:delete)
+const DenyRulePrefix = "!"
+
+// APIDoc status constants
+const (
+	APIDocStatusActive uint8 = 1 // Route exists in current route table
+	APIDocStatusStale  uint8 = 0 // Route disappeared from route table
+)
+
 // TableName returns the table name for a model (helper function)
 func TableName(name string) string {
 	return "sys_" + name
@@ -1,8 +1,14 @@
 package model
 
+import (
+	"strconv"
+	"strings"
+)
+
 // Dept represents sys_dept table (tree structure)
 type Dept struct {
 	BaseModel
+	Auditable
 	ParentID  uint64 `gorm:"column:parent_id;not null;default:0;index:idx_parent_id" json:"parent_id"`
 	Ancestors string `gorm:"column:ancestors;type:varchar(500);default:'';index:idx_ancestors" json:"ancestors"` // e.g., "0,1,2"
 	DeptName  string `gorm:"column:dept_name;type:varchar(64);not null" json:"dept_name"`
@@ -37,3 +43,32 @@ func (d *Dept) IsRoot() bool {
 func (d *Dept) HasChildren() bool {
 	return len(d.Children) > 0
 }
+
+// DeptClosure represents sys_dept_closure table (closure of the department tree)
+// One row per (ancestor, descendant) pair, including (id, id, 0) for every department
+type DeptClosure struct {
+	Ancestor   uint64 `gorm:"column:ancestor;primaryKey;autoIncrement:false" json:"ancestor"`
+	Descendant uint64 `gorm:"column:descendant;primaryKey;autoIncrement:false;index:idx_descendant" json:"descendant"`
+	Depth      uint32 `gorm:"column:depth;not null;default:0" json:"depth"` // 0 = self, 1 = child, ...
+}
+
+// TableName returns the table name
+func (DeptClosure) TableName() string {
+	return "sys_dept_closure"
+}
+
+// ParseAncestors parses ancestors string ("0,1,2") to department IDs, root marker 0 excluded
+func ParseAncestors(ancestors string) []uint64 {
+	if ancestors == "" || ancestors == "0" {
+		return []uint64{}
+	}
+
+	parts := strings.Split(ancestors, ",")
+	ids := make([]uint64, 0, len(parts))
+	for _, part := range parts {
+		if id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64); err == nil && id > 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
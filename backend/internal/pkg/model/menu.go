@@ -3,6 +3,7 @@ package model
 // Menu represents sys_menu table (tree structure)
 type Menu struct {
 	BaseModel
+	Auditable
 	ParentID  uint64 `gorm:"column:parent_id;not null;default:0;index:idx_parent_id" json:"parent_id"`
 	MenuName  string `gorm:"column:menu_name;type:varchar(64);not null" json:"menu_name"`
 	MenuType  uint8  `gorm:"column:menu_type;type:tinyint;not null;default:2;comment:'1=Directory,2=Menu,3=Button'" json:"menu_type"`
@@ -1,11 +1,14 @@
 package model
 
+import "strings"
+
 // RolePermission represents sys_role_permission table (permission pattern matching)
 type RolePermission struct {
 	BaseModel
 	RoleID            uint64 `gorm:"column:role_id;not null;index:idx_role_id" json:"role_id"`
 	PermissionPattern string `gorm:"column:permission_pattern;type:varchar(100);not null" json:"permission_pattern"` // e.g., user:*, /api/users/*
 	PermissionType    string `gorm:"column:permission_type;type:varchar(20);not null;index:idx_type" json:"permission_type"`
+	Effect            string `gorm:"column:effect;type:varchar(10);not null;default:allow;index:idx_effect" json:"effect"` // allow or deny
 	Description       string `gorm:"column:description;type:varchar(200)" json:"description"`
 	Status            uint8  `gorm:"column:status;type:tinyint;not null;default:1;comment:'1=Active,0=Disabled'" json:"status"`
 
@@ -23,6 +26,25 @@ func (p *RolePermission) IsActive() bool {
 	return p.Status == StatusEnabled
 }
 
+// IsDeny checks if permission is a deny rule (effect column or ! prefix)
+func (p *RolePermission) IsDeny() bool {
+	return p.Effect == PermissionEffectDeny || strings.HasPrefix(p.PermissionPattern, DenyRulePrefix)
+}
+
+// Rule returns the pattern used for matching, deny rules are prefixed with "!"
+func (p *RolePermission) Rule() string {
+	return PermissionRule(p.PermissionPattern, p.Effect)
+}
+
+// PermissionRule builds a permission rule from pattern and effect
+// e.g., ("user:delete", "deny") → "!user:delete"
+func PermissionRule(pattern, effect string) string {
+	if effect == PermissionEffectDeny && !strings.HasPrefix(pattern, DenyRulePrefix) {
+		return DenyRulePrefix + pattern
+	}
+	return pattern
+}
+
 // IsGlobal checks if permission is global (*:*)
 func (p *RolePermission) IsGlobal() bool {
 	return p.PermissionType == PermissionTypeGlobal
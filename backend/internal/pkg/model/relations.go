@@ -29,3 +29,19 @@ type RoleMenu struct {
 func (RoleMenu) TableName() string {
 	return "sys_role_menu"
 }
+
+// RoleDept represents sys_role_dept table (role-dept: departments of DataScopeCustom roles)
+type RoleDept struct {
+	RoleID          uint64 `gorm:"column:role_id;primaryKey;index:idx_role_id" json:"role_id"`
+	DeptID          uint64 `gorm:"column:dept_id;primaryKey;index:idx_dept_id" json:"dept_id"`
+	IncludeChildren bool   `gorm:"column:include_children;not null;default:false" json:"include_children"` // Also descendants of dept
+
+	// Relations
+	Role *Role `gorm:"foreignKey:RoleID" json:"role,omitempty"`
+	Dept *Dept `gorm:"foreignKey:DeptID" json:"dept,omitempty"`
+}
+
+// TableName returns the table name
+func (RoleDept) TableName() string {
+	return "sys_role_dept"
+}
@@ -3,16 +3,18 @@ package model
 // Role represents sys_role table
 type Role struct {
 	BaseModel
+	Auditable
 	RoleName  string `gorm:"column:role_name;type:varchar(64);not null" json:"role_name"`
 	RoleKey   string `gorm:"column:role_key;type:varchar(64);not null;uniqueIndex:idx_role_key" json:"role_key"`
 	RoleSort  int    `gorm:"column:role_sort;not null;default:0" json:"role_sort"`
-	DataScope uint8  `gorm:"column:data_scope;type:tinyint;not null;default:4;comment:'1=All,2=DeptAndChild,3=DeptOnly,4=SelfOnly'" json:"data_scope"`
+	DataScope uint8  `gorm:"column:data_scope;type:tinyint;not null;default:4;comment:'1=All,2=DeptAndChild,3=DeptOnly,4=SelfOnly,5=Custom'" json:"data_scope"`
 	Status    uint8  `gorm:"column:status;type:tinyint;not null;default:1;comment:'1=Enabled,0=Disabled'" json:"status"`
 	Remark    string `gorm:"column:remark;type:varchar(500)" json:"remark"`
 
 	// Relations
 	Users       []*User           `gorm:"many2many:sys_user_role;" json:"users,omitempty"`
 	Menus       []*Menu           `gorm:"many2many:sys_role_menu;" json:"menus,omitempty"`
+	Depts       []*RoleDept       `gorm:"foreignKey:RoleID" json:"depts,omitempty"` // DataScopeCustom
 	Permissions []*RolePermission `gorm:"foreignKey:RoleID" json:"permissions,omitempty"`
 }
 
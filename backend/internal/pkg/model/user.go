@@ -3,6 +3,7 @@ package model
 // User represents sys_user table
 type User struct {
 	BaseModel
+	Auditable
 	Username string `gorm:"column:username;type:varchar(64);not null;uniqueIndex:idx_username" json:"username"`
 	Password string `gorm:"column:password;type:varchar(128);not null" json:"-"` // bcrypt hash
 	NickName string `gorm:"column:nick_name;type:varchar(64)" json:"nick_name"`
@@ -25,15 +25,16 @@ CREATE TABLE `sys_user` (
   `avatar` VARCHAR(255) DEFAULT NULL COMMENT 'Avatar URL',
   `status` TINYINT NOT NULL DEFAULT 1 COMMENT 'Status (1=Active, 0=Disabled)',
   `remark` VARCHAR(500) DEFAULT NULL COMMENT 'Remark',
-  `create_by` BIGINT UNSIGNED DEFAULT NULL COMMENT 'Created by user ID',
+  `created_by` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Created by user ID (0=system)',
   `create_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
-  `update_by` BIGINT UNSIGNED DEFAULT NULL COMMENT 'Updated by user ID',
+  `updated_by` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Updated by user ID (0=system)',
   `update_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
   `deleted_at` DATETIME DEFAULT NULL COMMENT 'Soft delete timestamp',
   PRIMARY KEY (`id`),
   UNIQUE KEY `idx_username` (`username`),
   KEY `idx_dept_id` (`dept_id`),
   KEY `idx_status` (`status`),
+  KEY `idx_created_by` (`created_by`),
   KEY `idx_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='User table';
 
@@ -50,18 +51,18 @@ CREATE TABLE `sys_role` (
   `role_name` VARCHAR(50) NOT NULL COMMENT 'Role name',
   `role_key` VARCHAR(50) NOT NULL COMMENT 'Role key (e.g., admin, manager)',
   `data_scope` VARCHAR(20) NOT NULL DEFAULT 'ALL' COMMENT 'Data permission scope (ALL/DEPT_AND_CHILD/DEPT_ONLY/SELF_ONLY/CUSTOM)',
-  `dept_ids` VARCHAR(500) DEFAULT NULL COMMENT 'Custom department IDs (comma-separated, for CUSTOM scope)',
   `status` TINYINT NOT NULL DEFAULT 1 COMMENT 'Status (1=Active, 0=Disabled)',
   `sort` INT NOT NULL DEFAULT 0 COMMENT 'Display order',
   `remark` VARCHAR(500) DEFAULT NULL COMMENT 'Remark',
-  `create_by` BIGINT UNSIGNED DEFAULT NULL COMMENT 'Created by user ID',
+  `created_by` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Created by user ID (0=system)',
   `create_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
-  `update_by` BIGINT UNSIGNED DEFAULT NULL COMMENT 'Updated by user ID',
+  `updated_by` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Updated by user ID (0=system)',
   `update_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
   `deleted_at` DATETIME DEFAULT NULL COMMENT 'Soft delete timestamp',
   PRIMARY KEY (`id`),
   UNIQUE KEY `idx_role_key` (`role_key`),
   KEY `idx_status` (`status`),
+  KEY `idx_created_by` (`created_by`),
   KEY `idx_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Role table';
 
@@ -85,15 +86,16 @@ CREATE TABLE `sys_dept` (
   `phone` VARCHAR(20) DEFAULT NULL COMMENT 'Contact phone',
   `email` VARCHAR(100) DEFAULT NULL COMMENT 'Contact email',
   `status` TINYINT NOT NULL DEFAULT 1 COMMENT 'Status (1=Active, 0=Disabled)',
-  `create_by` BIGINT UNSIGNED DEFAULT NULL COMMENT 'Created by user ID',
+  `created_by` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Created by user ID (0=system)',
   `create_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
-  `update_by` BIGINT UNSIGNED DEFAULT NULL COMMENT 'Updated by user ID',
+  `updated_by` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Updated by user ID (0=system)',
   `update_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
   `deleted_at` DATETIME DEFAULT NULL COMMENT 'Soft delete timestamp',
   PRIMARY KEY (`id`),
   KEY `idx_parent_id` (`parent_id`),
   KEY `idx_ancestors` (`ancestors`),
   KEY `idx_status` (`status`),
+  KEY `idx_created_by` (`created_by`),
   KEY `idx_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Department table (tree structure)';
 
@@ -103,6 +105,25 @@ INSERT INTO `sys_dept` (`id`, `parent_id`, `ancestors`, `dept_name`, `sort`, `le
 (2, 1, '0,1', 'Technology Department', 1, NULL, 1),
 (3, 1, '0,1', 'Marketing Department', 2, NULL, 1);
 
+-- =====================================================
+-- 3.1 Department Closure Table (sys_dept_closure)
+-- One row per (ancestor, descendant) pair, including (id, id, 0)
+-- Used for subtree queries instead of LIKE on ancestors
+-- =====================================================
+DROP TABLE IF EXISTS `sys_dept_closure`;
+CREATE TABLE `sys_dept_closure` (
+  `ancestor` BIGINT UNSIGNED NOT NULL COMMENT 'Ancestor department ID',
+  `descendant` BIGINT UNSIGNED NOT NULL COMMENT 'Descendant department ID',
+  `depth` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Distance (0=self, 1=child)',
+  PRIMARY KEY (`ancestor`, `descendant`),
+  KEY `idx_descendant` (`descendant`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Department closure table (tree hierarchy)';
+
+-- Initialize closure of departments above
+INSERT INTO `sys_dept_closure` (`ancestor`, `descendant`, `depth`) VALUES
+(1, 1, 0), (2, 2, 0), (3, 3, 0),
+(1, 2, 1), (1, 3, 1);
+
 -- =====================================================
 -- 4. Menu Table (sys_menu)
 -- =====================================================
@@ -121,15 +142,16 @@ CREATE TABLE `sys_menu` (
   `status` TINYINT NOT NULL DEFAULT 1 COMMENT 'Status (1=Active, 0=Disabled)',
   `is_cache` TINYINT NOT NULL DEFAULT 0 COMMENT 'Is cached (1=Yes, 0=No)',
   `is_frame` TINYINT NOT NULL DEFAULT 0 COMMENT 'Is external link (1=Yes, 0=No)',
-  `create_by` BIGINT UNSIGNED DEFAULT NULL COMMENT 'Created by user ID',
+  `created_by` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Created by user ID (0=system)',
   `create_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
-  `update_by` BIGINT UNSIGNED DEFAULT NULL COMMENT 'Updated by user ID',
+  `updated_by` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Updated by user ID (0=system)',
   `update_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
   `deleted_at` DATETIME DEFAULT NULL COMMENT 'Soft delete timestamp',
   PRIMARY KEY (`id`),
   KEY `idx_parent_id` (`parent_id`),
   KEY `idx_menu_type` (`menu_type`),
   KEY `idx_status` (`status`),
+  KEY `idx_created_by` (`created_by`),
   KEY `idx_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Menu table (tree structure)';
 
@@ -172,6 +194,7 @@ CREATE TABLE `sys_role_permission` (
   `role_id` BIGINT UNSIGNED NOT NULL COMMENT 'Role ID',
   `permission_pattern` VARCHAR(100) NOT NULL COMMENT 'Permission pattern (e.g., user:*, /api/users/*, user:read)',
   `permission_type` VARCHAR(20) NOT NULL COMMENT 'Pattern type (global/module/action/path)',
+  `effect` VARCHAR(10) NOT NULL DEFAULT 'allow' COMMENT 'Effect (allow/deny), deny overrides allow',
   `description` VARCHAR(200) DEFAULT NULL COMMENT 'Description',
   `status` TINYINT NOT NULL DEFAULT 1 COMMENT 'Status (1=Active, 0=Disabled)',
   `create_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
@@ -179,6 +202,7 @@ CREATE TABLE `sys_role_permission` (
   PRIMARY KEY (`id`),
   KEY `idx_role_id` (`role_id`),
   KEY `idx_pattern_type` (`permission_type`),
+  KEY `idx_effect` (`effect`),
   KEY `idx_status` (`status`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Role permission pattern table (Core optimization for API permissions)';
 
@@ -205,12 +229,15 @@ CREATE TABLE `sys_api_doc` (
   `api_method` VARCHAR(10) NOT NULL COMMENT 'HTTP method (GET/POST/PUT/DELETE)',
   `api_module` VARCHAR(50) NOT NULL COMMENT 'Module name (user/product/order)',
   `api_group` VARCHAR(50) DEFAULT NULL COMMENT 'API group',
+  `api_permission` VARCHAR(100) DEFAULT NULL COMMENT 'Declared permission (e.g., user:create)',
   `description` VARCHAR(200) DEFAULT NULL COMMENT 'API description',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT 'Status (1=Active, 0=Stale: route no longer registered)',
   `create_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time (auto-synced by route scanner)',
   `update_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
   PRIMARY KEY (`id`),
   UNIQUE KEY `idx_path_method` (`api_path`, `api_method`),
-  KEY `idx_module` (`api_module`)
+  KEY `idx_module` (`api_module`),
+  KEY `idx_status` (`status`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='API documentation table (for display only)';
 
 -- =====================================================
@@ -246,6 +273,20 @@ CREATE TABLE `sys_role_menu` (
 INSERT INTO `sys_role_menu` (`role_id`, `menu_id`)
 SELECT 1, id FROM sys_menu WHERE deleted_at IS NULL;
 
+-- =====================================================
+-- 8.1 Role-Dept Mapping Table (sys_role_dept)
+-- Departments visible to CUSTOM data scope roles
+-- =====================================================
+DROP TABLE IF EXISTS `sys_role_dept`;
+CREATE TABLE `sys_role_dept` (
+  `role_id` BIGINT UNSIGNED NOT NULL COMMENT 'Role ID',
+  `dept_id` BIGINT UNSIGNED NOT NULL COMMENT 'Department ID',
+  `include_children` TINYINT(1) NOT NULL DEFAULT 0 COMMENT 'Also grant descendant departments (1=Yes, 0=No)',
+  PRIMARY KEY (`role_id`, `dept_id`),
+  KEY `idx_role_id` (`role_id`),
+  KEY `idx_dept_id` (`dept_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Role-Dept mapping table (CUSTOM data scope)';
+
 -- =====================================================
 -- 9. Login Log Table (sys_login_log) - Phase 1 MVP
 -- =====================================================